@@ -1,8 +1,12 @@
 package app
 
 import (
+	"context"
 	"log"
+	"time"
 
+	"github.com/EmptyInsid/db_gui/internal/report"
+	"github.com/EmptyInsid/db_gui/internal/schedule"
 	"github.com/EmptyInsid/db_gui/internal/utils"
 )
 
@@ -23,8 +27,57 @@ func Run() error {
 		return err
 	}
 
+	// очистка корзины от устаревших записей, только если задан срок хранения
+	if config.TrashDays > 0 {
+		purged, err := db.PurgeExpiredTrash(context.Background(), config.TrashDays)
+		if err != nil {
+			log.Printf("Error while purge trash: %v", err)
+		} else {
+			log.Printf("Purged %d records from trash", purged)
+		}
+	}
+
+	setReportFonts(config)
+
+	// расписания отчётов проверяются, пока открыто приложение
+	if config.ScheduleMinutes > 0 {
+		schedule.Start(context.Background(), db, time.Duration(config.ScheduleMinutes)*time.Minute)
+	}
+
 	utils.StartApp(db)
 
 	return nil
 
 }
+
+// RunSchedules однократно строит отчёты наступивших расписаний без запуска GUI
+func RunSchedules() error {
+	config, err := utils.LoadConfig("../config/config.ini")
+	if err != nil {
+		log.Printf("Error connection with bd: %v", err)
+		return err
+	}
+
+	db, err := utils.LoadDb(config)
+	if err != nil {
+		log.Printf("Error connection with bd: %v", err)
+		return err
+	}
+	defer db.CloseDB()
+
+	setReportFonts(config)
+
+	done, err := schedule.RunDue(context.Background(), db, time.Now())
+	if err != nil {
+		log.Printf("Error while run report schedules: %v", err)
+		return err
+	}
+	log.Printf("Saved %d scheduled reports", done)
+	return nil
+}
+
+// шрифты PDF из настроек, без них - встроенные
+func setReportFonts(config *utils.Config) {
+	report.FontPath = config.PDFFont
+	report.FontBoldPath = config.PDFFontBold
+}
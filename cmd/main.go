@@ -2,11 +2,20 @@ package main
 
 import (
 	"log"
+	"os"
 
 	"github.com/EmptyInsid/db_gui/app"
 )
 
 func main() {
+	// run-schedules - построить отчёты по расписаниям и выйти, например из cron
+	if len(os.Args) > 1 && os.Args[1] == "run-schedules" {
+		if err := app.RunSchedules(); err != nil {
+			log.Fatalf("Error while run schedules: %v\n", err)
+		}
+		return
+	}
+
 	if err := app.Run(); err != nil {
 		log.Fatalf("Error while run app: %v\n", err)
 	}
@@ -0,0 +1,13 @@
+package auth
+
+import "errors"
+
+var (
+	ErrIncorrectPassword = errors.New("Incorrect password")
+	ErrAccountLocked     = errors.New("Account is temporarily locked")
+	ErrTooManyAttempts   = errors.New("Too many login attempts, try again later")
+
+	ErrSecondFactorRequired = errors.New("Second factor required")
+	ErrIncorrectCode        = errors.New("Incorrect two-factor code")
+	ErrInvalidTOTPSecret    = errors.New("Invalid TOTP secret")
+)
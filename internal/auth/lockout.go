@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/EmptyInsid/db_gui/internal/database"
+	"github.com/EmptyInsid/db_gui/internal/models"
+)
+
+const (
+	// число неудачных попыток, после которого учётная запись блокируется
+	MaxFailedAttempts = 5
+	// длительность временной блокировки
+	LockoutDuration = 15 * time.Minute
+	// задержка после первой неудачной попытки, дальше удваивается
+	backoffBase = time.Second
+)
+
+// текущее время, подменяется в тестах
+var now = time.Now
+
+// задержка перед следующей попыткой после failed неудачных попыток подряд
+func backoffDelay(failed int) time.Duration {
+	if failed <= 0 {
+		return 0
+	}
+	delay := backoffBase
+	for i := 1; i < failed; i++ {
+		delay *= 2
+		if delay >= LockoutDuration {
+			return LockoutDuration
+		}
+	}
+	return delay
+}
+
+// число неудачных попыток с учётом истёкшей блокировки
+func activeFailures(attempts models.LoginAttempts, t time.Time) int {
+	if attempts.LockedUntil != nil && !t.Before(*attempts.LockedUntil) {
+		return 0
+	}
+	return attempts.FailedCount
+}
+
+// проверить, может ли пользователь сейчас попытаться войти
+func checkLockout(attempts models.LoginAttempts, t time.Time) error {
+	if attempts.LockedUntil != nil && t.Before(*attempts.LockedUntil) {
+		return ErrAccountLocked
+	}
+	failed := activeFailures(attempts, t)
+	if failed > 0 && attempts.LastFailedAt != nil && t.Before(attempts.LastFailedAt.Add(backoffDelay(failed))) {
+		return ErrTooManyAttempts
+	}
+	return nil
+}
+
+// учесть неудачную попытку входа и при необходимости заблокировать учётную запись
+func registerFailure(db database.Service, ctx context.Context, username string, t time.Time, reason string) {
+	failed, err := db.RecordLoginFailure(ctx, username, t, MaxFailedAttempts, t.Add(LockoutDuration))
+	if err != nil {
+		log.Printf("Error while record login failure: %v\n", err)
+	}
+	if failed >= MaxFailedAttempts {
+		reason = "locked: " + reason
+	}
+	if err := db.AddLoginRecord(ctx, username, false, reason); err != nil {
+		log.Printf("Error while add login record: %v\n", err)
+	}
+}
+
+// Разблокировать учётную запись пользователя (для администратора)
+func UnlockUser(db database.Service, ctx context.Context, username string) error {
+	if err := db.ResetLoginAttempts(ctx, username); err != nil {
+		log.Printf("Error while unlock user: %v\n", err)
+		return err
+	}
+	if err := db.AddUnlockRecord(ctx, username, "unlocked by admin"); err != nil {
+		log.Printf("Error while add unlock record: %v\n", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/EmptyInsid/db_gui/internal/database"
+	"github.com/EmptyInsid/db_gui/internal/models"
+)
+
+// сервис, считающий неудачные попытки в памяти
+type failureService struct {
+	database.Service
+	failed  int
+	reasons []string
+}
+
+func (s *failureService) RecordLoginFailure(ctx context.Context, username string, failedAt time.Time, maxFailed int, lockedUntil time.Time) (int, error) {
+	s.failed++
+	return s.failed, nil
+}
+
+func (s *failureService) AddLoginRecord(ctx context.Context, username string, success bool, reason string) error {
+	s.reasons = append(s.reasons, reason)
+	return nil
+}
+
+func TestBackoffDelay(t *testing.T) {
+	tests := []struct {
+		failed int
+		want   time.Duration
+	}{
+		{0, 0},
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{4, 8 * time.Second},
+		{20, LockoutDuration},
+	}
+	for _, tt := range tests {
+		if got := backoffDelay(tt.failed); got != tt.want {
+			t.Errorf("backoffDelay(%d) = %v, want %v", tt.failed, got, tt.want)
+		}
+	}
+}
+
+func TestCheckLockout(t *testing.T) {
+	fixed := time.Date(2024, 11, 1, 12, 0, 0, 0, time.UTC)
+	failedAt := fixed.Add(-time.Second)
+	lockedUntil := fixed.Add(time.Minute)
+	expired := fixed.Add(-time.Minute)
+
+	tests := []struct {
+		name     string
+		attempts models.LoginAttempts
+		want     error
+	}{
+		{"без попыток", models.LoginAttempts{}, nil},
+		{"задержка ещё идёт", models.LoginAttempts{FailedCount: 3, LastFailedAt: &failedAt}, ErrTooManyAttempts},
+		{"задержка прошла", models.LoginAttempts{FailedCount: 1, LastFailedAt: &failedAt}, nil},
+		{"заблокирован", models.LoginAttempts{FailedCount: MaxFailedAttempts, LastFailedAt: &failedAt, LockedUntil: &lockedUntil}, ErrAccountLocked},
+		{"блокировка истекла", models.LoginAttempts{FailedCount: MaxFailedAttempts, LastFailedAt: &failedAt, LockedUntil: &expired}, nil},
+	}
+	for _, tt := range tests {
+		if got := checkLockout(tt.attempts, fixed); got != tt.want {
+			t.Errorf("%s: checkLockout = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRegisterFailureLocks(t *testing.T) {
+	db := &failureService{}
+	fixed := time.Date(2024, 11, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < MaxFailedAttempts; i++ {
+		registerFailure(db, context.Background(), "user", fixed, "wrong password")
+	}
+
+	last := len(db.reasons) - 1
+	for i, reason := range db.reasons[:last] {
+		if reason != "wrong password" {
+			t.Errorf("attempt %d: reason = %q, want %q", i+1, reason, "wrong password")
+		}
+	}
+	if want := "locked: wrong password"; db.reasons[last] != want {
+		t.Errorf("attempt %d: reason = %q, want %q", last+1, db.reasons[last], want)
+	}
+}
@@ -2,7 +2,6 @@ package auth
 
 import (
 	"context"
-	"fmt"
 	"log"
 
 	"github.com/EmptyInsid/db_gui/internal/database"
@@ -23,15 +22,51 @@ func RegistrUser(db database.Service, ctx context.Context, username, password, r
 	return err
 }
 
+// Проверка логина и пароля. Если у пользователя включена двухфакторная аутентификация,
+// возвращаются логин и роль вместе с ErrSecondFactorRequired.
 func AuthenticateUser(db database.Service, ctx context.Context, username, password string) (string, string, error) {
+	attempts, err := db.GetLoginAttempts(ctx, username)
+	if err != nil {
+		log.Printf("Error while get login attempts: %v\n", err)
+		return "", "", err
+	}
+
+	t := now()
+	if err := checkLockout(attempts, t); err != nil {
+		log.Printf("Login for %s refused: %v\n", username, err)
+		if err := db.AddLoginRecord(ctx, username, false, err.Error()); err != nil {
+			log.Printf("Error while add login record: %v\n", err)
+		}
+		return "", "", err
+	}
+
 	storedPassword, role, err := db.AuthUser(ctx, username, password)
 	if err != nil {
 		log.Printf("Error while auth user: %v\n", err)
+		registerFailure(db, ctx, username, t, "unknown user")
 		return "", "", err
 	}
 	if !CheckPasswordHash(password, storedPassword) {
 		log.Printf("Incorrect password")
-		return "", "", fmt.Errorf("incorrect password")
+		registerFailure(db, ctx, username, t, "incorrect password")
+		return "", "", ErrIncorrectPassword
+	}
+
+	// при включённой 2FA вход завершается в VerifySecondFactor
+	secret, err := db.GetTOTPSecret(ctx, username)
+	if err != nil {
+		log.Printf("Error while get totp secret: %v\n", err)
+		return "", "", err
+	}
+	if secret != "" {
+		return username, role, ErrSecondFactorRequired
+	}
+
+	if err := db.ResetLoginAttempts(ctx, username); err != nil {
+		log.Printf("Error while reset login attempts: %v\n", err)
+	}
+	if err := db.AddLoginRecord(ctx, username, true, ""); err != nil {
+		log.Printf("Error while add login record: %v\n", err)
 	}
 	return username, role, nil
 }
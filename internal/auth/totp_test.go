@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+// секрет из RFC 4226 и RFC 6238: ASCII "12345678901234567890"
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestHOTPRFC4226(t *testing.T) {
+	want := []string{
+		"755224", "287082", "359152", "969429", "338314",
+		"254676", "287922", "162583", "399871", "520489",
+	}
+	key, err := decodeSecret(rfcSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for counter, code := range want {
+		if got := hotp(key, uint64(counter)); got != code {
+			t.Errorf("hotp(%d) = %s, want %s", counter, got, code)
+		}
+	}
+}
+
+// векторы RFC 6238 для SHA1, последние 6 цифр восьмизначных кодов
+func TestTOTPRFC6238(t *testing.T) {
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		at := time.Unix(tt.unix, 0).UTC()
+		code, err := TOTPCode(rfcSecret, at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code != tt.code {
+			t.Errorf("TOTPCode(%d) = %s, want %s", tt.unix, code, tt.code)
+		}
+		if !ValidateTOTP(rfcSecret, tt.code, at) {
+			t.Errorf("ValidateTOTP(%d) rejected %s", tt.unix, tt.code)
+		}
+	}
+}
+
+func TestValidateTOTPSkew(t *testing.T) {
+	fixed := time.Date(2024, 11, 1, 12, 0, 15, 0, time.UTC)
+	defer func(orig func() time.Time) { now = orig }(now)
+	now = func() time.Time { return fixed }
+
+	tests := []struct {
+		name   string
+		offset time.Duration
+		ok     bool
+	}{
+		{"текущий шаг", 0, true},
+		{"шаг назад", -totpPeriod * time.Second, true},
+		{"шаг вперёд", totpPeriod * time.Second, true},
+		{"два шага назад", -2 * totpPeriod * time.Second, false},
+		{"два шага вперёд", 2 * totpPeriod * time.Second, false},
+	}
+	for _, tt := range tests {
+		code, err := TOTPCode(rfcSecret, fixed.Add(tt.offset))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ValidateTOTP(rfcSecret, code, now()); got != tt.ok {
+			t.Errorf("%s: ValidateTOTP = %v, want %v", tt.name, got, tt.ok)
+		}
+		if got := ValidateTOTP(rfcSecret, " "+code+" ", now()); got != tt.ok {
+			t.Errorf("%s: code with spaces: ValidateTOTP = %v, want %v", tt.name, got, tt.ok)
+		}
+	}
+}
+
+func TestValidateTOTPMalformed(t *testing.T) {
+	at := time.Unix(59, 0)
+	for _, code := range []string{"", "28708", "2870820", "abcdef"} {
+		if ValidateTOTP(rfcSecret, code, at) {
+			t.Errorf("ValidateTOTP accepted %q", code)
+		}
+	}
+	if ValidateTOTP("not base32!", "287082", at) {
+		t.Error("ValidateTOTP accepted invalid secret")
+	}
+}
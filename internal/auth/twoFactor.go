@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"context"
+	"log"
+
+	"github.com/EmptyInsid/db_gui/internal/database"
+)
+
+// Включена ли у пользователя двухфакторная аутентификация
+func TwoFactorEnabled(db database.Service, ctx context.Context, username string) (bool, error) {
+	secret, err := db.GetTOTPSecret(ctx, username)
+	if err != nil {
+		log.Printf("Error while get totp secret: %v\n", err)
+		return false, err
+	}
+	return secret != "", nil
+}
+
+// Подключить двухфакторную аутентификацию. Код подтверждает, что секрет добавлен в приложение.
+// Возвращает коды восстановления, которые показываются пользователю один раз.
+func EnrollTwoFactor(db database.Service, ctx context.Context, username, secret, code string) ([]string, error) {
+	counter, ok := matchTOTP(secret, code, now())
+	if !ok {
+		return nil, ErrIncorrectCode
+	}
+
+	codes, err := GenerateRecoveryCodes()
+	if err != nil {
+		log.Printf("Error while generate recovery codes: %v\n", err)
+		return nil, err
+	}
+
+	hashes := make([]string, 0, len(codes))
+	for _, code := range codes {
+		hash, err := HashPassword(code)
+		if err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, hash)
+	}
+
+	if err := db.EnableTOTP(ctx, username, secret, hashes); err != nil {
+		log.Printf("Error while enable totp: %v\n", err)
+		return nil, err
+	}
+	// код подтверждения нельзя повторно использовать для входа
+	if _, err := db.AcceptTOTPCounter(ctx, username, int64(counter)); err != nil {
+		log.Printf("Error while accept totp counter: %v\n", err)
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Отключить двухфакторную аутентификацию после проверки текущего кода
+func DisableTwoFactor(db database.Service, ctx context.Context, username, code string) error {
+	if err := checkSecondFactor(db, ctx, username, code); err != nil {
+		return err
+	}
+	if err := db.DisableTOTP(ctx, username); err != nil {
+		log.Printf("Error while disable totp: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+// Второй шаг входа: код TOTP или один из кодов восстановления
+func VerifySecondFactor(db database.Service, ctx context.Context, username, code string) error {
+	attempts, err := db.GetLoginAttempts(ctx, username)
+	if err != nil {
+		log.Printf("Error while get login attempts: %v\n", err)
+		return err
+	}
+
+	t := now()
+	if err := checkLockout(attempts, t); err != nil {
+		log.Printf("Second factor for %s refused: %v\n", username, err)
+		return err
+	}
+
+	if err := checkSecondFactor(db, ctx, username, code); err != nil {
+		if err == ErrIncorrectCode {
+			registerFailure(db, ctx, username, t, "incorrect two-factor code")
+		}
+		return err
+	}
+
+	if err := db.ResetLoginAttempts(ctx, username); err != nil {
+		log.Printf("Error while reset login attempts: %v\n", err)
+	}
+	if err := db.AddLoginRecord(ctx, username, true, "two-factor"); err != nil {
+		log.Printf("Error while add login record: %v\n", err)
+	}
+	return nil
+}
+
+func checkSecondFactor(db database.Service, ctx context.Context, username, code string) error {
+	secret, err := db.GetTOTPSecret(ctx, username)
+	if err != nil {
+		log.Printf("Error while get totp secret: %v\n", err)
+		return err
+	}
+	if secret != "" {
+		if counter, ok := matchTOTP(secret, code, now()); ok {
+			// код принимается один раз: шаг должен быть позже последнего принятого
+			accepted, err := db.AcceptTOTPCounter(ctx, username, int64(counter))
+			if err != nil {
+				log.Printf("Error while accept totp counter: %v\n", err)
+				return err
+			}
+			if accepted {
+				return nil
+			}
+			log.Printf("Two-factor code for %s already used", username)
+			return ErrIncorrectCode
+		}
+	}
+
+	recoveryCodes, err := db.GetRecoveryCodes(ctx, username)
+	if err != nil {
+		log.Printf("Error while get recovery codes: %v\n", err)
+		return err
+	}
+	code = normalizeRecoveryCode(code)
+	for _, recovery := range recoveryCodes {
+		if CheckPasswordHash(code, recovery.CodeHash) {
+			return db.UseRecoveryCode(ctx, recovery.ID)
+		}
+	}
+
+	log.Printf("Incorrect two-factor code")
+	return ErrIncorrectCode
+}
@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/EmptyInsid/db_gui/internal/database"
+	"github.com/EmptyInsid/db_gui/internal/models"
+)
+
+// сервис с секретом TOTP и кодами восстановления одного пользователя в памяти
+type twoFactorDB struct {
+	database.Service
+	secret      string
+	lastCounter int64
+	recovery    []models.RecoveryCode
+	used        map[int]bool
+}
+
+func (db *twoFactorDB) GetTOTPSecret(ctx context.Context, username string) (string, error) {
+	return db.secret, nil
+}
+
+func (db *twoFactorDB) AcceptTOTPCounter(ctx context.Context, username string, counter int64) (bool, error) {
+	if counter <= db.lastCounter {
+		return false, nil
+	}
+	db.lastCounter = counter
+	return true, nil
+}
+
+func (db *twoFactorDB) GetRecoveryCodes(ctx context.Context, username string) ([]models.RecoveryCode, error) {
+	var codes []models.RecoveryCode
+	for _, code := range db.recovery {
+		if !db.used[code.ID] {
+			codes = append(codes, code)
+		}
+	}
+	return codes, nil
+}
+
+func (db *twoFactorDB) UseRecoveryCode(ctx context.Context, id int) error {
+	db.used[id] = true
+	return nil
+}
+
+func TestCheckSecondFactorRejectsReplay(t *testing.T) {
+	fixed := time.Date(2024, 11, 1, 12, 0, 15, 0, time.UTC)
+	defer func(orig func() time.Time) { now = orig }(now)
+	now = func() time.Time { return fixed }
+
+	db := &twoFactorDB{secret: rfcSecret, used: map[int]bool{}}
+	ctx := context.Background()
+
+	previous, _ := TOTPCode(rfcSecret, fixed.Add(-totpPeriod*time.Second))
+	current, _ := TOTPCode(rfcSecret, fixed)
+	next, _ := TOTPCode(rfcSecret, fixed.Add(totpPeriod*time.Second))
+
+	if err := checkSecondFactor(db, ctx, "user", current); err != nil {
+		t.Fatalf("current code: %v", err)
+	}
+	if err := checkSecondFactor(db, ctx, "user", current); err != ErrIncorrectCode {
+		t.Errorf("replayed code: got %v, want ErrIncorrectCode", err)
+	}
+	if err := checkSecondFactor(db, ctx, "user", previous); err != ErrIncorrectCode {
+		t.Errorf("earlier code after later one: got %v, want ErrIncorrectCode", err)
+	}
+	if err := checkSecondFactor(db, ctx, "user", next); err != nil {
+		t.Errorf("later code in window: %v", err)
+	}
+}
+
+func TestCheckSecondFactorRecoveryCode(t *testing.T) {
+	hash, err := HashPassword("abcde-fghij")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := &twoFactorDB{
+		secret:   rfcSecret,
+		recovery: []models.RecoveryCode{{ID: 1, Username: "user", CodeHash: hash}},
+		used:     map[int]bool{},
+	}
+	ctx := context.Background()
+
+	if err := checkSecondFactor(db, ctx, "user", " ABCDE-FGHIJ \n"); err != nil {
+		t.Fatalf("pasted recovery code: %v", err)
+	}
+	if err := checkSecondFactor(db, ctx, "user", "abcde-fghij"); err != ErrIncorrectCode {
+		t.Errorf("used recovery code: got %v, want ErrIncorrectCode", err)
+	}
+}
@@ -0,0 +1,215 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// измерения конструктора отчётов
+const (
+	DimArticle = "article"
+	DimMonth   = "month"
+	DimWeek    = "week"
+	DimTag     = "tag"
+	DimBalance = "balance"
+)
+
+// показатели конструктора отчётов
+const (
+	MeasureDebit     = "sum_debit"
+	MeasureCredit    = "sum_credit"
+	MeasureProfit    = "profit"
+	MeasureCount     = "count"
+	MeasureAvgDebit  = "avg_debit"
+	MeasureAvgCredit = "avg_credit"
+)
+
+// выражения измерений и показателей; в SQL попадают только они,
+// значения фильтров передаются параметрами
+var adhocDimensions = map[string]string{
+	DimArticle: "a.name",
+	DimMonth:   "date_trunc('month', o.create_date)::date",
+	DimWeek:    "date_trunc('week', o.create_date)::date",
+	DimTag:     "a.tag",
+	DimBalance: "CASE WHEN b.id IS NULL THEN '' ELSE to_char(b.start_date, 'YYYY-MM-DD') || ' - ' || to_char(b.create_date, 'YYYY-MM-DD') END",
+}
+
+var adhocMeasures = map[string]string{
+	MeasureDebit:     "COALESCE(SUM(o.debit), 0)::double precision",
+	MeasureCredit:    "COALESCE(SUM(o.credit), 0)::double precision",
+	MeasureProfit:    "COALESCE(SUM(o.debit - o.credit), 0)::double precision",
+	MeasureCount:     "COUNT(*)",
+	MeasureAvgDebit:  "COALESCE(AVG(o.debit), 0)::double precision",
+	MeasureAvgCredit: "COALESCE(AVG(o.credit), 0)::double precision",
+}
+
+// Validate проверяет, что выбраны известные измерения и показатели
+func (spec AdhocSpec) Validate() error {
+	if len(spec.Dimensions) == 0 || len(spec.Measures) == 0 {
+		return ErrAdhocSpec
+	}
+	seen := make(map[string]bool)
+	for _, dim := range spec.Dimensions {
+		if _, ok := adhocDimensions[dim]; !ok || seen[dim] {
+			return ErrAdhocSpec
+		}
+		seen[dim] = true
+	}
+	for _, measure := range spec.Measures {
+		if _, ok := adhocMeasures[measure]; !ok || seen[measure] {
+			return ErrAdhocSpec
+		}
+		seen[measure] = true
+	}
+	if spec.MinAmount != nil && spec.MaxAmount != nil && *spec.MinAmount > *spec.MaxAmount {
+		return ErrAdhocSpec
+	}
+	return nil
+}
+
+// запрос конструктора: группировка по измерениям в заданном порядке.
+// Сумма операции для фильтра - большее из дохода и расхода
+func buildAdhocQuery(spec AdhocSpec, household int) (string, []any, error) {
+	if err := spec.Validate(); err != nil {
+		return "", nil, err
+	}
+
+	args := []any{household}
+	where := []string{"o.household_id = $1", "a.deleted_at IS NULL", "o.deleted_at IS NULL"}
+	arg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if spec.StartDate != "" {
+		where = append(where, "o.create_date >= "+arg(spec.StartDate)+"::date")
+	}
+	if spec.EndDate != "" {
+		where = append(where, "o.create_date <= "+arg(spec.EndDate)+"::date")
+	}
+	if len(spec.Articles) > 0 {
+		where = append(where, "a.name = ANY("+arg(spec.Articles)+")")
+	}
+	if spec.MinAmount != nil {
+		where = append(where, "GREATEST(o.debit, o.credit) >= "+arg(*spec.MinAmount))
+	}
+	if spec.MaxAmount != nil {
+		where = append(where, "GREATEST(o.debit, o.credit) <= "+arg(*spec.MaxAmount))
+	}
+
+	var columns, groups []string
+	for i, dim := range spec.Dimensions {
+		columns = append(columns, adhocDimensions[dim])
+		groups = append(groups, fmt.Sprint(i+1))
+	}
+	for _, measure := range spec.Measures {
+		columns = append(columns, adhocMeasures[measure])
+	}
+
+	query := `
+	SELECT ` + strings.Join(columns, ", ") + `
+	FROM operations o
+	JOIN articles a ON a.id = o.article_id
+	LEFT JOIN balance b ON b.id = o.balance_id AND b.deleted_at IS NULL
+	WHERE ` + strings.Join(where, " AND ") + `
+	GROUP BY ` + strings.Join(groups, ", ") + `
+	ORDER BY ` + strings.Join(groups, ", ")
+
+	return query, args, nil
+}
+
+// Выполнить отчёт конструктора: строки со значениями измерений, затем показателей
+func (db *Database) RunAdhocReport(ctx context.Context, spec AdhocSpec) ([][]any, error) {
+	query, args, err := buildAdhocQuery(spec, db.household())
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.pool.Query(ctx, query, args...)
+	if err != nil {
+		log.Printf("Failed to run adhoc report: %v\n", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result [][]any
+	for rows.Next() {
+		values, err := rows.Values()
+		if err != nil {
+			log.Printf("Failed to scan row: %v\n", err)
+			return nil, err
+		}
+		result = append(result, values)
+	}
+	return result, rows.Err()
+}
+
+// сохранённые отчёты конструктора текущего пользователя
+func (db *Database) GetSavedReports(ctx context.Context) ([]SavedReport, error) {
+	query := `
+	SELECT id, name, spec FROM saved_reports
+	WHERE household_id = $1 AND username = $2
+	ORDER BY name
+	`
+
+	rows, err := db.pool.Query(ctx, query, db.household(), db.user())
+	if err != nil {
+		log.Printf("Error while get saved reports: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reports []SavedReport
+	for rows.Next() {
+		var saved SavedReport
+		var spec []byte
+		if err := rows.Scan(&saved.ID, &saved.Name, &spec); err != nil {
+			log.Printf("Error while scan saved report: %v", err)
+			return nil, err
+		}
+		if err := json.Unmarshal(spec, &saved.Spec); err != nil {
+			log.Printf("Error while decode saved report %d: %v", saved.ID, err)
+			return nil, err
+		}
+		reports = append(reports, saved)
+	}
+	return reports, nil
+}
+
+// сохранить отчёт конструктора под именем, одноимённый отчёт пользователя перезаписывается
+func (db *Database) SaveReport(ctx context.Context, name string, spec AdhocSpec) error {
+	if err := spec.Validate(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO saved_reports (household_id, username, name, spec)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (household_id, username, name) DO UPDATE SET spec = EXCLUDED.spec
+	`
+	if _, err := db.pool.Exec(ctx, query, db.household(), db.user(), name, data); err != nil {
+		log.Printf("Error while save report: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (db *Database) DeleteSavedReport(ctx context.Context, id int) error {
+	commandTag, err := db.pool.Exec(ctx, "DELETE FROM saved_reports WHERE id = $1 AND household_id = $2 AND username = $3",
+		id, db.household(), db.user())
+	if err != nil {
+		log.Printf("Error while delete saved report: %v", err)
+		return err
+	}
+	if commandTag.RowsAffected() == 0 {
+		return ErrEmptyRow
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package database
+
+import (
+	"context"
+	"log"
+
+	"github.com/EmptyInsid/db_gui/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// сущности журнала изменений
+const (
+	AuditArticle   = "article"
+	AuditOperation = "operation"
+	AuditBalance   = "balance"
+	AuditHousehold = "household"
+	AuditRecurring = "recurring"
+	AuditSchedule  = "schedule"
+)
+
+// снимок строк запроса в виде JSON-массива, nil если строк нет
+func snapshot(ctx context.Context, tx pgx.Tx, query string, args ...any) ([]byte, error) {
+	var data []byte
+	err := tx.QueryRow(ctx, "SELECT jsonb_agg(to_jsonb(t)) FROM ("+query+") t", args...).Scan(&data)
+	if err != nil {
+		log.Printf("Error while take audit snapshot: %v", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// записать изменение в журнал в рамках транзакции изменения
+func (db *Database) audit(ctx context.Context, tx pgx.Tx, action, entity string, before, after []byte) error {
+	return auditAs(ctx, tx, db.household(), db.user(), action, entity, before, after)
+}
+
+// записать изменение от имени заданного пользователя и домохозяйства, например при фоновой очистке
+func auditAs(ctx context.Context, tx pgx.Tx, householdID int, username, action, entity string, before, after []byte) error {
+	query := `
+	INSERT INTO audit_log (household_id, username, action, entity, before_data, after_data)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	if _, err := tx.Exec(ctx, query, householdID, username, action, entity, before, after); err != nil {
+		log.Printf("Error while write audit log: %v", err)
+		return err
+	}
+	return nil
+}
+
+// номер последнего изменения данных активного домохозяйства,
+// по его росту экраны узнают, что данные нужно перечитать
+func (db *Database) GetDataVersion(ctx context.Context) (int64, error) {
+	var version int64
+	err := db.pool.QueryRow(ctx, "SELECT COALESCE(MAX(id), 0) FROM audit_log WHERE household_id = $1", db.household()).Scan(&version)
+	if err != nil {
+		log.Printf("Error while get data version: %v", err)
+		return 0, err
+	}
+	return version, nil
+}
+
+// получить журнал изменений активного домохозяйства с фильтрами
+func (db *Database) GetAuditLog(ctx context.Context, filter AuditFilter) ([]models.AuditEntry, error) {
+	query := `
+	SELECT id, COALESCE(household_id, 0), username, action, entity,
+		COALESCE(before_data::text, ''), COALESCE(after_data::text, ''), created_at
+	FROM audit_log
+	WHERE household_id = $1
+	  AND ($2 = '' OR username = $2)
+	  AND ($3 = '' OR entity = $3)
+	  AND ($4 = '' OR created_at >= $4::date)
+	  AND ($5 = '' OR created_at < $5::date + 1)
+	ORDER BY created_at DESC, id DESC
+	`
+
+	rows, err := db.pool.Query(ctx, query, db.household(), filter.Username, filter.Entity, filter.StartDate, filter.EndDate)
+	if err != nil {
+		log.Printf("Error while get audit log: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.AuditEntry
+	for rows.Next() {
+		var entry models.AuditEntry
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.HouseholdID,
+			&entry.Username,
+			&entry.Action,
+			&entry.Entity,
+			&entry.Before,
+			&entry.After,
+			&entry.Date,
+		); err != nil {
+			log.Printf("Error while get audit log: %v", err)
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
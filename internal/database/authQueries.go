@@ -0,0 +1,290 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/EmptyInsid/db_gui/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// получить счётчик неудачных попыток входа пользователя
+func (db *Database) GetLoginAttempts(ctx context.Context, username string) (models.LoginAttempts, error) {
+	query := `
+	SELECT username, failed_count, last_failed_at, locked_until
+	FROM login_attempts
+	WHERE username = $1
+	`
+
+	attempts := models.LoginAttempts{Username: username}
+	err := db.pool.QueryRow(ctx, query, username).Scan(
+		&attempts.Username,
+		&attempts.FailedCount,
+		&attempts.LastFailedAt,
+		&attempts.LockedUntil,
+	)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		log.Printf("Error while get login attempts: %v", err)
+		return attempts, err
+	}
+	return attempts, nil
+}
+
+// получить все учётные записи с неудачными попытками входа
+func (db *Database) GetAllLoginAttempts(ctx context.Context) ([]models.LoginAttempts, error) {
+	query := `
+	SELECT username, failed_count, last_failed_at, locked_until
+	FROM login_attempts
+	ORDER BY username
+	`
+
+	rows, err := db.pool.Query(ctx, query)
+	if err != nil {
+		log.Printf("Error while get login attempts: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attempts []models.LoginAttempts
+	for rows.Next() {
+		var attempt models.LoginAttempts
+		if err := rows.Scan(
+			&attempt.Username,
+			&attempt.FailedCount,
+			&attempt.LastFailedAt,
+			&attempt.LockedUntil,
+		); err != nil {
+			log.Printf("Error while get login attempts: %v", err)
+			return nil, err
+		}
+		attempts = append(attempts, attempt)
+	}
+
+	return attempts, nil
+}
+
+// записать неудачную попытку входа и вернуть новое число попыток подряд.
+// Счётчик увеличивается в запросе, поэтому одновременные попытки не теряются;
+// после истёкшей блокировки отсчёт начинается заново, при maxFailed попыток
+// учётная запись блокируется до lockedUntil
+func (db *Database) RecordLoginFailure(ctx context.Context, username string, failedAt time.Time, maxFailed int, lockedUntil time.Time) (int, error) {
+	query := `
+	INSERT INTO login_attempts AS a (username, failed_count, last_failed_at, locked_until)
+	VALUES ($1, 1, $2, CASE WHEN 1 >= $3 THEN $4::timestamptz END)
+	ON CONFLICT (username) DO UPDATE
+	SET failed_count = ` + failedCountExpr + `,
+		last_failed_at = EXCLUDED.last_failed_at,
+		locked_until = CASE WHEN ` + failedCountExpr + ` >= $3 THEN $4::timestamptz END
+	RETURNING failed_count
+	`
+
+	var failed int
+	if err := db.pool.QueryRow(ctx, query, username, failedAt, maxFailed, lockedUntil).Scan(&failed); err != nil {
+		log.Printf("Error while record login failure: %v", err)
+		return 0, err
+	}
+	return failed, nil
+}
+
+// новое число неудачных попыток; в SET столбцы a.* - значения до обновления
+const failedCountExpr = `CASE WHEN a.locked_until IS NOT NULL AND a.locked_until <= EXCLUDED.last_failed_at
+		THEN 1 ELSE a.failed_count + 1 END`
+
+// сбросить счётчик неудачных попыток входа и снять блокировку
+func (db *Database) ResetLoginAttempts(ctx context.Context, username string) error {
+	if _, err := db.pool.Exec(ctx, "DELETE FROM login_attempts WHERE username = $1", username); err != nil {
+		log.Printf("Error while reset login attempts: %v", err)
+		return err
+	}
+	return nil
+}
+
+// добавить запись о попытке входа в историю входов
+func (db *Database) AddLoginRecord(ctx context.Context, username string, success bool, reason string) error {
+	query := `INSERT INTO login_history (username, success, reason, event) VALUES ($1, $2, $3, $4)`
+
+	if _, err := db.pool.Exec(ctx, query, username, success, reason, models.LoginEventLogin); err != nil {
+		log.Printf("Error while add login record: %v", err)
+		return err
+	}
+	return nil
+}
+
+// добавить в историю входов разблокировку учётной записи администратором
+func (db *Database) AddUnlockRecord(ctx context.Context, username, reason string) error {
+	query := `INSERT INTO login_history (username, success, reason, event) VALUES ($1, true, $2, $3)`
+
+	if _, err := db.pool.Exec(ctx, query, username, reason, models.LoginEventUnlock); err != nil {
+		log.Printf("Error while add unlock record: %v", err)
+		return err
+	}
+	return nil
+}
+
+// получить историю входов, начиная с последних
+func (db *Database) GetLoginHistory(ctx context.Context) ([]models.LoginRecord, error) {
+	query := `
+	SELECT id, username, success, reason, event, created_at
+	FROM login_history
+	ORDER BY created_at DESC, id DESC
+	`
+
+	rows, err := db.pool.Query(ctx, query)
+	if err != nil {
+		log.Printf("Error while get login history: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []models.LoginRecord
+	for rows.Next() {
+		var record models.LoginRecord
+		if err := rows.Scan(
+			&record.ID,
+			&record.Username,
+			&record.Success,
+			&record.Reason,
+			&record.Event,
+			&record.Date,
+		); err != nil {
+			log.Printf("Error while get login history: %v", err)
+			return nil, err
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// получить секрет TOTP пользователя, пустая строка - двухфакторная аутентификация отключена
+func (db *Database) GetTOTPSecret(ctx context.Context, username string) (string, error) {
+	var secret string
+	err := db.pool.QueryRow(ctx, "SELECT secret FROM user_totp WHERE username = $1", username).Scan(&secret)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		log.Printf("Error while get totp secret: %v", err)
+		return "", err
+	}
+	return secret, nil
+}
+
+// включить двухфакторную аутентификацию и заменить коды восстановления
+func (db *Database) EnableTOTP(ctx context.Context, username, secret string, recoveryHashes []string) error {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+	INSERT INTO user_totp (username, secret) VALUES ($1, $2)
+	ON CONFLICT (username) DO UPDATE SET secret = EXCLUDED.secret, last_counter = 0, created_at = now()
+	`
+	if _, err := tx.Exec(ctx, query, username, secret); err != nil {
+		log.Printf("Error while save totp secret: %v", err)
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM user_recovery_codes WHERE username = $1", username); err != nil {
+		log.Printf("Error while delete recovery codes: %v", err)
+		return err
+	}
+	for _, hash := range recoveryHashes {
+		if _, err := tx.Exec(ctx, "INSERT INTO user_recovery_codes (username, code_hash) VALUES ($1, $2)", username, hash); err != nil {
+			log.Printf("Error while insert recovery code: %v", err)
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error commit transaction: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+// запомнить принятый шаг TOTP; false - код этого шага или более позднего уже принимался
+func (db *Database) AcceptTOTPCounter(ctx context.Context, username string, counter int64) (bool, error) {
+	commandTag, err := db.pool.Exec(ctx, "UPDATE user_totp SET last_counter = $2 WHERE username = $1 AND last_counter < $2",
+		username, counter)
+	if err != nil {
+		log.Printf("Error while accept totp counter: %v", err)
+		return false, err
+	}
+	return commandTag.RowsAffected() == 1, nil
+}
+
+// отключить двухфакторную аутентификацию
+func (db *Database) DisableTOTP(ctx context.Context, username string) error {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	commandTag, err := tx.Exec(ctx, "DELETE FROM user_totp WHERE username = $1", username)
+	if err != nil {
+		log.Printf("Error while delete totp secret: %v", err)
+		return err
+	}
+	if commandTag.RowsAffected() == 0 {
+		log.Printf("Error no totp secret for user: %s", username)
+		return ErrEmptyRow
+	}
+	if _, err := tx.Exec(ctx, "DELETE FROM user_recovery_codes WHERE username = $1", username); err != nil {
+		log.Printf("Error while delete recovery codes: %v", err)
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error commit transaction: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+// получить неиспользованные коды восстановления пользователя
+func (db *Database) GetRecoveryCodes(ctx context.Context, username string) ([]models.RecoveryCode, error) {
+	query := `
+	SELECT id, username, code_hash
+	FROM user_recovery_codes
+	WHERE username = $1 AND used_at IS NULL
+	ORDER BY id
+	`
+
+	rows, err := db.pool.Query(ctx, query, username)
+	if err != nil {
+		log.Printf("Error while get recovery codes: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var codes []models.RecoveryCode
+	for rows.Next() {
+		var code models.RecoveryCode
+		if err := rows.Scan(&code.ID, &code.Username, &code.CodeHash); err != nil {
+			log.Printf("Error while get recovery codes: %v", err)
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+
+	return codes, nil
+}
+
+// пометить код восстановления использованным
+func (db *Database) UseRecoveryCode(ctx context.Context, id int) error {
+	commandTag, err := db.pool.Exec(ctx, "UPDATE user_recovery_codes SET used_at = now() WHERE id = $1 AND used_at IS NULL", id)
+	if err != nil {
+		log.Printf("Error while use recovery code: %v", err)
+		return err
+	}
+	if commandTag.RowsAffected() == 0 {
+		log.Printf("Error recovery code %d already used", id)
+		return ErrEmptyRow
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package database
+
+import (
+	"context"
+	"log"
+
+	"github.com/EmptyInsid/db_gui/internal/models"
+)
+
+// скалярный подзапрос: деньги на руках домохозяйства на конец даты -
+// начальный остаток плюс прибыль всех операций с даты начального остатка
+func moneyOnHandExpr(household, date string) string {
+	return `(
+	SELECT h.opening_balance + COALESCE((
+		SELECT SUM(o.debit - o.credit) FROM operations o
+		WHERE o.household_id = h.id AND o.deleted_at IS NULL
+		  AND o.create_date <= ` + date + `
+		  AND (h.opening_date IS NULL OR o.create_date >= h.opening_date)
+	), 0)
+	FROM households h WHERE h.id = ` + household + `
+	)`
+}
+
+// получить начальный остаток активного домохозяйства
+func (db *Database) GetOpeningBalance(ctx context.Context) (models.OpeningBalance, error) {
+	var opening models.OpeningBalance
+	query := "SELECT opening_balance, opening_date FROM households WHERE id = $1"
+	if err := db.pool.QueryRow(ctx, query, db.household()).Scan(&opening.Amount, &opening.Date); err != nil {
+		log.Printf("Error while get opening balance: %v", err)
+		return opening, err
+	}
+	return opening, nil
+}
+
+// задать начальный остаток на начало даты, пустая дата - с самой первой операции
+func (db *Database) SetOpeningBalance(ctx context.Context, amount float64, date string) error {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	selectQuery := "SELECT id, name, opening_balance, opening_date FROM households WHERE id = $1"
+	before, err := snapshot(ctx, tx, selectQuery, db.household())
+	if err != nil {
+		return err
+	}
+
+	query := "UPDATE households SET opening_balance = $1, opening_date = NULLIF($2, '')::date WHERE id = $3"
+	if _, err := tx.Exec(ctx, query, amount, date, db.household()); err != nil {
+		log.Printf("Error while set opening balance: %v", err)
+		return err
+	}
+
+	after, err := snapshot(ctx, tx, selectQuery, db.household())
+	if err != nil {
+		return err
+	}
+	if err := db.audit(ctx, tx, "opening balance", AuditHousehold, before, after); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error commit transaction: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+// деньги на руках на конец заданной даты
+func (db *Database) GetMoneyOnHand(ctx context.Context, date string) (float64, error) {
+	var money float64
+	query := "SELECT " + moneyOnHandExpr("$1", "$2::date")
+	if err := db.pool.QueryRow(ctx, query, db.household(), date).Scan(&money); err != nil {
+		log.Printf("Error while get money on hand: %v", err)
+		return 0, err
+	}
+	return money, nil
+}
@@ -0,0 +1,109 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+// закрыть по порядку все незакрытые периоды заданного типа до даты включительно.
+// Закрытие останавливается на первом периоде с прибылью ниже порога,
+// в режиме dryRun ничего не записывается и возвращается предпросмотр всех периодов.
+func (db *Database) CloseBalances(ctx context.Context, periodType, untilDate string, minProfit float64, dryRun bool) ([]ClosingPeriod, error) {
+	periods, err := db.unclosedPeriods(ctx, periodType, untilDate, minProfit)
+	if err != nil {
+		return nil, err
+	}
+	if dryRun {
+		return periods, nil
+	}
+
+	for i, period := range periods {
+		if !period.Passes {
+			log.Printf("Batch closing stopped at %s: profit %f is less than %f", period.Start.Format("2006-01-02"), period.Profit, minProfit)
+			break
+		}
+
+		err := db.CreateBalanceIfProfitable(ctx, period.PeriodType, period.Start.Format("2006-01-02"), period.End.Format("2006-01-02"), minProfit)
+		if errors.Is(err, ErrLessThenMin) {
+			// операции изменились после предпросмотра
+			periods[i].Passes = false
+			break
+		}
+		if err != nil {
+			return periods, err
+		}
+		periods[i].Closed = true
+	}
+	return periods, nil
+}
+
+// незакрытые периоды от конца последнего баланса (или первой операции) до даты
+func (db *Database) unclosedPeriods(ctx context.Context, periodType, untilDate string, minProfit float64) ([]ClosingPeriod, error) {
+	if periodType == PeriodCustom {
+		return nil, ErrPeriodInvalid
+	}
+	until, err := time.Parse("2006-01-02", untilDate)
+	if err != nil {
+		log.Printf("Error failed to parse closing date: %v", err)
+		return nil, ErrPeriodInvalid
+	}
+
+	query := `
+	SELECT COALESCE(
+		(SELECT MAX(create_date) + 1 FROM balance WHERE household_id = $1 AND deleted_at IS NULL),
+		(SELECT MIN(create_date) FROM operations WHERE household_id = $1 AND deleted_at IS NULL)
+	)
+	`
+	var from *time.Time
+	if err := db.pool.QueryRow(ctx, query, db.household()).Scan(&from); err != nil {
+		log.Printf("Error while get first unclosed date: %v", err)
+		return nil, err
+	}
+	if from == nil {
+		return nil, nil
+	}
+
+	hasBalances := false
+	if err := db.pool.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM balance WHERE household_id = $1 AND deleted_at IS NULL)", db.household()).Scan(&hasBalances); err != nil {
+		log.Printf("Error while check balances: %v", err)
+		return nil, err
+	}
+
+	start := *from
+	if !hasBalances {
+		// без балансов закрытие начинается с периода первой операции
+		start, _ = PeriodBounds(periodType, start)
+	}
+
+	sumQuery := `
+	SELECT COALESCE(SUM(debit), 0), COALESCE(SUM(credit), 0)
+	FROM operations
+	WHERE household_id = $1 AND deleted_at IS NULL AND create_date BETWEEN $2 AND $3
+	`
+
+	var periods []ClosingPeriod
+	for {
+		periodStart, end := PeriodBounds(periodType, start)
+		period := ClosingPeriod{PeriodType: periodType, Start: start, End: end}
+		if !sameDay(periodStart, start) {
+			// последний баланс закончился внутри периода: остаток периода закрывается произвольным
+			period.PeriodType = PeriodCustom
+		}
+		if end.After(until) {
+			break
+		}
+
+		if err := db.pool.QueryRow(ctx, sumQuery, db.household(), period.Start, period.End).Scan(&period.Debit, &period.Credit); err != nil {
+			log.Printf("Error failed to calculate debit/credit: %v", err)
+			return nil, err
+		}
+		period.Profit = period.Debit - period.Credit
+		period.Passes = period.Profit >= minProfit
+
+		periods = append(periods, period)
+		start = end.AddDate(0, 0, 1)
+	}
+	return periods, nil
+}
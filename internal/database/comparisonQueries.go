@@ -0,0 +1,79 @@
+package database
+
+import (
+	"context"
+	"log"
+	"math"
+)
+
+// Сравнение потока (доход, расход или прибыль) по статьям за два периода.
+// Пустой список статей - все статьи. Статьи без операций в периоде получают ноль,
+// процент изменения не определён при нулевой базе
+func (db *Database) GetArticleComparison(ctx context.Context, articles []string, flow, baseStart, baseEnd, currentStart, currentEnd string) ([]ArticleComparison, error) {
+	query := `
+	WITH totals AS (
+		SELECT a.name AS article_name,
+			COALESCE(SUM(CASE $1
+				WHEN 'debit' THEN o.debit
+				WHEN 'credit' THEN o.credit
+				ELSE o.debit - o.credit
+			END) FILTER (WHERE o.create_date BETWEEN $3 AND $4), 0) AS base,
+			COALESCE(SUM(CASE $1
+				WHEN 'debit' THEN o.debit
+				WHEN 'credit' THEN o.credit
+				ELSE o.debit - o.credit
+			END) FILTER (WHERE o.create_date BETWEEN $5 AND $6), 0) AS current
+		FROM articles a
+		LEFT JOIN operations o ON o.article_id = a.id AND o.deleted_at IS NULL
+		WHERE (COALESCE(cardinality($2::text[]), 0) = 0 OR a.name = ANY($2))
+		  AND a.household_id = $7
+		  AND a.deleted_at IS NULL
+		GROUP BY a.name
+	)
+	SELECT article_name, base, current, current - base,
+		(current - base) * 100 / NULLIF(ABS(base), 0)
+	FROM totals
+	ORDER BY article_name
+	`
+
+	rows, err := db.pool.Query(ctx, query, flow, articles, baseStart, baseEnd, currentStart, currentEnd, db.household())
+	if err != nil {
+		log.Printf("Failed to get article comparison: %v\n", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var comparisons []ArticleComparison
+	for rows.Next() {
+		var comparison ArticleComparison
+		err := rows.Scan(
+			&comparison.ArticleName,
+			&comparison.Base,
+			&comparison.Current,
+			&comparison.Change,
+			&comparison.ChangeProc,
+		)
+		if err != nil {
+			log.Printf("Failed to scan row: %v\n", err)
+			return nil, err
+		}
+		comparisons = append(comparisons, comparison)
+	}
+
+	return comparisons, nil
+}
+
+// ComparisonTotal - итоговая строка сравнения по всем статьям
+func ComparisonTotal(data []ArticleComparison) ArticleComparison {
+	total := ArticleComparison{ArticleName: "Итого"}
+	for _, row := range data {
+		total.Base += row.Base
+		total.Current += row.Current
+	}
+	total.Change = total.Current - total.Base
+	if total.Base != 0 {
+		proc := total.Change * 100 / math.Abs(total.Base)
+		total.ChangeProc = &proc
+	}
+	return total
+}
@@ -1,11 +1,47 @@
 package database
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 var (
 	ErrEmptyRow    = errors.New("Return empty row")
 	ErrLessThenMin = errors.New("Balance profit less then minimum")
+	ErrNotMember   = errors.New("User is not a member of household")
+
+	ErrRestoreConflict = errors.New("Active record with the same key already exists")
+	ErrArticleDeleted  = errors.New("Article of operation is deleted")
+
+	ErrPeriodClosed      = errors.New("Period is closed by balance")
+	ErrPeriodNotReopened = errors.New("Period is not reopened")
+	ErrRepairFailed      = errors.New("Balances are still inconsistent after repair")
+
+	ErrAdhocSpec    = errors.New("Unknown or empty report dimensions or measures")
+	ErrScheduleSpec = errors.New("Report schedule needs a known frequency and a directory")
+	ErrPresetSpec   = errors.New("Report preset needs a report and a name")
+
+	ErrPeriodInvalid = errors.New("Period bounds do not match period type")
+	ErrPeriodOverlap = errors.New("Period overlaps an existing balance")
+	ErrPeriodGap     = errors.New("Period leaves a gap between balances")
 
 	ErrGetProfit = errors.New("Error while getting profit")
 	ErrGetCredit = errors.New("Error while getting credit")
 )
+
+// ClosedPeriodError возвращается при изменении операций периода, закрытого балансом
+type ClosedPeriodError struct {
+	BalanceID int
+	PeriodEnd time.Time
+	Date      time.Time // дата затронутой операции
+}
+
+func (e *ClosedPeriodError) Error() string {
+	return fmt.Sprintf("Period is closed by balance %d (%s), operation date %s",
+		e.BalanceID, e.PeriodEnd.Format("2006-01-02"), e.Date.Format("2006-01-02"))
+}
+
+func (e *ClosedPeriodError) Is(target error) bool {
+	return target == ErrPeriodClosed
+}
@@ -0,0 +1,140 @@
+package database
+
+import (
+	"context"
+	"log"
+
+	"github.com/EmptyInsid/db_gui/internal/models"
+)
+
+// помесячные доходы и расходы по статьям за период, для прогноза
+func (db *Database) GetMonthlyArticleTotals(ctx context.Context, startDate, endDate string) ([]ArticleMonthTotal, error) {
+	query := `
+	SELECT a.name, date_trunc('month', o.create_date)::date AS month,
+		COALESCE(SUM(o.debit), 0), COALESCE(SUM(o.credit), 0)
+	FROM operations o
+	JOIN articles a ON o.article_id = a.id
+	WHERE o.household_id = $3
+	  AND a.deleted_at IS NULL AND o.deleted_at IS NULL
+	  AND o.create_date BETWEEN $1 AND $2
+	GROUP BY a.name, month
+	ORDER BY month, a.name
+	`
+
+	rows, err := db.pool.Query(ctx, query, startDate, endDate, db.household())
+	if err != nil {
+		log.Printf("Failed to get monthly article totals: %v\n", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var totals []ArticleMonthTotal
+	for rows.Next() {
+		var total ArticleMonthTotal
+		if err := rows.Scan(&total.ArticleName, &total.Month, &total.TotalDebit, &total.TotalCredit); err != nil {
+			log.Printf("Failed to scan row: %v\n", err)
+			return nil, err
+		}
+		totals = append(totals, total)
+	}
+	return totals, nil
+}
+
+// получить регулярные платежи активного домохозяйства
+func (db *Database) GetRecurringItems(ctx context.Context) ([]models.RecurringItem, error) {
+	query := `
+	SELECT r.id, a.name, r.debit, r.credit, r.start_date, r.end_date, r.every_months
+	FROM recurring_items r
+	JOIN articles a ON a.id = r.article_id
+	WHERE r.household_id = $1 AND a.deleted_at IS NULL
+	ORDER BY r.id
+	`
+
+	rows, err := db.pool.Query(ctx, query, db.household())
+	if err != nil {
+		log.Printf("Error while get recurring items: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []models.RecurringItem
+	for rows.Next() {
+		var item models.RecurringItem
+		if err := rows.Scan(&item.ID, &item.ArticleName, &item.Debit, &item.Credit, &item.StartDate, &item.EndDate, &item.EveryMonths); err != nil {
+			log.Printf("Error while scan recurring item: %v", err)
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// добавить регулярный платёж, пустая дата окончания - без ограничения
+func (db *Database) AddRecurringItem(ctx context.Context, item models.RecurringItem) error {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+	INSERT INTO recurring_items (household_id, article_id, debit, credit, start_date, end_date, every_months)
+	VALUES ($1, (SELECT id FROM articles WHERE name = $2 AND household_id = $1 AND deleted_at IS NULL), $3, $4, $5, $6, $7)
+	RETURNING id
+	`
+	var id int
+	err = tx.QueryRow(ctx, query, db.household(), item.ArticleName, item.Debit, item.Credit, item.StartDate, item.EndDate, item.EveryMonths).Scan(&id)
+	if err != nil {
+		log.Printf("Error while insert recurring item: %v", err)
+		return err
+	}
+
+	after, err := snapshot(ctx, tx, "SELECT * FROM recurring_items WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	if err := db.audit(ctx, tx, "add", AuditRecurring, nil, after); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error commit transaction: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+func (db *Database) DeleteRecurringItem(ctx context.Context, id int) error {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	selectQuery := "SELECT * FROM recurring_items WHERE id = $1 AND household_id = $2"
+	before, err := snapshot(ctx, tx, selectQuery, id, db.household())
+	if err != nil {
+		return err
+	}
+
+	commandTag, err := tx.Exec(ctx, "DELETE FROM recurring_items WHERE id = $1 AND household_id = $2", id, db.household())
+	if err != nil {
+		log.Printf("Error while delete recurring item: %v", err)
+		return err
+	}
+	if commandTag.RowsAffected() == 0 {
+		log.Printf("Error no recurring item found with id: %d", id)
+		return ErrEmptyRow
+	}
+
+	if err := db.audit(ctx, tx, "delete", AuditRecurring, before, nil); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error commit transaction: %v\n", err)
+		return err
+	}
+	return nil
+}
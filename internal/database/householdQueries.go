@@ -0,0 +1,179 @@
+package database
+
+import (
+	"context"
+	"log"
+
+	"github.com/EmptyInsid/db_gui/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// активное домохозяйство сессии
+func (db *Database) household() int {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.householdID
+}
+
+// пользователь текущей сессии
+func (db *Database) user() string {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.username
+}
+
+// CurrentHousehold возвращает идентификатор активного домохозяйства
+func (db *Database) CurrentHousehold() int {
+	return db.household()
+}
+
+// CurrentUser возвращает пользователя текущей сессии
+func (db *Database) CurrentUser() string {
+	return db.user()
+}
+
+// ForHousehold - сервис на том же пуле соединений с другой сессией, текущая не меняется.
+// Нужен фоновым задачам, например расписаниям отчётов; закрывать его не нужно
+func (db *Database) ForHousehold(username string, householdID int) Service {
+	return &Database{pool: db.pool, username: username, householdID: householdID}
+}
+
+// получить домохозяйства, в которых состоит пользователь
+func (db *Database) GetUserHouseholds(ctx context.Context, username string) ([]models.Household, error) {
+	query := `
+	SELECT h.id, h.name
+	FROM households h
+	JOIN household_members m ON m.household_id = h.id
+	WHERE m.username = $1
+	ORDER BY h.id
+	`
+
+	rows, err := db.pool.Query(ctx, query, username)
+	if err != nil {
+		log.Printf("Error while get households: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var households []models.Household
+	for rows.Next() {
+		var household models.Household
+		if err := rows.Scan(&household.ID, &household.Name); err != nil {
+			log.Printf("Error while get households: %v", err)
+			return nil, err
+		}
+		households = append(households, household)
+	}
+
+	return households, nil
+}
+
+// создать домохозяйство и добавить в него пользователя
+func (db *Database) AddHousehold(ctx context.Context, name, username string) (int, error) {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	id, err := insertHousehold(ctx, tx, name, username)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error commit transaction: %v\n", err)
+		return 0, err
+	}
+	return id, nil
+}
+
+// PersonalHouseholdName - название личного домохозяйства пользователя
+func PersonalHouseholdName(username string) string {
+	return "Бюджет " + username
+}
+
+// создать домохозяйство с единственным участником в транзакции
+func insertHousehold(ctx context.Context, tx pgx.Tx, name, username string) (int, error) {
+	var id int
+	if err := tx.QueryRow(ctx, "INSERT INTO households (name) VALUES ($1) RETURNING id", name).Scan(&id); err != nil {
+		log.Printf("Error while insert household: %v", err)
+		return 0, err
+	}
+	if _, err := tx.Exec(ctx, "INSERT INTO household_members (household_id, username) VALUES ($1, $2)", id, username); err != nil {
+		log.Printf("Error while insert household member: %v", err)
+		return 0, err
+	}
+
+	after, err := snapshot(ctx, tx, "SELECT * FROM households WHERE id = $1", id)
+	if err != nil {
+		return 0, err
+	}
+	// запись относится к новому домохозяйству, а не к активному
+	if err := auditAs(ctx, tx, id, username, "create", AuditHousehold, nil, after); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// добавить существующего пользователя в активное домохозяйство
+func (db *Database) AddHouseholdMember(ctx context.Context, username string) error {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+	INSERT INTO household_members (household_id, username)
+	SELECT $1, username FROM users WHERE username = $2
+	ON CONFLICT DO NOTHING
+	`
+
+	commandTag, err := tx.Exec(ctx, query, db.household(), username)
+	if err != nil {
+		log.Printf("Error while insert household member: %v", err)
+		return err
+	}
+	if commandTag.RowsAffected() == 0 {
+		log.Printf("Error no user to add with name: %s", username)
+		return ErrEmptyRow
+	}
+
+	after, err := snapshot(ctx, tx, "SELECT * FROM household_members WHERE household_id = $1 AND username = $2", db.household(), username)
+	if err != nil {
+		return err
+	}
+	if err := db.audit(ctx, tx, "add member", AuditHousehold, nil, after); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error commit transaction: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+// сделать домохозяйство активным для сессии пользователя
+func (db *Database) UseHousehold(ctx context.Context, username string, householdID int) error {
+	query := `SELECT EXISTS (SELECT 1 FROM household_members WHERE household_id = $1 AND username = $2)`
+
+	var member bool
+	if err := db.pool.QueryRow(ctx, query, householdID, username).Scan(&member); err != nil {
+		log.Printf("Error while check household member: %v", err)
+		return err
+	}
+	if !member {
+		log.Printf("Error user %s is not a member of household %d", username, householdID)
+		return ErrNotMember
+	}
+
+	db.mu.Lock()
+	db.username = username
+	db.householdID = householdID
+	db.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,226 @@
+package database
+
+import (
+	"context"
+	"log"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// суммы хранятся в DOUBLE PRECISION, расхождение меньше половины копейки - ошибка округления
+const moneyTolerance = "0.005"
+
+// условие SQL: суммы a и b расходятся больше, чем на ошибку округления
+func moneyDiffers(a, b string) string {
+	return "abs((" + a + ") - (" + b + ")) > " + moneyTolerance
+}
+
+// проверить балансы активного домохозяйства: пересчитать итоги по операциям,
+// найти ссылки на несуществующие балансы и неучтённые операции закрытых периодов
+func (db *Database) VerifyBalances(ctx context.Context) (IntegrityReport, error) {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return IntegrityReport{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	return db.verifyBalances(ctx, tx)
+}
+
+// исправить найденные нарушения в одной транзакции и вернуть исправленный отчёт
+func (db *Database) RepairBalances(ctx context.Context) (IntegrityReport, error) {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return IntegrityReport{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	// балансы блокируются до конца исправления
+	if _, err := tx.Exec(ctx, "SELECT id FROM balance WHERE household_id = $1 FOR UPDATE", db.household()); err != nil {
+		log.Printf("Error while lock balances: %v", err)
+		return IntegrityReport{}, err
+	}
+
+	report, err := db.verifyBalances(ctx, tx)
+	if err != nil {
+		return IntegrityReport{}, err
+	}
+	if report.Empty() {
+		return report, nil
+	}
+
+	balancesQuery := "SELECT * FROM balance WHERE household_id = $1 AND deleted_at IS NULL ORDER BY id"
+	operationsQuery := `
+	SELECT o.* FROM operations o
+	WHERE o.household_id = $1 AND o.id = ANY($2)
+	ORDER BY o.id
+	`
+	var operationIDs []int
+	for _, op := range report.Orphans {
+		operationIDs = append(operationIDs, op.OperationID)
+	}
+	for _, op := range report.Unlinked {
+		operationIDs = append(operationIDs, op.OperationID)
+	}
+
+	beforeBalances, err := snapshot(ctx, tx, balancesQuery, db.household())
+	if err != nil {
+		return IntegrityReport{}, err
+	}
+	beforeOperations, err := snapshot(ctx, tx, operationsQuery, db.household(), operationIDs)
+	if err != nil {
+		return IntegrityReport{}, err
+	}
+
+	repairQueries := []string{
+		// ссылки на несуществующие балансы сбрасываются
+		`UPDATE operations o SET balance_id = NULL
+		WHERE o.household_id = $1 AND o.balance_id IS NOT NULL
+		  AND NOT EXISTS (SELECT 1 FROM balance b WHERE b.id = o.balance_id AND b.household_id = o.household_id)`,
+		// операции закрытых периодов привязываются к своему балансу
+		`UPDATE operations o SET balance_id = b.id
+		FROM balance b
+		WHERE o.household_id = $1 AND o.deleted_at IS NULL
+		  AND b.household_id = $1 AND b.deleted_at IS NULL AND b.reopened_at IS NULL
+		  AND o.create_date BETWEEN ` + balancePeriodStart + ` AND b.create_date
+		  AND o.balance_id IS DISTINCT FROM b.id`,
+		// итоги балансов пересчитываются по привязанным операциям
+		`UPDATE balance b
+		SET debit = t.debit, credit = t.credit, amount = t.debit - t.credit
+		FROM (
+			SELECT b.id,
+				COALESCE(SUM(o.debit), 0) AS debit,
+				COALESCE(SUM(o.credit), 0) AS credit
+			FROM balance b
+			LEFT JOIN operations o ON o.balance_id = b.id AND o.deleted_at IS NULL
+			WHERE b.household_id = $1 AND b.deleted_at IS NULL
+			GROUP BY b.id
+		) t
+		WHERE b.id = t.id
+		  AND (` + moneyDiffers("b.debit", "t.debit") +
+			` OR ` + moneyDiffers("b.credit", "t.credit") +
+			` OR ` + moneyDiffers("b.amount", "t.debit - t.credit") + `)`,
+	}
+	for _, query := range repairQueries {
+		if _, err := tx.Exec(ctx, query, db.household()); err != nil {
+			log.Printf("Error while repair balances: %v", err)
+			return IntegrityReport{}, err
+		}
+	}
+
+	afterBalances, err := snapshot(ctx, tx, balancesQuery, db.household())
+	if err != nil {
+		return IntegrityReport{}, err
+	}
+	afterOperations, err := snapshot(ctx, tx, operationsQuery, db.household(), operationIDs)
+	if err != nil {
+		return IntegrityReport{}, err
+	}
+	if err := db.audit(ctx, tx, "repair", AuditBalance, beforeBalances, afterBalances); err != nil {
+		return IntegrityReport{}, err
+	}
+	if len(operationIDs) > 0 {
+		if err := db.audit(ctx, tx, "repair", AuditOperation, beforeOperations, afterOperations); err != nil {
+			return IntegrityReport{}, err
+		}
+	}
+
+	// после исправления нарушений остаться не должно
+	left, err := db.verifyBalances(ctx, tx)
+	if err != nil {
+		return IntegrityReport{}, err
+	}
+	if !left.Empty() {
+		log.Printf("Error balances still inconsistent after repair")
+		return IntegrityReport{}, ErrRepairFailed
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error commit transaction: %v\n", err)
+		return IntegrityReport{}, err
+	}
+	return report, nil
+}
+
+func (db *Database) verifyBalances(ctx context.Context, tx pgx.Tx) (IntegrityReport, error) {
+	var report IntegrityReport
+
+	mismatchQuery := `
+	SELECT b.id, b.create_date, b.debit, b.credit, b.amount,
+		COALESCE(SUM(o.debit), 0), COALESCE(SUM(o.credit), 0)
+	FROM balance b
+	LEFT JOIN operations o ON o.balance_id = b.id AND o.deleted_at IS NULL
+	WHERE b.household_id = $1 AND b.deleted_at IS NULL
+	GROUP BY b.id, b.create_date, b.debit, b.credit, b.amount
+	HAVING ` + moneyDiffers("b.debit", "COALESCE(SUM(o.debit), 0)") + `
+	    OR ` + moneyDiffers("b.credit", "COALESCE(SUM(o.credit), 0)") + `
+	    OR ` + moneyDiffers("b.amount", "COALESCE(SUM(o.debit), 0) - COALESCE(SUM(o.credit), 0)") + `
+	ORDER BY b.create_date
+	`
+	rows, err := tx.Query(ctx, mismatchQuery, db.household())
+	if err != nil {
+		log.Printf("Error while verify balances: %v", err)
+		return report, err
+	}
+	for rows.Next() {
+		var m BalanceMismatch
+		if err := rows.Scan(&m.BalanceID, &m.Date, &m.Debit, &m.Credit, &m.Amount, &m.ActualDebit, &m.ActualCredit); err != nil {
+			rows.Close()
+			log.Printf("Error while scan balance mismatch: %v", err)
+			return report, err
+		}
+		m.ActualAmount = m.ActualDebit - m.ActualCredit
+		report.Mismatches = append(report.Mismatches, m)
+	}
+	rows.Close()
+
+	orphanQuery := `
+	SELECT o.id, a.name, o.create_date, o.balance_id
+	FROM operations o
+	JOIN articles a ON a.id = o.article_id
+	WHERE o.household_id = $1 AND o.balance_id IS NOT NULL
+	  AND NOT EXISTS (SELECT 1 FROM balance b WHERE b.id = o.balance_id AND b.household_id = o.household_id)
+	ORDER BY o.create_date, o.id
+	`
+	if report.Orphans, err = integrityOperations(ctx, tx, orphanQuery, db.household()); err != nil {
+		return report, err
+	}
+
+	unlinkedQuery := `
+	SELECT o.id, a.name, o.create_date, b.id
+	FROM operations o
+	JOIN articles a ON a.id = o.article_id
+	JOIN balance b ON b.household_id = o.household_id AND b.deleted_at IS NULL AND b.reopened_at IS NULL
+	  AND o.create_date BETWEEN ` + balancePeriodStart + ` AND b.create_date
+	WHERE o.household_id = $1 AND o.deleted_at IS NULL
+	  AND o.balance_id IS DISTINCT FROM b.id
+	ORDER BY o.create_date, o.id
+	`
+	if report.Unlinked, err = integrityOperations(ctx, tx, unlinkedQuery, db.household()); err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
+
+func integrityOperations(ctx context.Context, tx pgx.Tx, query string, args ...any) ([]IntegrityOperation, error) {
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		log.Printf("Error while verify operations: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var operations []IntegrityOperation
+	for rows.Next() {
+		var op IntegrityOperation
+		if err := rows.Scan(&op.OperationID, &op.ArticleName, &op.Date, &op.BalanceID); err != nil {
+			log.Printf("Error while scan operation: %v", err)
+			return nil, err
+		}
+		operations = append(operations, op)
+	}
+	return operations, nil
+}
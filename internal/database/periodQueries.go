@@ -0,0 +1,178 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// начало периода, закрытого балансом b, конец периода - b.create_date
+const balancePeriodStart = "b.start_date"
+
+// проверить, что изменение не затрагивает закрытые периоды,
+// query возвращает даты затронутых операций и их домохозяйство
+func checkOpenPeriods(ctx context.Context, tx pgx.Tx, query string, args ...any) error {
+	checkQuery := `
+	SELECT b.id, b.create_date, d.op_date
+	FROM (` + query + `) d(op_date, household_id)
+	JOIN balance b ON b.household_id = d.household_id
+	WHERE b.deleted_at IS NULL AND b.reopened_at IS NULL
+	  AND d.op_date BETWEEN ` + balancePeriodStart + ` AND b.create_date
+	ORDER BY d.op_date
+	LIMIT 1
+	`
+
+	var closed ClosedPeriodError
+	err := tx.QueryRow(ctx, checkQuery, args...).Scan(&closed.BalanceID, &closed.PeriodEnd, &closed.Date)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		log.Printf("Error while check closed period: %v", err)
+		return err
+	}
+	log.Printf("Error change in closed period: %v", &closed)
+	return &closed
+}
+
+// проверить, что новый период не пересекается с балансами и примыкает к одному из них
+func (db *Database) checkPeriodPlacement(ctx context.Context, tx pgx.Tx, startDate, endDate string) error {
+	if _, err := tx.Exec(ctx, "SELECT id FROM balance WHERE household_id = $1 AND deleted_at IS NULL FOR UPDATE", db.household()); err != nil {
+		log.Printf("Error while lock balances: %v", err)
+		return err
+	}
+
+	query := `
+	SELECT
+		EXISTS (SELECT 1 FROM balance b WHERE b.household_id = $1 AND b.deleted_at IS NULL
+			AND daterange(b.start_date, b.create_date, '[]') && daterange($2::date, $3::date, '[]')),
+		EXISTS (SELECT 1 FROM balance b WHERE b.household_id = $1 AND b.deleted_at IS NULL),
+		EXISTS (SELECT 1 FROM balance b WHERE b.household_id = $1 AND b.deleted_at IS NULL
+			AND (b.create_date = $2::date - 1 OR b.start_date = $3::date + 1))
+	`
+
+	var overlap, exists, adjacent bool
+	if err := tx.QueryRow(ctx, query, db.household(), startDate, endDate).Scan(&overlap, &exists, &adjacent); err != nil {
+		log.Printf("Error while check period placement: %v", err)
+		return err
+	}
+	if overlap {
+		log.Printf("Error period %s - %s overlaps existing balance", startDate, endDate)
+		return ErrPeriodOverlap
+	}
+	if exists && !adjacent {
+		log.Printf("Error period %s - %s leaves a gap between balances", startDate, endDate)
+		return ErrPeriodGap
+	}
+	return nil
+}
+
+// открыть закрытый период повторно для исправлений
+func (db *Database) ReopenPeriod(ctx context.Context, balanceID int, reason string) error {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	selectQuery := "SELECT * FROM balance WHERE id = $1 AND household_id = $2 AND deleted_at IS NULL"
+	before, err := snapshot(ctx, tx, selectQuery, balanceID, db.household())
+	if err != nil {
+		return err
+	}
+
+	query := `
+	UPDATE balance SET reopened_at = now(), reopened_by = $3, reopen_reason = $4
+	WHERE id = $1 AND household_id = $2 AND deleted_at IS NULL AND reopened_at IS NULL
+	`
+	commandTag, err := tx.Exec(ctx, query, balanceID, db.household(), db.user(), reason)
+	if err != nil {
+		log.Printf("Error while reopen period: %v", err)
+		return err
+	}
+	if commandTag.RowsAffected() == 0 {
+		log.Printf("Error no closed balance found with id: %d", balanceID)
+		return ErrEmptyRow
+	}
+
+	after, err := snapshot(ctx, tx, selectQuery, balanceID, db.household())
+	if err != nil {
+		return err
+	}
+	if err := db.audit(ctx, tx, "reopen", AuditBalance, before, after); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error commit transaction: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+// закрыть повторно открытый период: операции периода снова привязываются к балансу,
+// итоги баланса пересчитываются с учётом исправлений
+func (db *Database) ClosePeriod(ctx context.Context, balanceID int) error {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	selectQuery := "SELECT * FROM balance WHERE id = $1 AND household_id = $2 AND deleted_at IS NULL"
+	before, err := snapshot(ctx, tx, selectQuery, balanceID, db.household())
+	if err != nil {
+		return err
+	}
+
+	linkQuery := `
+	UPDATE operations o SET balance_id = b.id
+	FROM balance b
+	WHERE b.id = $1 AND b.household_id = $2 AND b.deleted_at IS NULL AND b.reopened_at IS NOT NULL
+	  AND o.household_id = b.household_id AND o.deleted_at IS NULL
+	  AND o.create_date BETWEEN ` + balancePeriodStart + ` AND b.create_date
+	`
+	if _, err := tx.Exec(ctx, linkQuery, balanceID, db.household()); err != nil {
+		log.Printf("Error while link period operations: %v", err)
+		return err
+	}
+
+	closeQuery := `
+	UPDATE balance b
+	SET debit = t.debit, credit = t.credit, amount = t.debit - t.credit,
+		reopened_at = NULL, reopened_by = NULL, reopen_reason = NULL
+	FROM (
+		SELECT COALESCE(SUM(debit), 0) AS debit, COALESCE(SUM(credit), 0) AS credit
+		FROM operations
+		WHERE balance_id = $1 AND deleted_at IS NULL
+	) t
+	WHERE b.id = $1 AND b.household_id = $2 AND b.deleted_at IS NULL AND b.reopened_at IS NOT NULL
+	`
+	commandTag, err := tx.Exec(ctx, closeQuery, balanceID, db.household())
+	if err != nil {
+		log.Printf("Error while close period: %v", err)
+		return err
+	}
+	if commandTag.RowsAffected() == 0 {
+		log.Printf("Error no reopened balance found with id: %d", balanceID)
+		return ErrPeriodNotReopened
+	}
+
+	after, err := snapshot(ctx, tx, selectQuery, balanceID, db.household())
+	if err != nil {
+		return err
+	}
+	if err := db.audit(ctx, tx, "close", AuditBalance, before, after); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error commit transaction: %v\n", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package database
+
+import (
+	"time"
+)
+
+// типы периодов баланса
+const (
+	PeriodWeek    = "week"
+	PeriodMonth   = "month"
+	PeriodQuarter = "quarter"
+	PeriodYear    = "year"
+	PeriodCustom  = "custom"
+)
+
+var PeriodTypes = []string{PeriodWeek, PeriodMonth, PeriodQuarter, PeriodYear, PeriodCustom}
+
+// PeriodBounds возвращает период заданного типа, содержащий дату.
+// Для произвольного периода возвращается сама дата.
+func PeriodBounds(periodType string, date time.Time) (time.Time, time.Time) {
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch periodType {
+	case PeriodWeek:
+		// неделя начинается с понедельника
+		offset := (int(date.Weekday()) + 6) % 7
+		start := date.AddDate(0, 0, -offset)
+		return start, start.AddDate(0, 0, 6)
+	case PeriodMonth:
+		start := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, -1)
+	case PeriodQuarter:
+		month := time.Month((int(date.Month())-1)/3*3 + 1)
+		start := time.Date(date.Year(), month, 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 3, -1)
+	case PeriodYear:
+		start := time.Date(date.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(1, 0, -1)
+	default:
+		return date, date
+	}
+}
+
+// ValidatePeriod проверяет, что начало и конец соответствуют типу периода
+func ValidatePeriod(periodType string, start, end time.Time) error {
+	if end.Before(start) {
+		return ErrPeriodInvalid
+	}
+
+	switch periodType {
+	case PeriodCustom:
+		return nil
+	case PeriodWeek, PeriodMonth, PeriodQuarter, PeriodYear:
+		wantStart, wantEnd := PeriodBounds(periodType, start)
+		if !sameDay(start, wantStart) || !sameDay(end, wantEnd) {
+			return ErrPeriodInvalid
+		}
+		return nil
+	default:
+		return ErrPeriodInvalid
+	}
+}
+
+func sameDay(a, b time.Time) bool {
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay()
+}
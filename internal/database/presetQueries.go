@@ -0,0 +1,80 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+)
+
+// сохранённые наборы параметров отчёта текущего пользователя
+func (db *Database) GetReportPresets(ctx context.Context, reportID string) ([]ReportPreset, error) {
+	query := `
+	SELECT id, report_id, name, period, params FROM report_presets
+	WHERE household_id = $1 AND username = $2 AND report_id = $3
+	ORDER BY name
+	`
+
+	rows, err := db.pool.Query(ctx, query, db.household(), db.user(), reportID)
+	if err != nil {
+		log.Printf("Error while get report presets: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var presets []ReportPreset
+	for rows.Next() {
+		var preset ReportPreset
+		var params []byte
+		if err := rows.Scan(&preset.ID, &preset.ReportID, &preset.Name, &preset.Period, &params); err != nil {
+			log.Printf("Error while scan report preset: %v", err)
+			return nil, err
+		}
+		if err := json.Unmarshal(params, &preset.Values); err != nil {
+			log.Printf("Error while decode report preset %d: %v", preset.ID, err)
+			return nil, err
+		}
+		presets = append(presets, preset)
+	}
+	return presets, nil
+}
+
+// сохранить набор параметров под именем, одноимённый набор отчёта перезаписывается
+func (db *Database) SaveReportPreset(ctx context.Context, preset ReportPreset) error {
+	preset.Name = strings.TrimSpace(preset.Name)
+	if preset.Name == "" || preset.ReportID == "" {
+		return ErrPresetSpec
+	}
+	if preset.Values == nil {
+		preset.Values = map[string][]string{}
+	}
+	data, err := json.Marshal(preset.Values)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO report_presets (household_id, username, report_id, name, period, params)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	ON CONFLICT (household_id, username, report_id, name)
+	DO UPDATE SET period = EXCLUDED.period, params = EXCLUDED.params
+	`
+	if _, err := db.pool.Exec(ctx, query, db.household(), db.user(), preset.ReportID, preset.Name, preset.Period, data); err != nil {
+		log.Printf("Error while save report preset: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (db *Database) DeleteReportPreset(ctx context.Context, id int) error {
+	commandTag, err := db.pool.Exec(ctx, "DELETE FROM report_presets WHERE id = $1 AND household_id = $2 AND username = $3",
+		id, db.household(), db.user())
+	if err != nil {
+		log.Printf("Error while delete report preset: %v", err)
+		return err
+	}
+	if commandTag.RowsAffected() == 0 {
+		return ErrEmptyRow
+	}
+	return nil
+}
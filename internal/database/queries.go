@@ -3,15 +3,17 @@ package database
 import (
 	"context"
 	"database/sql"
-	"fmt"
+	"errors"
 	"log"
+	"time"
 
 	"github.com/EmptyInsid/db_gui/internal/models"
+	"github.com/jackc/pgx/v5"
 )
 
 // выбрать все статьи
 func (db *Database) GetAllArticles(ctx context.Context) ([]models.Article, error) {
-	rows, err := db.pool.Query(ctx, "SELECT * FROM articles ORDER BY articles.id")
+	rows, err := db.pool.Query(ctx, "SELECT id, name, tag FROM articles WHERE household_id = $1 AND deleted_at IS NULL ORDER BY articles.id", db.household())
 	if err != nil {
 		log.Printf("Error while get articles: %v", err)
 		return nil, err
@@ -21,7 +23,7 @@ func (db *Database) GetAllArticles(ctx context.Context) ([]models.Article, error
 	var articles []models.Article
 	for rows.Next() {
 		var article models.Article
-		if err := rows.Scan(&article.ID, &article.Name); err != nil {
+		if err := rows.Scan(&article.ID, &article.Name, &article.Tag); err != nil {
 			log.Printf("Error while get articles: %v", err)
 			return nil, err
 		}
@@ -36,11 +38,11 @@ func (db *Database) GetUnusedArticles(ctx context.Context, startData, finishData
 
 	query := `
     SELECT DISTINCT id, name FROM articles 
-    WHERE id NOT IN (SELECT DISTINCT operations.article_id FROM operations 
-    WHERE $1 <= create_date AND create_date < $2)
+    WHERE household_id = $3 AND deleted_at IS NULL AND id NOT IN (SELECT DISTINCT operations.article_id FROM operations 
+    WHERE $1 <= create_date AND create_date < $2 AND operations.household_id = $3 AND operations.deleted_at IS NULL)
 	ORDER BY articles.id`
 
-	rows, err := db.pool.Query(ctx, query, startData, finishData)
+	rows, err := db.pool.Query(ctx, query, startData, finishData, db.household())
 	if err != nil {
 		log.Printf("Error while get unused articles: %v", err)
 		return nil, err
@@ -70,7 +72,7 @@ func (db *Database) AddArticle(ctx context.Context, name string) error {
 	}
 	defer tx.Rollback(context.Background())
 
-	commandTag, err := tx.Exec(ctx, "INSERT INTO articles(name) VALUES ($1)", name)
+	commandTag, err := tx.Exec(ctx, "INSERT INTO articles(name, household_id) VALUES ($1, $2)", name, db.household())
 	if err != nil {
 		log.Printf("Error while insert article: %v\n", err)
 		return err
@@ -80,6 +82,14 @@ func (db *Database) AddArticle(ctx context.Context, name string) error {
 		return ErrEmptyRow
 	}
 
+	after, err := snapshot(ctx, tx, "SELECT * FROM articles WHERE name = $1 AND household_id = $2 AND deleted_at IS NULL", name, db.household())
+	if err != nil {
+		return err
+	}
+	if err := db.audit(ctx, tx, "add", AuditArticle, nil, after); err != nil {
+		return err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		log.Printf("Error commit transaction: %v\n", err)
 		return err
@@ -89,9 +99,22 @@ func (db *Database) AddArticle(ctx context.Context, name string) error {
 
 // В рамках транзакции поменять заданную статью во всех операциях на другую и удалить ее.
 func (db *Database) UpdateArticle(ctx context.Context, oldName, newName string) error {
-	query := `UPDATE articles SET name = $1 WHERE name = $2`
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	selectQuery := "SELECT * FROM articles WHERE name = $1 AND household_id = $2 AND deleted_at IS NULL"
+	before, err := snapshot(ctx, tx, selectQuery, oldName, db.household())
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE articles SET name = $1 WHERE name = $2 AND household_id = $3 AND deleted_at IS NULL`
 
-	commandTag, err := db.pool.Exec(ctx, query, newName, oldName)
+	commandTag, err := tx.Exec(ctx, query, newName, oldName, db.household())
 	if err != nil {
 		log.Printf("Error failed to update article name: %v", err)
 		return err
@@ -102,6 +125,60 @@ func (db *Database) UpdateArticle(ctx context.Context, oldName, newName string)
 		return ErrEmptyRow
 	}
 
+	after, err := snapshot(ctx, tx, selectQuery, newName, db.household())
+	if err != nil {
+		return err
+	}
+	if err := db.audit(ctx, tx, "update", AuditArticle, before, after); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error commit transaction: %v\n", err)
+		return err
+	}
+
+	return nil
+}
+
+// Задать метку статьи, пустая метка снимает её
+func (db *Database) SetArticleTag(ctx context.Context, articleName, tag string) error {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	selectQuery := "SELECT * FROM articles WHERE name = $1 AND household_id = $2 AND deleted_at IS NULL"
+	before, err := snapshot(ctx, tx, selectQuery, articleName, db.household())
+	if err != nil {
+		return err
+	}
+
+	commandTag, err := tx.Exec(ctx, `UPDATE articles SET tag = $1 WHERE name = $2 AND household_id = $3 AND deleted_at IS NULL`,
+		tag, articleName, db.household())
+	if err != nil {
+		log.Printf("Error failed to update article tag: %v", err)
+		return err
+	}
+	if commandTag.RowsAffected() == 0 {
+		log.Printf("Error no articles found with name: %s", articleName)
+		return ErrEmptyRow
+	}
+
+	after, err := snapshot(ctx, tx, selectQuery, articleName, db.household())
+	if err != nil {
+		return err
+	}
+	if err := db.audit(ctx, tx, "update", AuditArticle, before, after); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error commit transaction: %v\n", err)
+		return err
+	}
 	return nil
 }
 
@@ -114,9 +191,26 @@ func (db *Database) DeleteArticle(ctx context.Context, articleName string) error
 	}
 	defer tx.Rollback(ctx)
 
-	// Delete the article
-	deleteArticleQuery := `DELETE FROM articles WHERE name = $1;`
-	commandTag, err := tx.Exec(ctx, deleteArticleQuery, articleName)
+	// статья сохраняется в журнале вместе с операциями, удаляемыми вместе с ней
+	before, err := snapshot(ctx, tx, `
+	SELECT a.*, (SELECT jsonb_agg(to_jsonb(o)) FROM operations o WHERE o.article_id = a.id AND o.deleted_at IS NULL) AS operations
+	FROM articles a WHERE a.name = $1 AND a.household_id = $2 AND a.deleted_at IS NULL`, articleName, db.household())
+	if err != nil {
+		return err
+	}
+
+	operationDates := `
+	SELECT o.create_date, o.household_id FROM operations o
+	JOIN articles a ON a.id = o.article_id
+	WHERE a.name = $1 AND a.household_id = $2 AND a.deleted_at IS NULL AND o.deleted_at IS NULL
+	`
+	if err := checkOpenPeriods(ctx, tx, operationDates, articleName, db.household()); err != nil {
+		return err
+	}
+
+	// Статья и её операции помечаются удалёнными одной меткой времени, чтобы восстанавливаться вместе
+	deleteArticleQuery := `UPDATE articles SET deleted_at = now() WHERE name = $1 AND household_id = $2 AND deleted_at IS NULL;`
+	commandTag, err := tx.Exec(ctx, deleteArticleQuery, articleName, db.household())
 	if err != nil {
 		log.Printf("Error deleting article %v", err)
 		return err
@@ -126,6 +220,21 @@ func (db *Database) DeleteArticle(ctx context.Context, articleName string) error
 		return ErrEmptyRow
 	}
 
+	deleteOperationsQuery := `
+	UPDATE operations o SET deleted_at = a.deleted_at
+	FROM articles a
+	WHERE o.article_id = a.id AND o.deleted_at IS NULL
+	  AND a.name = $1 AND a.household_id = $2 AND a.deleted_at = now();
+	`
+	if _, err := tx.Exec(ctx, deleteOperationsQuery, articleName, db.household()); err != nil {
+		log.Printf("Error deleting article operations %v", err)
+		return err
+	}
+
+	if err := db.audit(ctx, tx, "delete", AuditArticle, before, nil); err != nil {
+		return err
+	}
+
 	if err = tx.Commit(ctx); err != nil {
 		log.Printf("Error commit transaction: %v\n", err)
 		return err
@@ -136,7 +245,16 @@ func (db *Database) DeleteArticle(ctx context.Context, articleName string) error
 
 // получить все балансы
 func (db *Database) GetAllBalances(ctx context.Context) ([]models.Balance, error) {
-	rows, err := db.pool.Query(ctx, "SELECT * FROM balance ORDER BY balance.id")
+	query := `
+	SELECT b.id, b.create_date, b.debit, b.credit, b.amount, b.start_date, b.period_type,
+		` + moneyOnHandExpr("$1", "b.create_date") + `,
+		b.reopened_at, COALESCE(b.reopened_by, '')
+	FROM balance b
+	WHERE b.household_id = $1 AND b.deleted_at IS NULL
+	ORDER BY b.start_date
+	`
+
+	rows, err := db.pool.Query(ctx, query, db.household())
 	if err != nil {
 		log.Printf("Error while get balances: %v", err)
 		return nil, err
@@ -152,6 +270,11 @@ func (db *Database) GetAllBalances(ctx context.Context) ([]models.Balance, error
 			&balance.Debit,
 			&balance.Credit,
 			&balance.Amount,
+			&balance.StartDate,
+			&balance.PeriodType,
+			&balance.OnHand,
+			&balance.ReopenedAt,
+			&balance.ReopenedBy,
 		); err != nil {
 			log.Printf("Error while get balances: %v", err)
 			return nil, err
@@ -169,11 +292,12 @@ func (db *Database) GetBalanceCountByArticleName(ctx context.Context, articleNam
 	FROM balance b
 	JOIN operations o ON b.id = o.balance_id
 	JOIN articles a ON o.article_id = a.id
-	WHERE a.name = $1;
+	WHERE a.name = $1 AND a.household_id = $2
+	  AND a.deleted_at IS NULL AND o.deleted_at IS NULL AND b.deleted_at IS NULL;
 	`
 
 	var balanceCount int
-	if err := db.pool.QueryRow(ctx, query, articleName).Scan(&balanceCount); err != nil {
+	if err := db.pool.QueryRow(ctx, query, articleName, db.household()).Scan(&balanceCount); err != nil {
 		log.Printf("Error fetching balance count: %v", err)
 		return 0, err
 	}
@@ -188,12 +312,14 @@ func (db *Database) GetTotalCreditByArticleAndPeriod(ctx context.Context, articl
 	JOIN articles a ON o.article_id = a.id
 	JOIN balance b ON o.balance_id = b.id
 	WHERE a.name = $1
+	  AND a.household_id = $4
+	  AND a.deleted_at IS NULL AND o.deleted_at IS NULL AND b.deleted_at IS NULL
 	  AND b.create_date BETWEEN $2 AND $3;
 	`
 
 	var profit *float64
 
-	if err := db.pool.QueryRow(ctx, query, articleName, startDate, finishDate).Scan(&profit); err != nil {
+	if err := db.pool.QueryRow(ctx, query, articleName, startDate, finishDate, db.household()).Scan(&profit); err != nil {
 		log.Printf("Error fetching total credit: %v", err)
 		return 0, err
 	}
@@ -205,7 +331,22 @@ func (db *Database) GetTotalCreditByArticleAndPeriod(ctx context.Context, articl
 }
 
 // Сформировать баланс. Если сумма прибыли меньше некоторой суммы – транзакцию откатить.
-func (db *Database) CreateBalanceIfProfitable(ctx context.Context, startDate, endDate string, minProfit float64) error {
+func (db *Database) CreateBalanceIfProfitable(ctx context.Context, periodType, startDate, endDate string, minProfit float64) error {
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		log.Printf("Error failed to parse period start: %v", err)
+		return ErrPeriodInvalid
+	}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		log.Printf("Error failed to parse period end: %v", err)
+		return ErrPeriodInvalid
+	}
+	if err := ValidatePeriod(periodType, start, end); err != nil {
+		log.Printf("Error period %s %s - %s does not match its type", periodType, startDate, endDate)
+		return err
+	}
+
 	tx, err := db.pool.Begin(ctx)
 	if err != nil {
 		log.Printf("Error failed to begin transaction: %v", err)
@@ -214,15 +355,20 @@ func (db *Database) CreateBalanceIfProfitable(ctx context.Context, startDate, en
 
 	defer tx.Rollback(ctx)
 
+	// периоды не пересекаются и идут без разрывов
+	if err := db.checkPeriodPlacement(ctx, tx, startDate, endDate); err != nil {
+		return err
+	}
+
 	var totalDebit, totalCredit float64
 
 	// Calculate debit and credit for the given period
 	query := `
 	SELECT COALESCE(SUM(debit), 0), COALESCE(SUM(credit), 0)
 	FROM operations
-	WHERE create_date BETWEEN $1 AND $2
+	WHERE create_date BETWEEN $1 AND $2 AND household_id = $3 AND deleted_at IS NULL
 	`
-	err = tx.QueryRow(ctx, query, startDate, endDate).Scan(&totalDebit, &totalCredit)
+	err = tx.QueryRow(ctx, query, startDate, endDate, db.household()).Scan(&totalDebit, &totalCredit)
 	if err != nil {
 		log.Printf("Error failed to calculate debit/credit: %v", err)
 		return err
@@ -236,11 +382,11 @@ func (db *Database) CreateBalanceIfProfitable(ctx context.Context, startDate, en
 
 	// Insert balance
 	insertQuery := `
-	INSERT INTO balance (create_date, debit, credit, amount)
-	VALUES ($1, $2, $3, $4) RETURNING id
+	INSERT INTO balance (create_date, debit, credit, amount, household_id, start_date, period_type)
+	VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id
 	`
 	var newBalanceID int
-	err = tx.QueryRow(ctx, insertQuery, endDate, totalDebit, totalCredit, profit).Scan(&newBalanceID)
+	err = tx.QueryRow(ctx, insertQuery, endDate, totalDebit, totalCredit, profit, db.household(), startDate, periodType).Scan(&newBalanceID)
 	if err != nil {
 		log.Printf("Error failed to insert balance: %v", err)
 		return err
@@ -250,14 +396,22 @@ func (db *Database) CreateBalanceIfProfitable(ctx context.Context, startDate, en
 	updateQuery := `
 	UPDATE operations
 	SET balance_id = $1
-	WHERE create_date BETWEEN $2 AND $3
+	WHERE create_date BETWEEN $2 AND $3 AND household_id = $4 AND deleted_at IS NULL
 	`
-	_, err = tx.Exec(ctx, updateQuery, newBalanceID, startDate, endDate)
+	_, err = tx.Exec(ctx, updateQuery, newBalanceID, startDate, endDate, db.household())
 	if err != nil {
 		log.Printf("Error failed to update operations: %v", err)
 		return err
 	}
 
+	after, err := snapshot(ctx, tx, "SELECT * FROM balance WHERE id = $1", newBalanceID)
+	if err != nil {
+		return err
+	}
+	if err := db.audit(ctx, tx, "create", AuditBalance, nil, after); err != nil {
+		return err
+	}
+
 	// Commit transaction
 	err = tx.Commit(ctx)
 	if err != nil {
@@ -277,15 +431,27 @@ func (db *Database) DeleteMostUnprofitableBalance(ctx context.Context) error {
 	}
 	defer tx.Rollback(ctx)
 
+	selectQuery := `
+	SELECT * FROM balance
+	WHERE household_id = $1 AND deleted_at IS NULL
+	  AND amount = (SELECT MIN(amount) FROM balance WHERE household_id = $1 AND deleted_at IS NULL)
+	LIMIT 1
+	`
+	before, err := snapshot(ctx, tx, selectQuery, db.household())
+	if err != nil {
+		return err
+	}
+
 	deleteBalanceQuery := `
-	DELETE FROM balance
+	UPDATE balance SET deleted_at = now()
 	WHERE id = (
 	    SELECT id FROM balance
-	    WHERE amount = (SELECT MIN(amount) FROM balance)
+	    WHERE household_id = $1 AND deleted_at IS NULL
+	      AND amount = (SELECT MIN(amount) FROM balance WHERE household_id = $1 AND deleted_at IS NULL)
 	    LIMIT 1
 	);
 	`
-	commandTag, err := tx.Exec(ctx, deleteBalanceQuery)
+	commandTag, err := tx.Exec(ctx, deleteBalanceQuery, db.household())
 	if err != nil {
 		log.Printf("Error deleting the most unprofitable balance: %v", err)
 		return err
@@ -295,6 +461,10 @@ func (db *Database) DeleteMostUnprofitableBalance(ctx context.Context) error {
 		return ErrEmptyRow
 	}
 
+	if err := db.audit(ctx, tx, "delete", AuditBalance, before, nil); err != nil {
+		return err
+	}
+
 	if err = tx.Commit(ctx); err != nil {
 		log.Printf("Error commit transaction: %v\n", err)
 		return err
@@ -305,7 +475,15 @@ func (db *Database) DeleteMostUnprofitableBalance(ctx context.Context) error {
 
 // получить все операции
 func (db *Database) GetAllOperations(ctx context.Context) ([]models.Operation, error) {
-	rows, err := db.pool.Query(ctx, "SELECT * FROM operations ORDER BY operations.id")
+	query := `
+	SELECT o.id, o.article_id, o.debit, o.credit, o.create_date, b.id
+	FROM operations o
+	LEFT JOIN balance b ON b.id = o.balance_id AND b.deleted_at IS NULL
+	WHERE o.household_id = $1 AND o.deleted_at IS NULL
+	ORDER BY o.id
+	`
+
+	rows, err := db.pool.Query(ctx, query, db.household())
 	if err != nil {
 		log.Printf("Error while get operations: %v", err)
 		return nil, err
@@ -342,18 +520,24 @@ func (db *Database) GetArticlesWithOperations(ctx context.Context) ([]ArticleWit
 		operations.debit,
 		operations.credit,
 		operations.create_date,
-		operations.balance_id
+		balance.id
 	FROM 
 		articles
 	RIGHT JOIN 
 		operations 
 	ON 
 		articles.id = operations.article_id
+	LEFT JOIN 
+		balance 
+	ON 
+		balance.id = operations.balance_id AND balance.deleted_at IS NULL
+	WHERE 
+		operations.household_id = $1 AND operations.deleted_at IS NULL
 	ORDER BY 
 		operations.create_date;
 	`
 
-	rows, err := db.pool.Query(ctx, query)
+	rows, err := db.pool.Query(ctx, query, db.household())
 	if err != nil {
 		log.Printf("Error fetching articles with operations: %v", err)
 		return nil, err
@@ -382,6 +566,55 @@ func (db *Database) GetArticlesWithOperations(ctx context.Context) ([]ArticleWit
 	return results, nil
 }
 
+// получить операцию по идентификатору вместе с названием статьи
+func (db *Database) GetOperation(ctx context.Context, id int) (ArticleWithOperations, error) {
+	query := `
+	SELECT a.id, a.name, o.id, o.debit, o.credit, o.create_date, b.id
+	FROM operations o
+	JOIN articles a ON a.id = o.article_id
+	LEFT JOIN balance b ON b.id = o.balance_id AND b.deleted_at IS NULL
+	WHERE o.id = $1 AND o.household_id = $2 AND o.deleted_at IS NULL
+	`
+
+	var record ArticleWithOperations
+	err := db.pool.QueryRow(ctx, query, id, db.household()).Scan(
+		&record.ArticleID,
+		&record.ArticleName,
+		&record.OperationID,
+		&record.Debit,
+		&record.Credit,
+		&record.CreateDate,
+		&record.BalanceID,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		log.Printf("Error no operation found with id: %d", id)
+		return record, ErrEmptyRow
+	}
+	if err != nil {
+		log.Printf("Error while get operation: %v", err)
+		return record, err
+	}
+	return record, nil
+}
+
+// Проверить, входит ли дата в период, закрытый балансом и не открытый повторно
+func (db *Database) IsPeriodClosed(ctx context.Context, date string) (bool, error) {
+	query := `
+	SELECT EXISTS (
+		SELECT 1 FROM balance b
+		WHERE b.household_id = $2 AND b.deleted_at IS NULL AND b.reopened_at IS NULL
+		  AND $1::date BETWEEN ` + balancePeriodStart + ` AND b.create_date
+	)
+	`
+
+	var closed bool
+	if err := db.pool.QueryRow(ctx, query, date, db.household()).Scan(&closed); err != nil {
+		log.Printf("Error while check closed period: %v", err)
+		return false, err
+	}
+	return closed, nil
+}
+
 // Посчитать прибыль за заданную дату
 func (db *Database) GetProfitByDate(ctx context.Context, articleName, startDate, endDate string) (float64, error) {
 
@@ -391,12 +624,14 @@ func (db *Database) GetProfitByDate(ctx context.Context, articleName, startDate,
 	JOIN articles a ON o.article_id = a.id
 	JOIN balance b ON o.balance_id = b.id
 	WHERE a.name = $1
+	  AND a.household_id = $4
+	  AND a.deleted_at IS NULL AND o.deleted_at IS NULL AND b.deleted_at IS NULL
 	  AND b.create_date BETWEEN $2 AND $3;
 	`
 
 	var totalProfit *float64
 
-	if err := db.pool.QueryRow(context.Background(), query, articleName, startDate, endDate).Scan(&totalProfit); err != nil {
+	if err := db.pool.QueryRow(context.Background(), query, articleName, startDate, endDate, db.household()).Scan(&totalProfit); err != nil {
 		log.Printf("Error while get operations: %v", err)
 		return 0, err
 	}
@@ -407,71 +642,199 @@ func (db *Database) GetProfitByDate(ctx context.Context, articleName, startDate,
 	}
 }
 
-// Добавить операцию в рамках статьи
-func (db *Database) AddOperation(ctx context.Context, articleName string, debit float64, credit float64, date string) error {
+// Добавить операцию в рамках статьи, возвращает идентификатор новой операции
+func (db *Database) AddOperation(ctx context.Context, articleName string, debit float64, credit float64, date string) (int, error) {
 	tx, err := db.pool.Begin(ctx)
 	if err != nil {
 		log.Printf("Error starting transaction: %v", err)
-		return err
+		return 0, err
 	}
 	defer tx.Rollback(context.Background())
+
+	if err := checkOpenPeriods(ctx, tx, "SELECT $1::date, $2::int", date, db.household()); err != nil {
+		return 0, err
+	}
+
 	// Вставить операцию
 	queryAddOp := `
-	INSERT INTO operations(article_id, debit, credit, create_date, balance_id) VALUES
-	((SELECT id FROM articles WHERE articles.name = $1), $2, $3, $4, NULL)
+	INSERT INTO operations(article_id, debit, credit, create_date, balance_id, household_id) VALUES
+	((SELECT id FROM articles WHERE articles.name = $1 AND articles.household_id = $5 AND articles.deleted_at IS NULL), $2, $3, $4, NULL, $5)
+	RETURNING id
 	`
 
-	if _, err := db.pool.Exec(ctx, queryAddOp, articleName, debit, credit, date); err != nil {
+	var id int
+	if err := tx.QueryRow(ctx, queryAddOp, articleName, debit, credit, date, db.household()).Scan(&id); err != nil {
 		log.Printf("Error insert operation: %v\n", err)
-		return err
+		return 0, err
+	}
+
+	after, err := snapshot(ctx, tx, "SELECT * FROM operations WHERE id = $1", id)
+	if err != nil {
+		return 0, err
 	}
+	if err := db.audit(ctx, tx, "add", AuditOperation, nil, after); err != nil {
+		return 0, err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		log.Printf("Error commit transaction: %v\n", err)
-		return err
+		return 0, err
 	}
 
-	return nil
+	return id, nil
 }
 
 // Увеличить сумму расхода операций для статьи, заданной по наименованию
-func (db *Database) IncreaseExpensesForArticle(ctx context.Context, articleName string, increaseAmount float64) error {
+func (db *Database) IncreaseExpensesForArticle(ctx context.Context, articleName string, increaseAmount float64) ([]CreditChange, error) {
 	tx, err := db.pool.Begin(ctx)
 	if err != nil {
 		log.Printf("Error starting transaction: %v", err)
-		return err
+		return nil, err
 	}
 	defer tx.Rollback(context.Background())
 
-	// Update operations for the given article
+	selectQuery := `
+	SELECT o.* FROM operations o
+	JOIN articles a ON o.article_id = a.id
+	WHERE a.name = $1 AND a.household_id = $2
+	  AND a.deleted_at IS NULL AND o.deleted_at IS NULL
+	ORDER BY o.id
+	`
+	before, err := snapshot(ctx, tx, selectQuery, articleName, db.household())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkOpenPeriods(ctx, tx, "SELECT create_date, household_id FROM ("+selectQuery+") o", articleName, db.household()); err != nil {
+		return nil, err
+	}
+
+	// Update operations for the given article, old credits are returned for undo
 	updateOperationsQuery := `
 	UPDATE operations o
-	SET credit = credit + $1
-	FROM articles a
-	WHERE o.article_id = a.id
-	AND a.name = $2;
+	SET credit = o.credit + $1
+	FROM (
+		SELECT p.id, p.credit FROM operations p
+		JOIN articles a ON p.article_id = a.id
+		WHERE a.name = $2 AND a.household_id = $3
+		  AND a.deleted_at IS NULL AND p.deleted_at IS NULL
+	) prev
+	WHERE o.id = prev.id
+	RETURNING o.id, o.create_date, prev.credit, o.credit
 	`
-	commandTag, err := tx.Exec(ctx, updateOperationsQuery, increaseAmount, articleName)
+	rows, err := tx.Query(ctx, updateOperationsQuery, increaseAmount, articleName, db.household())
 	if err != nil {
 		log.Printf("Error upgrading operations: %v", err)
-		return err
+		return nil, err
+	}
+	var changes []CreditChange
+	for rows.Next() {
+		var change CreditChange
+		if err := rows.Scan(&change.OperationID, &change.CreateDate, &change.Before, &change.After); err != nil {
+			rows.Close()
+			log.Printf("Error upgrading operations: %v", err)
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		log.Printf("Error upgrading operations: %v", err)
+		return nil, err
 	}
 
-	if commandTag.RowsAffected() == 0 {
+	if len(changes) == 0 {
 		log.Printf("Error nothing upd")
-		return ErrEmptyRow
+		return nil, ErrEmptyRow
+	}
+
+	after, err := snapshot(ctx, tx, selectQuery, articleName, db.household())
+	if err != nil {
+		return nil, err
+	}
+	if err := db.audit(ctx, tx, "increase", AuditOperation, before, after); err != nil {
+		return nil, err
 	}
 
 	if err = tx.Commit(ctx); err != nil {
 		log.Printf("Error commit transaction: %v\n", err)
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+// установить расход операциям по идентификаторам, все или ни одной
+func (db *Database) SetOperationCredits(ctx context.Context, credits map[int]float64) error {
+	ids := make([]int, 0, len(credits))
+	values := make([]float64, 0, len(credits))
+	for id, credit := range credits {
+		ids = append(ids, id)
+		values = append(values, credit)
+	}
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback(context.Background())
+
+	selectQuery := "SELECT * FROM operations WHERE id = ANY($1) AND household_id = $2 AND deleted_at IS NULL ORDER BY id"
+	before, err := snapshot(ctx, tx, selectQuery, ids, db.household())
+	if err != nil {
+		return err
+	}
+
+	if err := checkOpenPeriods(ctx, tx, "SELECT create_date, household_id FROM ("+selectQuery+") o", ids, db.household()); err != nil {
+		return err
+	}
+
+	updateQuery := `
+	UPDATE operations o
+	SET credit = c.credit
+	FROM unnest($1::integer[], $2::double precision[]) AS c(id, credit)
+	WHERE o.id = c.id AND o.household_id = $3 AND o.deleted_at IS NULL
+	`
+	commandTag, err := tx.Exec(ctx, updateQuery, ids, values, db.household())
+	if err != nil {
+		log.Printf("Error while set operation credits: %v", err)
+		return err
+	}
+	// операция могла быть удалена после повышения расходов
+	if commandTag.RowsAffected() != int64(len(ids)) {
+		log.Printf("Error updated %d of %d operations", commandTag.RowsAffected(), len(ids))
+		return ErrEmptyRow
+	}
+
+	after, err := snapshot(ctx, tx, selectQuery, ids, db.household())
+	if err != nil {
+		return err
+	}
+	if err := db.audit(ctx, tx, "set credit", AuditOperation, before, after); err != nil {
 		return err
 	}
 
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error commit transaction: %v\n", err)
+		return err
+	}
 	return nil
 }
 
 // Создать представление, отображающее все статьи и суммы приход/расход неучтенных операций
 func (db *Database) GetViewUnaccountedOpertions(ctx context.Context) ([]ArticleTotalMoney, error) {
-	rows, err := db.pool.Query(ctx, "SELECT * FROM unaccounted_operations")
+	query := `
+	SELECT a.name, COALESCE(SUM(o.debit), 0), COALESCE(SUM(o.credit), 0)
+	FROM articles a
+	LEFT JOIN operations o ON o.article_id = a.id AND o.deleted_at IS NULL
+	  AND NOT EXISTS (SELECT 1 FROM balance b WHERE b.id = o.balance_id AND b.deleted_at IS NULL)
+	WHERE a.household_id = $1 AND a.deleted_at IS NULL
+	GROUP BY a.id, a.name
+	ORDER BY a.id
+	`
+
+	rows, err := db.pool.Query(ctx, query, db.household())
 	if err != nil {
 		log.Printf("Error getting unaccounted_operations: %v", err)
 		return nil, err
@@ -492,7 +855,16 @@ func (db *Database) GetViewUnaccountedOpertions(ctx context.Context) ([]ArticleT
 
 // Создать представление, отображающее все балансы и число операций, на основании которых они были сформированы
 func (db *Database) GetViewCountBalanceOper(ctx context.Context) ([]BalanceOperations, error) {
-	rows, err := db.pool.Query(ctx, "SELECT * FROM balance_operations_count ORDER BY balance_id")
+	query := `
+	SELECT b.id, b.create_date, COUNT(o.id)
+	FROM balance b
+	LEFT JOIN operations o ON o.balance_id = b.id AND o.deleted_at IS NULL
+	WHERE b.household_id = $1 AND b.deleted_at IS NULL
+	GROUP BY b.id, b.create_date
+	ORDER BY b.id
+	`
+
+	rows, err := db.pool.Query(ctx, query, db.household())
 	if err != nil {
 		log.Printf("Error getting balance_operations_count: %v", err)
 		return nil, err
@@ -535,6 +907,16 @@ func (db *Database) GetStoreProcBalanceWithProfit(ctx context.Context, articleFi
 
 // Создать хранимую процедуру с входным параметром баланс и выходным параметром – статья, операции по которой проведены с наибольшими расходами
 func (db *Database) GetStoreProcArticleMaxExpens(ctx context.Context, balance int, article string) error {
+	var owned bool
+	if err := db.pool.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM balance WHERE id = $1 AND household_id = $2)", balance, db.household()).Scan(&owned); err != nil {
+		log.Printf("Error while check balance household: %v", err)
+		return err
+	}
+	if !owned {
+		log.Printf("Error no balance found with id: %d", balance)
+		return ErrEmptyRow
+	}
+
 	if err := db.pool.QueryRow(ctx, "CALL get_article_with_max_expenses($1, $2)", balance, &article).Scan(&article); err != nil {
 		log.Printf("Failed to call get_balances_with_profit_comparison procedure: %v", err)
 		return err
@@ -552,7 +934,11 @@ func (db *Database) RegistrUserDB(ctx context.Context, username, password, role
 	}
 	defer tx.Rollback(context.Background())
 
-	if _, err := db.pool.Exec(ctx, "INSERT INTO users(username, password, role) VALUES($1, $2, $3)", username, password, role); err != nil {
+	if _, err := tx.Exec(ctx, "INSERT INTO users(username, password, role) VALUES($1, $2, $3)", username, password, role); err != nil {
+		return err
+	}
+	// у нового пользователя сразу есть личное домохозяйство
+	if _, err := insertHousehold(ctx, tx, PersonalHouseholdName(username), username); err != nil {
 		return err
 	}
 
@@ -585,16 +971,26 @@ func (db *Database) UpdateOpertions(ctx context.Context, id int, articleName str
 	}
 	defer tx.Rollback(context.Background())
 
+	selectQuery := "SELECT * FROM operations WHERE id = $1 AND household_id = $2 AND deleted_at IS NULL"
+	before, err := snapshot(ctx, tx, selectQuery, id, db.household())
+	if err != nil {
+		return err
+	}
+
+	if err := checkOpenPeriods(ctx, tx, "SELECT create_date, household_id FROM ("+selectQuery+") o", id, db.household()); err != nil {
+		return err
+	}
+
 	query := `
 	UPDATE operations 
 	SET 
-		article_id = (SELECT DISTINCT id FROM articles WHERE articles.name = $1),
+		article_id = (SELECT DISTINCT id FROM articles WHERE articles.name = $1 AND articles.household_id = $5 AND articles.deleted_at IS NULL),
 		debit = $2,
 		credit = $3
-	WHERE id = $4
+	WHERE id = $4 AND household_id = $5 AND deleted_at IS NULL
 	`
 
-	commandTag, err := tx.Exec(ctx, query, articleName, debit, credit, id)
+	commandTag, err := tx.Exec(ctx, query, articleName, debit, credit, id, db.household())
 
 	if err != nil {
 		log.Printf("Error failed to update operation: %v", err)
@@ -606,6 +1002,14 @@ func (db *Database) UpdateOpertions(ctx context.Context, id int, articleName str
 		return ErrEmptyRow
 	}
 
+	after, err := snapshot(ctx, tx, selectQuery, id, db.household())
+	if err != nil {
+		return err
+	}
+	if err := db.audit(ctx, tx, "update", AuditOperation, before, after); err != nil {
+		return err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		log.Printf("Error commit transaction: %v\n", err)
 		return err
@@ -621,8 +1025,21 @@ func (db *Database) DeleteOperation(ctx context.Context, id int) error {
 		return err
 	}
 
-	query := `DELETE FROM operations WHERE id = $1`
-	commandTag, err := tx.Exec(ctx, query, id)
+	defer tx.Rollback(ctx)
+
+	selectQuery := "SELECT * FROM operations WHERE id = $1 AND household_id = $2 AND deleted_at IS NULL"
+	before, err := snapshot(ctx, tx, selectQuery, id, db.household())
+	if err != nil {
+		return err
+	}
+
+	if err := checkOpenPeriods(ctx, tx, "SELECT create_date, household_id FROM ("+selectQuery+") o", id, db.household()); err != nil {
+		return err
+	}
+
+	// операция переносится в корзину
+	query := `UPDATE operations SET deleted_at = now() WHERE id = $1 AND household_id = $2 AND deleted_at IS NULL`
+	commandTag, err := tx.Exec(ctx, query, id, db.household())
 	if err != nil {
 		log.Printf("Error deleting operation %v", err)
 		return err
@@ -631,6 +1048,10 @@ func (db *Database) DeleteOperation(ctx context.Context, id int) error {
 		log.Printf("Error deleting operation nothing %v", err)
 		return ErrEmptyRow
 	}
+
+	if err := db.audit(ctx, tx, "delete", AuditOperation, before, nil); err != nil {
+		return err
+	}
 	if err := tx.Commit(ctx); err != nil {
 		log.Printf("Error commit transaction: %v\n", err)
 		return err
@@ -645,8 +1066,16 @@ func (db *Database) DeleteBalance(ctx context.Context, date string) error {
 		return err
 	}
 
-	query := `DELETE FROM balance WHERE create_date = $1`
-	commandTag, err := tx.Exec(ctx, query, date)
+	defer tx.Rollback(ctx)
+
+	before, err := snapshot(ctx, tx, "SELECT * FROM balance WHERE create_date = $1 AND household_id = $2 AND deleted_at IS NULL", date, db.household())
+	if err != nil {
+		return err
+	}
+
+	// баланс переносится в корзину, его операции снова считаются неучтёнными
+	query := `UPDATE balance SET deleted_at = now() WHERE create_date = $1 AND household_id = $2 AND deleted_at IS NULL`
+	commandTag, err := tx.Exec(ctx, query, date, db.household())
 	if err != nil {
 		log.Printf("Error deleting balance %v", err)
 		return err
@@ -655,6 +1084,10 @@ func (db *Database) DeleteBalance(ctx context.Context, date string) error {
 		log.Printf("Error no balances found with date: %s", date)
 		return ErrEmptyRow
 	}
+
+	if err := db.audit(ctx, tx, "delete", AuditBalance, before, nil); err != nil {
+		return err
+	}
 	if err := tx.Commit(ctx); err != nil {
 		log.Printf("Error commit transaction: %v\n", err)
 		return err
@@ -662,27 +1095,23 @@ func (db *Database) DeleteBalance(ctx context.Context, date string) error {
 	return nil
 }
 
+// Динамика доходов и расходов по выбранным статьям за период, пустой список - все статьи
 func (db *Database) GetIncomeExpenseDynamics(ctx context.Context, articles []string, startDate, endDate string) ([]DateTotalMoney, error) {
-	tx, err := db.pool.Begin(ctx)
-	if err != nil {
-		log.Printf("Error starting transaction: %v", err)
-		return nil, err
-	}
-	defer tx.Rollback(ctx)
-
-	query := `CALL get_income_expense_dynamics($1, $2, $3, $4)`
-	cursorName := "help"
-
-	_, err = tx.Exec(ctx, query, startDate, endDate, articles, cursorName)
-	if err != nil {
-		log.Printf("Procedure call failed: %v\n", err)
-		return nil, err
-	}
+	query := `
+	SELECT o.create_date, COALESCE(SUM(o.debit), 0), COALESCE(SUM(o.credit), 0)
+	FROM operations o
+	JOIN articles a ON o.article_id = a.id
+	WHERE (COALESCE(cardinality($3::text[]), 0) = 0 OR a.name = ANY($3))
+	  AND o.household_id = $4
+	  AND a.deleted_at IS NULL AND o.deleted_at IS NULL
+	  AND o.create_date BETWEEN $1 AND $2
+	GROUP BY o.create_date
+	ORDER BY o.create_date
+	`
 
-	// Извлечение данных из курсора
-	rows, err := tx.Query(ctx, fmt.Sprintf("FETCH ALL FROM %s", cursorName))
+	rows, err := db.pool.Query(ctx, query, startDate, endDate, articles, db.household())
 	if err != nil {
-		log.Printf("Failed to fetch cursor data: %v\n", err)
+		log.Printf("Failed to get income expense dynamics: %v\n", err)
 		return nil, err
 	}
 	defer rows.Close()
@@ -699,35 +1128,41 @@ func (db *Database) GetIncomeExpenseDynamics(ctx context.Context, articles []str
 		dateTotalMoneys = append(dateTotalMoneys, dateTotalMoney)
 	}
 
-	if err := tx.Commit(ctx); err != nil {
-		log.Printf("Error commit transaction: %v\n", err)
-		return nil, err
-	}
 	return dateTotalMoneys, nil
 }
 
+// Доля каждой статьи в общем потоке (доход, расход или прибыль) за период, пустой список - все статьи
 func (db *Database) GetFinancialPercentages(ctx context.Context, articles []string, flow, startDate, endDate string) ([]FinancialPercentage, error) {
-	tx, err := db.pool.Begin(ctx)
-	if err != nil {
-		log.Printf("Error starting transaction: %v", err)
-		return nil, err
-	}
-	defer tx.Rollback(ctx)
-
-	// Вызов процедуры
-	query := `CALL calculate_financial_percentages($1, $2, $3, $4, $5)`
-	cursorName := "help"
-
-	_, err = tx.Exec(ctx, query, startDate, endDate, articles, flow, cursorName) // Используем tx вместо db.pool
-	if err != nil {
-		log.Printf("Procedure call failed: %v\n", err)
-		return nil, err
-	}
+	query := `
+	WITH totals AS (
+		SELECT a.name AS article_name,
+			COALESCE(SUM(o.debit), 0) AS total_debit,
+			COALESCE(SUM(o.credit), 0) AS total_credit,
+			COALESCE(SUM(o.debit - o.credit), 0) AS total_profit
+		FROM operations o
+		JOIN articles a ON o.article_id = a.id
+		WHERE (COALESCE(cardinality($3::text[]), 0) = 0 OR a.name = ANY($3))
+		  AND o.household_id = $5
+		  AND a.deleted_at IS NULL AND o.deleted_at IS NULL
+		  AND o.create_date BETWEEN $1 AND $2
+		GROUP BY a.name
+	), flows AS (
+		SELECT *, CASE $4
+			WHEN 'debit' THEN total_debit
+			WHEN 'credit' THEN total_credit
+			ELSE total_profit
+		END AS flow_value
+		FROM totals
+	)
+	SELECT article_name, total_debit, total_credit, total_profit,
+		COALESCE(flow_value * 100 / NULLIF(SUM(flow_value) OVER (), 0), 0)
+	FROM flows
+	ORDER BY article_name
+	`
 
-	// Извлечение данных из курсора
-	rows, err := tx.Query(ctx, fmt.Sprintf("FETCH ALL FROM %s", cursorName)) // Используем tx вместо db.pool
+	rows, err := db.pool.Query(ctx, query, startDate, endDate, articles, flow, db.household())
 	if err != nil {
-		log.Printf("Failed to fetch cursor data: %v\n", err)
+		log.Printf("Failed to get financial percentages: %v\n", err)
 		return nil, err
 	}
 	defer rows.Close()
@@ -750,35 +1185,24 @@ func (db *Database) GetFinancialPercentages(ctx context.Context, articles []stri
 		dateFinancialPercentages = append(dateFinancialPercentages, dateFinancialPercentage)
 	}
 
-	if err := tx.Commit(ctx); err != nil {
-		log.Printf("Error commit transaction: %v\n", err)
-		return nil, err
-	}
 	return dateFinancialPercentages, nil
 
 }
 
+// Чистая прибыль бюджета по датам за период
 func (db *Database) GetTotalProfitDate(ctx context.Context, startDate, endDate string) ([]DateProfit, error) {
-	tx, err := db.pool.Begin(ctx)
-	if err != nil {
-		log.Printf("Error starting transaction: %v", err)
-		return nil, err
-	}
-	defer tx.Rollback(ctx)
-
-	query := `CALL get_total_profit_date($1, $2, $3)`
-	cursorName := "help"
-
-	_, err = tx.Exec(ctx, query, startDate, endDate, cursorName)
-	if err != nil {
-		log.Printf("Procedure call failed: %v\n", err)
-		return nil, err
-	}
+	query := `
+	SELECT create_date, COALESCE(SUM(debit - credit), 0), ` + moneyOnHandExpr("$3", "create_date") + `
+	FROM operations
+	WHERE household_id = $3 AND deleted_at IS NULL
+	  AND create_date BETWEEN $1 AND $2
+	GROUP BY create_date
+	ORDER BY create_date
+	`
 
-	// Извлечение данных из курсора
-	rows, err := tx.Query(ctx, fmt.Sprintf("FETCH ALL FROM %s", cursorName))
+	rows, err := db.pool.Query(ctx, query, startDate, endDate, db.household())
 	if err != nil {
-		log.Printf("Failed to fetch cursor data: %v\n", err)
+		log.Printf("Failed to get total profit: %v\n", err)
 		return nil, err
 	}
 	defer rows.Close()
@@ -787,7 +1211,7 @@ func (db *Database) GetTotalProfitDate(ctx context.Context, startDate, endDate s
 	var dateProfits []DateProfit
 	for rows.Next() {
 		var dateProfit DateProfit
-		err := rows.Scan(&dateProfit.Date, &dateProfit.TotalProfit)
+		err := rows.Scan(&dateProfit.Date, &dateProfit.TotalProfit, &dateProfit.OnHand)
 		if err != nil {
 			log.Printf("Failed to scan row: %v\n", err)
 			return nil, err
@@ -795,9 +1219,5 @@ func (db *Database) GetTotalProfitDate(ctx context.Context, startDate, endDate s
 		dateProfits = append(dateProfits, dateProfit)
 	}
 
-	if err := tx.Commit(ctx); err != nil {
-		log.Printf("Error commit transaction: %v\n", err)
-		return nil, err
-	}
 	return dateProfits, nil
 }
@@ -0,0 +1,229 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+)
+
+// периодичность расписаний отчётов
+const (
+	ScheduleDaily   = "daily"
+	ScheduleWeekly  = "weekly"
+	ScheduleMonthly = "monthly"
+)
+
+var ScheduleFrequencies = []string{ScheduleDaily, ScheduleWeekly, ScheduleMonthly}
+
+// итог последнего запуска расписания
+const (
+	ScheduleOK    = "ok"
+	ScheduleError = "error"
+)
+
+// NextScheduleRun - ближайший запуск после now, отсчитанный от прошлого планового запуска.
+// Ежемесячные запуски приходятся на число первого запуска, в коротких месяцах - на последний день
+func NextScheduleRun(first, planned time.Time, frequency string, now time.Time) time.Time {
+	next := planned
+	months := (planned.Year()-first.Year())*12 + int(planned.Month()) - int(first.Month())
+	for !next.After(now) {
+		switch frequency {
+		case ScheduleDaily:
+			next = next.AddDate(0, 0, 1)
+		case ScheduleWeekly:
+			next = next.AddDate(0, 0, 7)
+		default:
+			months++
+			next = addMonthsClamped(first, months)
+		}
+	}
+	return next
+}
+
+// дата через months месяцев с тем же числом, не дальше конца месяца
+func addMonthsClamped(t time.Time, months int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	day := t.Day()
+	if lastDay := first.AddDate(0, 1, -1).Day(); day > lastDay {
+		day = lastDay
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+const scheduleColumns = `id, household_id, username, report_id, period, params, format, directory,
+	frequency, COALESCE(first_run, next_run), next_run, last_run, last_status, last_message`
+
+func scanSchedule(row interface{ Scan(dest ...any) error }) (ReportSchedule, error) {
+	var schedule ReportSchedule
+	var params []byte
+	err := row.Scan(&schedule.ID, &schedule.HouseholdID, &schedule.Username, &schedule.ReportID, &schedule.Period,
+		&params, &schedule.Format, &schedule.Directory, &schedule.Frequency, &schedule.FirstRun, &schedule.NextRun,
+		&schedule.LastRun, &schedule.LastStatus, &schedule.LastMessage)
+	if err != nil {
+		return schedule, err
+	}
+	if err := json.Unmarshal(params, &schedule.Values); err != nil {
+		return schedule, err
+	}
+	return schedule, nil
+}
+
+// расписания отчётов активного домохозяйства
+func (db *Database) GetReportSchedules(ctx context.Context) ([]ReportSchedule, error) {
+	query := `SELECT ` + scheduleColumns + ` FROM report_schedules WHERE household_id = $1 ORDER BY id`
+
+	rows, err := db.pool.Query(ctx, query, db.household())
+	if err != nil {
+		log.Printf("Error while get report schedules: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schedules []ReportSchedule
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			log.Printf("Error while scan report schedule: %v", err)
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+	return schedules, nil
+}
+
+// расписания всех домохозяйств, чей запуск наступил к now
+func (db *Database) GetDueReportSchedules(ctx context.Context, now time.Time) ([]ReportSchedule, error) {
+	query := `SELECT ` + scheduleColumns + ` FROM report_schedules WHERE next_run <= $1 ORDER BY next_run, id`
+
+	rows, err := db.pool.Query(ctx, query, now)
+	if err != nil {
+		log.Printf("Error while get due report schedules: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schedules []ReportSchedule
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			log.Printf("Error while scan report schedule: %v", err)
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+	return schedules, nil
+}
+
+// добавить расписание от имени текущего пользователя
+func (db *Database) AddReportSchedule(ctx context.Context, schedule ReportSchedule) error {
+	if !validFrequency(schedule.Frequency) || schedule.Directory == "" {
+		return ErrScheduleSpec
+	}
+	params, err := json.Marshal(schedule.Values)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+	INSERT INTO report_schedules (household_id, username, report_id, period, params, format, directory, frequency, first_run, next_run)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9)
+	RETURNING id
+	`
+	var id int
+	err = tx.QueryRow(ctx, query, db.household(), db.user(), schedule.ReportID, schedule.Period, params,
+		schedule.Format, schedule.Directory, schedule.Frequency, schedule.NextRun).Scan(&id)
+	if err != nil {
+		log.Printf("Error while insert report schedule: %v", err)
+		return err
+	}
+
+	after, err := snapshot(ctx, tx, "SELECT * FROM report_schedules WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	if err := db.audit(ctx, tx, "add", AuditSchedule, nil, after); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error commit transaction: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+func (db *Database) DeleteReportSchedule(ctx context.Context, id int) error {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	selectQuery := "SELECT * FROM report_schedules WHERE id = $1 AND household_id = $2"
+	before, err := snapshot(ctx, tx, selectQuery, id, db.household())
+	if err != nil {
+		return err
+	}
+
+	commandTag, err := tx.Exec(ctx, "DELETE FROM report_schedules WHERE id = $1 AND household_id = $2", id, db.household())
+	if err != nil {
+		log.Printf("Error while delete report schedule: %v", err)
+		return err
+	}
+	if commandTag.RowsAffected() == 0 {
+		log.Printf("Error no report schedule found with id: %d", id)
+		return ErrEmptyRow
+	}
+
+	if err := db.audit(ctx, tx, "delete", AuditSchedule, before, nil); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error commit transaction: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+// ClaimReportSchedule сдвигает плановый запуск до построения отчёта, чтобы
+// одно расписание не выполнили одновременно приложение и команда run-schedules
+func (db *Database) ClaimReportSchedule(ctx context.Context, id int, planned, next time.Time) (bool, error) {
+	commandTag, err := db.pool.Exec(ctx, "UPDATE report_schedules SET next_run = $3 WHERE id = $1 AND next_run = $2", id, planned, next)
+	if err != nil {
+		log.Printf("Error while claim report schedule: %v", err)
+		return false, err
+	}
+	return commandTag.RowsAffected() > 0, nil
+}
+
+// записать итог запуска: имя файла или текст ошибки
+func (db *Database) FinishReportSchedule(ctx context.Context, id int, status, message string) error {
+	query := `
+	UPDATE report_schedules
+	SET last_run = now(), last_status = $2, last_message = $3
+	WHERE id = $1
+	`
+	if _, err := db.pool.Exec(ctx, query, id, status, message); err != nil {
+		log.Printf("Error while finish report schedule: %v", err)
+		return err
+	}
+	return nil
+}
+
+func validFrequency(frequency string) bool {
+	for _, f := range ScheduleFrequencies {
+		if f == frequency {
+			return true
+		}
+	}
+	return false
+}
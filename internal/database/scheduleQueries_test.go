@@ -0,0 +1,35 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextScheduleRun(t *testing.T) {
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 8, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name      string
+		first     time.Time
+		planned   time.Time
+		frequency string
+		now       time.Time
+		want      time.Time
+	}{
+		{"ежедневно", at(2024, time.January, 1), at(2024, time.March, 10), ScheduleDaily, at(2024, time.March, 10), at(2024, time.March, 11)},
+		{"ежедневно после простоя", at(2024, time.January, 1), at(2024, time.March, 10), ScheduleDaily, at(2024, time.March, 13), at(2024, time.March, 14)},
+		{"еженедельно", at(2024, time.January, 1), at(2024, time.March, 4), ScheduleWeekly, at(2024, time.March, 5), at(2024, time.March, 11)},
+		{"31-е в феврале", at(2024, time.January, 31), at(2024, time.January, 31), ScheduleMonthly, at(2024, time.January, 31), at(2024, time.February, 29)},
+		{"после февраля снова 31-е", at(2024, time.January, 31), at(2024, time.February, 29), ScheduleMonthly, at(2024, time.February, 29), at(2024, time.March, 31)},
+		{"31-е в апреле", at(2024, time.January, 31), at(2024, time.March, 31), ScheduleMonthly, at(2024, time.March, 31), at(2024, time.April, 30)},
+		{"пропущенные месяцы", at(2024, time.January, 31), at(2024, time.January, 31), ScheduleMonthly, at(2024, time.May, 1), at(2024, time.May, 31)},
+		{"через год", at(2023, time.December, 15), at(2023, time.December, 15), ScheduleMonthly, at(2023, time.December, 15), at(2024, time.January, 15)},
+	}
+	for _, tt := range tests {
+		if got := NextScheduleRun(tt.first, tt.planned, tt.frequency, tt.now); !got.Equal(tt.want) {
+			t.Errorf("%s: NextScheduleRun = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package database
+
+import (
+	"context"
+	"log"
+)
+
+// дополнительные таблицы приложения, создаются при запуске, если их ещё нет
+var schema = []string{
+	// неудачные попытки входа по пользователю
+	`CREATE TABLE IF NOT EXISTS login_attempts (
+		username       TEXT PRIMARY KEY,
+		failed_count   INTEGER NOT NULL DEFAULT 0,
+		last_failed_at TIMESTAMPTZ,
+		locked_until   TIMESTAMPTZ
+	)`,
+	// история входов
+	`CREATE TABLE IF NOT EXISTS login_history (
+		id         SERIAL PRIMARY KEY,
+		username   TEXT NOT NULL,
+		success    BOOLEAN NOT NULL,
+		reason     TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	// вид события истории входов: вход или разблокировка администратором
+	`ALTER TABLE login_history ADD COLUMN IF NOT EXISTS event TEXT NOT NULL DEFAULT 'login'`,
+	// секреты TOTP для двухфакторной аутентификации
+	`CREATE TABLE IF NOT EXISTS user_totp (
+		username   TEXT PRIMARY KEY,
+		secret     TEXT NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	// последний принятый шаг TOTP, код этого и более ранних шагов повторно не принимается
+	`ALTER TABLE user_totp ADD COLUMN IF NOT EXISTS last_counter BIGINT NOT NULL DEFAULT 0`,
+	// одноразовые коды восстановления, хранятся в виде хешей
+	`CREATE TABLE IF NOT EXISTS user_recovery_codes (
+		id        SERIAL PRIMARY KEY,
+		username  TEXT NOT NULL,
+		code_hash TEXT NOT NULL,
+		used_at   TIMESTAMPTZ
+	)`,
+	// домохозяйства и их участники
+	`CREATE TABLE IF NOT EXISTS households (
+		id   SERIAL PRIMARY KEY,
+		name TEXT NOT NULL
+	)`,
+	// название не уникально: у разных пользователей могут быть одноимённые бюджеты
+	`ALTER TABLE households DROP CONSTRAINT IF EXISTS households_name_key`,
+	`CREATE TABLE IF NOT EXISTS household_members (
+		household_id INTEGER NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+		username     TEXT NOT NULL,
+		PRIMARY KEY (household_id, username)
+	)`,
+	`ALTER TABLE articles ADD COLUMN IF NOT EXISTS household_id INTEGER REFERENCES households(id)`,
+	`ALTER TABLE operations ADD COLUMN IF NOT EXISTS household_id INTEGER REFERENCES households(id)`,
+	`ALTER TABLE balance ADD COLUMN IF NOT EXISTS household_id INTEGER REFERENCES households(id)`,
+	// существующие данные и пользователи переносятся в общее домохозяйство один раз,
+	// пока участников ещё нет; новые пользователи получают личное при регистрации
+	`DO $$
+	BEGIN
+		IF NOT EXISTS (SELECT 1 FROM household_members) THEN
+			INSERT INTO households (name) SELECT 'Общий бюджет' WHERE NOT EXISTS (SELECT 1 FROM households);
+			UPDATE articles SET household_id = (SELECT MIN(id) FROM households) WHERE household_id IS NULL;
+			UPDATE operations SET household_id = (SELECT MIN(id) FROM households) WHERE household_id IS NULL;
+			UPDATE balance SET household_id = (SELECT MIN(id) FROM households) WHERE household_id IS NULL;
+			INSERT INTO household_members (household_id, username)
+			SELECT (SELECT MIN(id) FROM households), username FROM users;
+		END IF;
+	END
+	$$`,
+	// названия статей уникальны в пределах домохозяйства
+	`ALTER TABLE articles DROP CONSTRAINT IF EXISTS articles_name_key`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS articles_household_name_idx ON articles (household_id, name)`,
+	// журнал изменений данных
+	`CREATE TABLE IF NOT EXISTS audit_log (
+		id           SERIAL PRIMARY KEY,
+		household_id INTEGER,
+		username     TEXT NOT NULL DEFAULT '',
+		action       TEXT NOT NULL,
+		entity       TEXT NOT NULL,
+		before_data  JSONB,
+		after_data   JSONB,
+		created_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE INDEX IF NOT EXISTS audit_log_household_created_idx ON audit_log (household_id, created_at)`,
+	// корзина: удалённые записи помечаются временем удаления
+	`ALTER TABLE articles ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ`,
+	`ALTER TABLE operations ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ`,
+	`ALTER TABLE balance ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ`,
+	// уникальность проверяется только среди неудалённых записей
+	`DROP INDEX IF EXISTS articles_household_name_idx`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS articles_household_name_active_idx ON articles (household_id, name) WHERE deleted_at IS NULL`,
+	`ALTER TABLE balance DROP CONSTRAINT IF EXISTS balance_create_date_key`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS balance_household_date_active_idx ON balance (household_id, create_date) WHERE deleted_at IS NULL`,
+	// повторное открытие закрытого периода администратором
+	`ALTER TABLE balance ADD COLUMN IF NOT EXISTS reopened_at TIMESTAMPTZ`,
+	`ALTER TABLE balance ADD COLUMN IF NOT EXISTS reopened_by TEXT`,
+	`ALTER TABLE balance ADD COLUMN IF NOT EXISTS reopen_reason TEXT`,
+	// период баланса задаётся явно: тип, начало и конец (create_date)
+	`ALTER TABLE balance ADD COLUMN IF NOT EXISTS period_type TEXT NOT NULL DEFAULT 'month'`,
+	`ALTER TABLE balance ADD COLUMN IF NOT EXISTS start_date DATE`,
+	`UPDATE balance SET start_date = date_trunc('month', create_date)::date WHERE start_date IS NULL`,
+	`ALTER TABLE balance ALTER COLUMN start_date SET NOT NULL`,
+	// начальный остаток домохозяйства для расчёта денег на руках
+	`ALTER TABLE households ADD COLUMN IF NOT EXISTS opening_balance DOUBLE PRECISION NOT NULL DEFAULT 0`,
+	`ALTER TABLE households ADD COLUMN IF NOT EXISTS opening_date DATE`,
+	// регулярные платежи для прогноза
+	`CREATE TABLE IF NOT EXISTS recurring_items (
+		id           SERIAL PRIMARY KEY,
+		household_id INTEGER NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+		article_id   INTEGER NOT NULL REFERENCES articles(id) ON DELETE CASCADE,
+		debit        DOUBLE PRECISION NOT NULL DEFAULT 0,
+		credit       DOUBLE PRECISION NOT NULL DEFAULT 0,
+		start_date   DATE NOT NULL,
+		end_date     DATE,
+		every_months INTEGER NOT NULL DEFAULT 1 CHECK (every_months > 0)
+	)`,
+	// метка статьи для группировки в отчётах
+	`ALTER TABLE articles ADD COLUMN IF NOT EXISTS tag TEXT NOT NULL DEFAULT ''`,
+	// сохранённые пользователями конструкторы отчётов
+	`CREATE TABLE IF NOT EXISTS saved_reports (
+		id           SERIAL PRIMARY KEY,
+		household_id INTEGER NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+		username     TEXT NOT NULL,
+		name         TEXT NOT NULL,
+		spec         JSONB NOT NULL,
+		created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+		UNIQUE (household_id, username, name)
+	)`,
+	// расписания автоматического построения отчётов в папку
+	`CREATE TABLE IF NOT EXISTS report_schedules (
+		id           SERIAL PRIMARY KEY,
+		household_id INTEGER NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+		username     TEXT NOT NULL,
+		report_id    TEXT NOT NULL,
+		period       TEXT NOT NULL,
+		params       JSONB NOT NULL DEFAULT '{}',
+		format       TEXT NOT NULL,
+		directory    TEXT NOT NULL,
+		frequency    TEXT NOT NULL CHECK (frequency IN ('daily', 'weekly', 'monthly')),
+		next_run     TIMESTAMPTZ NOT NULL,
+		last_run     TIMESTAMPTZ,
+		last_status  TEXT NOT NULL DEFAULT '',
+		last_message TEXT NOT NULL DEFAULT ''
+	)`,
+	// первый запуск: от него отсчитываются ежемесячные запуски, чтобы 31-е число не сползало
+	`ALTER TABLE report_schedules ADD COLUMN IF NOT EXISTS first_run TIMESTAMPTZ`,
+	`UPDATE report_schedules SET first_run = next_run WHERE first_run IS NULL`,
+	// сохранённые пользователями наборы параметров отчётов
+	`CREATE TABLE IF NOT EXISTS report_presets (
+		id           SERIAL PRIMARY KEY,
+		household_id INTEGER NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+		username     TEXT NOT NULL,
+		report_id    TEXT NOT NULL,
+		name         TEXT NOT NULL,
+		period       TEXT NOT NULL DEFAULT '',
+		params       JSONB NOT NULL DEFAULT '{}',
+		created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+		UNIQUE (household_id, username, report_id, name)
+	)`,
+}
+
+// Migrate создаёт недостающие таблицы приложения
+func (db *Database) Migrate(ctx context.Context) error {
+	for _, query := range schema {
+		if _, err := db.pool.Exec(ctx, query); err != nil {
+			log.Printf("Error while migrate schema: %v", err)
+			return err
+		}
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/EmptyInsid/db_gui/internal/models"
@@ -11,10 +12,14 @@ import (
 type Service interface {
 	CloseDB()
 
-	AddArticle(ctx context.Context, name string) error                                                      //справочник статей +
-	AddOperation(ctx context.Context, articleName string, debit float64, credit float64, date string) error //справочник операций +
+	AddArticle(ctx context.Context, name string) error                                                             //справочник статей +
+	AddOperation(ctx context.Context, articleName string, debit float64, credit float64, date string) (int, error) //справочник операций +
 
-	CreateBalanceIfProfitable(ctx context.Context, startDate, endDate string, minProfit float64) error //журнал +
+	CreateBalanceIfProfitable(ctx context.Context, periodType, startDate, endDate string, minProfit float64) error            //журнал +
+	GetOpeningBalance(ctx context.Context) (models.OpeningBalance, error)                                                     //журнал +
+	SetOpeningBalance(ctx context.Context, amount float64, date string) error                                                 //журнал +
+	GetMoneyOnHand(ctx context.Context, date string) (float64, error)                                                         //журнал, отчёт 3 +
+	CloseBalances(ctx context.Context, periodType, untilDate string, minProfit float64, dryRun bool) ([]ClosingPeriod, error) //журнал +
 
 	DeleteArticle(ctx context.Context, articleName string) error //справочник статей +
 	DeleteOperation(ctx context.Context, id int) error           //справочник операций +
@@ -31,26 +36,93 @@ type Service interface {
 
 	GetUnusedArticles(ctx context.Context, startData, finishData string) ([]models.Article, error) //справочник статей
 	GetArticlesWithOperations(ctx context.Context) ([]ArticleWithOperations, error)                //справочник операций +
+	GetOperation(ctx context.Context, id int) (ArticleWithOperations, error)                       //справочник операций +
+	IsPeriodClosed(ctx context.Context, date string) (bool, error)                                 //справочник операций +
+	ReopenPeriod(ctx context.Context, balanceID int, reason string) error                          //журнал +
+	ClosePeriod(ctx context.Context, balanceID int) error                                          //журнал +
+	VerifyBalances(ctx context.Context) (IntegrityReport, error)                                   //проверка +
+	RepairBalances(ctx context.Context) (IntegrityReport, error)                                   //проверка +
 	GetViewUnaccountedOpertions(ctx context.Context) ([]ArticleTotalMoney, error)                  //
 	GetViewCountBalanceOper(ctx context.Context) ([]BalanceOperations, error)                      //
 
 	GetStoreProcLastBalanceOp(ctx context.Context) error                                 //
 	GetStoreProcArticleMaxExpens(ctx context.Context, balance int, article string) error //
 
-	UpdateArticle(ctx context.Context, oldName, newName string) error                                     //справочник статей +
-	UpdateOpertions(ctx context.Context, id int, articleName string, debit float64, credit float64) error //справочник операций +
-	IncreaseExpensesForArticle(ctx context.Context, articleName string, increaseAmount float64) error     //справочник операций +
+	UpdateArticle(ctx context.Context, oldName, newName string) error                                                   //справочник статей +
+	SetArticleTag(ctx context.Context, articleName, tag string) error                                                   //справочник статей +
+	UpdateOpertions(ctx context.Context, id int, articleName string, debit float64, credit float64) error               //справочник операций +
+	IncreaseExpensesForArticle(ctx context.Context, articleName string, increaseAmount float64) ([]CreditChange, error) //справочник операций +
+	SetOperationCredits(ctx context.Context, credits map[int]float64) error                                             //справочник операций +
 
 	AuthUser(ctx context.Context, username, password string) (string, string, error) //вход +
 	RegistrUserDB(ctx context.Context, username, password, role string) error        //регистрация -
 
+	GetLoginAttempts(ctx context.Context, username string) (models.LoginAttempts, error)                                            //вход +
+	GetAllLoginAttempts(ctx context.Context) ([]models.LoginAttempts, error)                                                        //пользователи +
+	RecordLoginFailure(ctx context.Context, username string, failedAt time.Time, maxFailed int, lockedUntil time.Time) (int, error) //вход +
+	ResetLoginAttempts(ctx context.Context, username string) error                                                                  //вход, пользователи +
+	AddLoginRecord(ctx context.Context, username string, success bool, reason string) error                                         //вход +
+	AddUnlockRecord(ctx context.Context, username, reason string) error                                                             //пользователи +
+	GetLoginHistory(ctx context.Context) ([]models.LoginRecord, error)                                                              //пользователи +
+
+	GetTOTPSecret(ctx context.Context, username string) (string, error)                     //вход, 2FA +
+	EnableTOTP(ctx context.Context, username, secret string, recoveryHashes []string) error //2FA +
+	AcceptTOTPCounter(ctx context.Context, username string, counter int64) (bool, error)    //вход, 2FA +
+	DisableTOTP(ctx context.Context, username string) error                                 //2FA +
+	GetRecoveryCodes(ctx context.Context, username string) ([]models.RecoveryCode, error)   //вход +
+	UseRecoveryCode(ctx context.Context, id int) error                                      //вход +
+
+	GetUserHouseholds(ctx context.Context, username string) ([]models.Household, error) //домохозяйства +
+	AddHousehold(ctx context.Context, name, username string) (int, error)               //домохозяйства +
+	AddHouseholdMember(ctx context.Context, username string) error                      //домохозяйства +
+	UseHousehold(ctx context.Context, username string, householdID int) error           //домохозяйства +
+	CurrentHousehold() int                                                              //домохозяйства +
+	CurrentUser() string                                                                //отчёты +
+
+	GetAuditLog(ctx context.Context, filter AuditFilter) ([]models.AuditEntry, error) //журнал изменений +
+	GetDataVersion(ctx context.Context) (int64, error)                                //главная +
+
+	GetTrash(ctx context.Context) ([]TrashItem, error)                       //корзина +
+	RestoreArticle(ctx context.Context, id int) error                        //корзина +
+	RestoreOperation(ctx context.Context, id int) error                      //корзина +
+	RestoreBalance(ctx context.Context, id int) error                        //корзина +
+	PurgeTrash(ctx context.Context, olderThanDays int) (int64, error)        //корзина +
+	PurgeExpiredTrash(ctx context.Context, olderThanDays int) (int64, error) //запуск +
+
 	GetIncomeExpenseDynamics(ctx context.Context, articles []string, startDate, endDate string) ([]DateTotalMoney, error)
 	GetFinancialPercentages(ctx context.Context, articles []string, flow, startDate, endDate string) ([]FinancialPercentage, error)
 	GetTotalProfitDate(ctx context.Context, startDate, endDate string) ([]DateProfit, error)
+	GetArticleComparison(ctx context.Context, articles []string, flow, baseStart, baseEnd, currentStart, currentEnd string) ([]ArticleComparison, error) //сравнение +
+
+	GetMonthlyArticleTotals(ctx context.Context, startDate, endDate string) ([]ArticleMonthTotal, error) //прогноз +
+	GetRecurringItems(ctx context.Context) ([]models.RecurringItem, error)                               //прогноз +
+	AddRecurringItem(ctx context.Context, item models.RecurringItem) error                               //прогноз +
+	DeleteRecurringItem(ctx context.Context, id int) error                                               //прогноз +
+
+	RunAdhocReport(ctx context.Context, spec AdhocSpec) ([][]any, error) //конструктор отчётов +
+	GetSavedReports(ctx context.Context) ([]SavedReport, error)          //конструктор отчётов +
+	SaveReport(ctx context.Context, name string, spec AdhocSpec) error   //конструктор отчётов +
+	DeleteSavedReport(ctx context.Context, id int) error                 //конструктор отчётов +
+
+	GetReportSchedules(ctx context.Context) ([]ReportSchedule, error)                       //расписания +
+	GetDueReportSchedules(ctx context.Context, now time.Time) ([]ReportSchedule, error)     //расписания +
+	AddReportSchedule(ctx context.Context, schedule ReportSchedule) error                   //расписания +
+	DeleteReportSchedule(ctx context.Context, id int) error                                 //расписания +
+	ClaimReportSchedule(ctx context.Context, id int, planned, next time.Time) (bool, error) //расписания +
+	FinishReportSchedule(ctx context.Context, id int, status, message string) error         //расписания +
+	ForHousehold(username string, householdID int) Service                                  //расписания +
+
+	GetReportPresets(ctx context.Context, reportID string) ([]ReportPreset, error) //параметры отчётов +
+	SaveReportPreset(ctx context.Context, preset ReportPreset) error               //параметры отчётов +
+	DeleteReportPreset(ctx context.Context, id int) error                          //параметры отчётов +
 }
 
 type Database struct {
 	pool *pgxpool.Pool
+
+	mu          sync.RWMutex
+	username    string // пользователь текущей сессии
+	householdID int    // активное домохозяйство, все запросы ограничены им
 }
 
 type ArticleWithOperations struct {
@@ -63,6 +135,14 @@ type ArticleWithOperations struct {
 	BalanceID   *float64 // NULL, если операция не учтена
 }
 
+// изменение расхода операции, нужно для отмены повышения расходов
+type CreditChange struct {
+	OperationID int
+	CreateDate  time.Time
+	Before      float64
+	After       float64
+}
+
 type ArticleTotalMoney struct {
 	ArticleName string
 	TotalDebit  float64
@@ -89,7 +169,133 @@ type FinancialPercentage struct {
 	TotalProc   float64
 }
 
+type ArticleMonthTotal struct {
+	ArticleName string
+	Month       time.Time
+	TotalDebit  float64
+	TotalCredit float64
+}
+
+// поток статьи за базовый и текущий периоды
+type ArticleComparison struct {
+	ArticleName string
+	Base        float64
+	Current     float64
+	Change      float64
+	ChangeProc  *float64 // nil, если в базовом периоде ноль
+}
+
+// параметры отчёта конструктора: измерения, показатели и фильтры,
+// пустые фильтры не ограничивают выборку
+type AdhocSpec struct {
+	Dimensions []string `json:"dimensions"`
+	Measures   []string `json:"measures"`
+	StartDate  string   `json:"start_date,omitempty"`
+	EndDate    string   `json:"end_date,omitempty"`
+	Articles   []string `json:"articles,omitempty"`
+	MinAmount  *float64 `json:"min_amount,omitempty"`
+	MaxAmount  *float64 `json:"max_amount,omitempty"`
+}
+
+// расписание построения отчёта в папку. Отчёт строится от имени Username
+// в его домохозяйстве за относительный период Period
+type ReportSchedule struct {
+	ID          int
+	HouseholdID int
+	Username    string
+	ReportID    string              // отчёт из реестра
+	Period      string              // относительный период, например previous_month
+	Values      map[string][]string // остальные параметры отчёта
+	Format      string              // формат выгрузки: PDF, CSV, XLSX, HTML
+	Directory   string
+	Frequency   string    // daily, weekly или monthly
+	FirstRun    time.Time // первый запуск, к его числу привязаны ежемесячные
+	NextRun     time.Time
+	LastRun     *time.Time
+	LastStatus  string // ok или error, пусто - ещё не запускалось
+	LastMessage string // имя файла или текст ошибки
+}
+
+// именованный набор параметров отчёта пользователя
+type ReportPreset struct {
+	ID       int
+	ReportID string
+	Name     string
+	Period   string              // относительный период, пусто - даты из Values
+	Values   map[string][]string // параметры по ключам
+}
+
+// именованный отчёт конструктора пользователя
+type SavedReport struct {
+	ID   int
+	Name string
+	Spec AdhocSpec
+}
+
 type DateProfit struct {
 	Date        time.Time
 	TotalProfit float64
+	OnHand      float64 // деньги на руках на конец даты
+}
+
+// фильтр журнала изменений, пустые поля не ограничивают выборку
+type AuditFilter struct {
+	Username  string
+	Entity    string
+	StartDate string
+	EndDate   string
+}
+
+// удалённая запись в корзине
+type TrashItem struct {
+	Entity    string // article, operation или balance
+	ID        int
+	Name      string     // статья, для баланса пусто
+	Date      *time.Time // дата операции или баланса, для статьи NULL
+	Debit     float64
+	Credit    float64
+	DeletedAt time.Time
+}
+
+// расхождение сохранённых итогов баланса с суммой его операций
+type BalanceMismatch struct {
+	BalanceID    int
+	Date         time.Time
+	Debit        float64
+	Credit       float64
+	Amount       float64
+	ActualDebit  float64
+	ActualCredit float64
+	ActualAmount float64
+}
+
+// операция с нарушенной привязкой к балансу
+type IntegrityOperation struct {
+	OperationID int
+	ArticleName string
+	Date        time.Time
+	BalanceID   int // баланс, на который ссылается или к которому должна относиться операция
+}
+
+// результат проверки целостности балансов
+type IntegrityReport struct {
+	Mismatches []BalanceMismatch
+	Orphans    []IntegrityOperation // ссылки на несуществующие балансы
+	Unlinked   []IntegrityOperation // операции закрытых периодов без привязки к их балансу
+}
+
+func (r IntegrityReport) Empty() bool {
+	return len(r.Mismatches) == 0 && len(r.Orphans) == 0 && len(r.Unlinked) == 0
+}
+
+// период пакетного закрытия и его итоги
+type ClosingPeriod struct {
+	PeriodType string
+	Start      time.Time
+	End        time.Time
+	Debit      float64
+	Credit     float64
+	Profit     float64
+	Passes     bool // прибыль не ниже порога
+	Closed     bool // баланс сформирован
 }
@@ -0,0 +1,374 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// элементы корзины
+const (
+	TrashArticle   = AuditArticle
+	TrashOperation = AuditOperation
+	TrashBalance   = AuditBalance
+)
+
+// получить содержимое корзины активного домохозяйства,
+// операции, удалённые вместе со статьёй, входят в неё и отдельно не показываются
+func (db *Database) GetTrash(ctx context.Context) ([]TrashItem, error) {
+	query := `
+	SELECT 'article', a.id, a.name, NULL::date, 0::float8, 0::float8, a.deleted_at
+	FROM articles a
+	WHERE a.household_id = $1 AND a.deleted_at IS NOT NULL
+	UNION ALL
+	SELECT 'operation', o.id, a.name, o.create_date, o.debit, o.credit, o.deleted_at
+	FROM operations o
+	JOIN articles a ON a.id = o.article_id
+	WHERE o.household_id = $1 AND o.deleted_at IS NOT NULL
+	  AND a.deleted_at IS DISTINCT FROM o.deleted_at
+	UNION ALL
+	SELECT 'balance', b.id, '', b.create_date, b.debit, b.credit, b.deleted_at
+	FROM balance b
+	WHERE b.household_id = $1 AND b.deleted_at IS NOT NULL
+	ORDER BY 7 DESC, 2 DESC
+	`
+
+	rows, err := db.pool.Query(ctx, query, db.household())
+	if err != nil {
+		log.Printf("Error while get trash: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []TrashItem
+	for rows.Next() {
+		var item TrashItem
+		if err := rows.Scan(&item.Entity, &item.ID, &item.Name, &item.Date, &item.Debit, &item.Credit, &item.DeletedAt); err != nil {
+			log.Printf("Error while scan trash item: %v", err)
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// восстановить статью вместе с операциями, удалёнными одновременно с ней
+func (db *Database) RestoreArticle(ctx context.Context, id int) error {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var conflict bool
+	conflictQuery := `
+	SELECT EXISTS (
+		SELECT 1 FROM articles a
+		JOIN articles d ON d.name = a.name AND d.household_id = a.household_id
+		WHERE d.id = $1 AND a.deleted_at IS NULL
+	)
+	`
+	if err := tx.QueryRow(ctx, conflictQuery, id).Scan(&conflict); err != nil {
+		log.Printf("Error while check article conflict: %v", err)
+		return err
+	}
+	if conflict {
+		return ErrRestoreConflict
+	}
+
+	operationDates := `
+	SELECT o.create_date, o.household_id FROM operations o
+	JOIN articles a ON a.id = o.article_id AND o.deleted_at = a.deleted_at
+	WHERE a.id = $1 AND a.household_id = $2
+	`
+	if err := checkOpenPeriods(ctx, tx, operationDates, id, db.household()); err != nil {
+		return err
+	}
+
+	restoreOperationsQuery := `
+	UPDATE operations o SET deleted_at = NULL
+	FROM articles a
+	WHERE o.article_id = a.id AND o.deleted_at = a.deleted_at
+	  AND a.id = $1 AND a.household_id = $2
+	`
+	if _, err := tx.Exec(ctx, restoreOperationsQuery, id, db.household()); err != nil {
+		log.Printf("Error while restore article operations: %v", err)
+		return err
+	}
+
+	commandTag, err := tx.Exec(ctx, "UPDATE articles SET deleted_at = NULL WHERE id = $1 AND household_id = $2 AND deleted_at IS NOT NULL", id, db.household())
+	if err != nil {
+		log.Printf("Error while restore article: %v", err)
+		return err
+	}
+	if commandTag.RowsAffected() == 0 {
+		log.Printf("Error no deleted article found with id: %d", id)
+		return ErrEmptyRow
+	}
+
+	if err := db.relinkOperations(ctx, tx); err != nil {
+		return err
+	}
+
+	after, err := snapshot(ctx, tx, `
+	SELECT a.*, (SELECT jsonb_agg(to_jsonb(o)) FROM operations o WHERE o.article_id = a.id AND o.deleted_at IS NULL) AS operations
+	FROM articles a WHERE a.id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if err := db.audit(ctx, tx, "restore", AuditArticle, nil, after); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error commit transaction: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+// восстановить операцию, если её статья не удалена
+func (db *Database) RestoreOperation(ctx context.Context, id int) error {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var articleDeleted bool
+	articleQuery := `
+	SELECT a.deleted_at IS NOT NULL
+	FROM operations o
+	JOIN articles a ON a.id = o.article_id
+	WHERE o.id = $1 AND o.household_id = $2 AND o.deleted_at IS NOT NULL
+	`
+	err = tx.QueryRow(ctx, articleQuery, id, db.household()).Scan(&articleDeleted)
+	if errors.Is(err, pgx.ErrNoRows) {
+		log.Printf("Error no deleted operation found with id: %d", id)
+		return ErrEmptyRow
+	}
+	if err != nil {
+		log.Printf("Error while check operation article: %v", err)
+		return err
+	}
+	if articleDeleted {
+		return ErrArticleDeleted
+	}
+
+	if err := checkOpenPeriods(ctx, tx, "SELECT create_date, household_id FROM operations WHERE id = $1 AND household_id = $2", id, db.household()); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, "UPDATE operations SET deleted_at = NULL WHERE id = $1 AND household_id = $2", id, db.household()); err != nil {
+		log.Printf("Error while restore operation: %v", err)
+		return err
+	}
+
+	if err := db.relinkOperations(ctx, tx); err != nil {
+		return err
+	}
+
+	after, err := snapshot(ctx, tx, "SELECT * FROM operations WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	if err := db.audit(ctx, tx, "restore", AuditOperation, nil, after); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error commit transaction: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+// восстановить баланс, если его период не занят другим балансом
+func (db *Database) RestoreBalance(ctx context.Context, id int) error {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var conflict bool
+	conflictQuery := `
+	SELECT EXISTS (
+		SELECT 1 FROM balance b
+		JOIN balance d ON d.household_id = b.household_id
+		  AND daterange(d.start_date, d.create_date, '[]') && daterange(b.start_date, b.create_date, '[]')
+		WHERE d.id = $1 AND b.id <> d.id AND b.deleted_at IS NULL
+	)
+	`
+	if err := tx.QueryRow(ctx, conflictQuery, id).Scan(&conflict); err != nil {
+		log.Printf("Error while check balance conflict: %v", err)
+		return err
+	}
+	if conflict {
+		return ErrRestoreConflict
+	}
+
+	commandTag, err := tx.Exec(ctx, "UPDATE balance SET deleted_at = NULL WHERE id = $1 AND household_id = $2 AND deleted_at IS NOT NULL", id, db.household())
+	if err != nil {
+		log.Printf("Error while restore balance: %v", err)
+		return err
+	}
+	if commandTag.RowsAffected() == 0 {
+		log.Printf("Error no deleted balance found with id: %d", id)
+		return ErrEmptyRow
+	}
+
+	if err := db.relinkOperations(ctx, tx); err != nil {
+		return err
+	}
+
+	after, err := snapshot(ctx, tx, "SELECT * FROM balance WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	if err := db.audit(ctx, tx, "restore", AuditBalance, nil, after); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error commit transaction: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+// привязать операции без действующего баланса к балансу, закрывшему их месяц
+func (db *Database) relinkOperations(ctx context.Context, tx pgx.Tx) error {
+	query := `
+	UPDATE operations o SET balance_id = b.id
+	FROM balance b
+	WHERE o.household_id = $1 AND o.deleted_at IS NULL
+	  AND b.household_id = $1 AND b.deleted_at IS NULL
+	  AND o.create_date BETWEEN ` + balancePeriodStart + ` AND b.create_date
+	  AND NOT EXISTS (SELECT 1 FROM balance cur WHERE cur.id = o.balance_id AND cur.deleted_at IS NULL)
+	`
+	if _, err := tx.Exec(ctx, query, db.household()); err != nil {
+		log.Printf("Error while relink operations: %v", err)
+		return err
+	}
+	return nil
+}
+
+// окончательно удалить из корзины активного домохозяйства записи старше заданного числа дней
+func (db *Database) PurgeTrash(ctx context.Context, olderThanDays int) (int64, error) {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	purged, err := db.purgeHousehold(ctx, tx, db.household(), db.user(), olderThanDays)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error commit transaction: %v\n", err)
+		return 0, err
+	}
+	return purged, nil
+}
+
+// окончательно удалить устаревшие записи корзины всех домохозяйств, запускается при старте приложения
+func (db *Database) PurgeExpiredTrash(ctx context.Context, olderThanDays int) (int64, error) {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, "SELECT id FROM households ORDER BY id")
+	if err != nil {
+		log.Printf("Error while get households: %v", err)
+		return 0, err
+	}
+	households, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		log.Printf("Error while scan households: %v", err)
+		return 0, err
+	}
+
+	var total int64
+	for _, householdID := range households {
+		purged, err := db.purgeHousehold(ctx, tx, householdID, "", olderThanDays)
+		if err != nil {
+			return 0, err
+		}
+		total += purged
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error commit transaction: %v\n", err)
+		return 0, err
+	}
+	return total, nil
+}
+
+// очистка корзины одного домохозяйства, удалённое записывается в журнал изменений
+func (db *Database) purgeHousehold(ctx context.Context, tx pgx.Tx, householdID int, username string, olderThanDays int) (int64, error) {
+	const expired = "household_id = $1 AND deleted_at < now() - make_interval(days => $2)"
+
+	entities := []struct {
+		entity, table string
+	}{
+		{AuditOperation, "operations"},
+		{AuditBalance, "balance"},
+		{AuditArticle, "articles"},
+	}
+
+	// снимки берутся до удаления: операции удалённых статей удаляются вместе с ними
+	snapshots := make(map[string][]byte)
+	for _, e := range entities {
+		query := "SELECT * FROM " + e.table + " WHERE " + expired
+		if e.table == "operations" {
+			query = "SELECT * FROM operations WHERE (" + expired + ") OR article_id IN (SELECT id FROM articles WHERE " + expired + ")"
+		}
+		before, err := snapshot(ctx, tx, query, householdID, olderThanDays)
+		if err != nil {
+			return 0, err
+		}
+		snapshots[e.entity] = before
+	}
+
+	var purged int64
+
+	queries := []string{
+		"DELETE FROM operations WHERE (" + expired + ") OR article_id IN (SELECT id FROM articles WHERE " + expired + ")",
+		// операции удаляемых балансов остаются неучтёнными
+		"UPDATE operations SET balance_id = NULL WHERE balance_id IN (SELECT id FROM balance WHERE " + expired + ")",
+		"DELETE FROM balance WHERE " + expired,
+		"DELETE FROM articles WHERE " + expired,
+	}
+	for i, query := range queries {
+		commandTag, err := tx.Exec(ctx, query, householdID, olderThanDays)
+		if err != nil {
+			log.Printf("Error while purge trash: %v", err)
+			return 0, err
+		}
+		if i != 1 {
+			purged += commandTag.RowsAffected()
+		}
+	}
+
+	for _, e := range entities {
+		if snapshots[e.entity] == nil {
+			continue
+		}
+		if err := auditAs(ctx, tx, householdID, username, "purge", e.entity, snapshots[e.entity], nil); err != nil {
+			return 0, err
+		}
+	}
+	return purged, nil
+}
@@ -0,0 +1,7 @@
+package forecast
+
+import "errors"
+
+var (
+	ErrInvalidParams = errors.New("Forecast horizon and history must be positive")
+)
@@ -0,0 +1,187 @@
+package forecast
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/EmptyInsid/db_gui/internal/database"
+	"github.com/EmptyInsid/db_gui/internal/models"
+)
+
+// множитель доверительного интервала: около 80% для нормального распределения
+const bandZ = 1.28
+
+// Params задаёт горизонт прогноза и глубину истории в месяцах
+type Params struct {
+	Months        int
+	HistoryMonths int
+}
+
+// Month - прогноз на один месяц
+type Month struct {
+	Month      time.Time
+	Income     float64
+	Expense    float64
+	Net        float64
+	Cumulative float64 // деньги на руках на конец месяца
+	Low        float64 // нижняя граница доверительного интервала остатка
+	High       float64 // верхняя граница доверительного интервала остатка
+}
+
+// статья в истории: суммы по месяцам истории
+type articleHistory struct {
+	debit  []float64
+	credit []float64
+}
+
+// Build строит прогноз на следующие месяцы начиная с первого числа следующего месяца.
+// Статьи с регулярными платежами прогнозируются по платежам, остальные -
+// по средним значениям того же календарного месяца в истории.
+func Build(db database.Service, ctx context.Context, params Params, today time.Time) ([]Month, error) {
+	if params.Months <= 0 || params.HistoryMonths <= 0 {
+		return nil, ErrInvalidParams
+	}
+
+	firstMonth := monthStart(today).AddDate(0, 1, 0)
+	historyStart := monthStart(today).AddDate(0, -params.HistoryMonths, 0)
+	historyEnd := monthStart(today).AddDate(0, 0, -1)
+
+	totals, err := db.GetMonthlyArticleTotals(ctx, historyStart.Format("2006-01-02"), historyEnd.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	recurring, err := db.GetRecurringItems(ctx)
+	if err != nil {
+		return nil, err
+	}
+	onHand, err := db.GetMoneyOnHand(ctx, today.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+
+	recurringArticles := make(map[string]bool)
+	for _, item := range recurring {
+		recurringArticles[item.ArticleName] = true
+	}
+
+	history := make(map[string]*articleHistory)
+	for _, total := range totals {
+		if recurringArticles[total.ArticleName] {
+			continue
+		}
+		h, ok := history[total.ArticleName]
+		if !ok {
+			h = &articleHistory{
+				debit:  make([]float64, params.HistoryMonths),
+				credit: make([]float64, params.HistoryMonths),
+			}
+			history[total.ArticleName] = h
+		}
+		i := monthsBetween(historyStart, total.Month)
+		if i < 0 || i >= params.HistoryMonths {
+			continue
+		}
+		h.debit[i] += total.TotalDebit
+		h.credit[i] += total.TotalCredit
+	}
+
+	sigma := residualSigma(history, historyStart, params.HistoryMonths)
+
+	months := make([]Month, 0, params.Months)
+	cumulative := onHand
+	for k := 0; k < params.Months; k++ {
+		month := firstMonth.AddDate(0, k, 0)
+
+		var income, expense float64
+		for _, h := range history {
+			income += seasonalAverage(h.debit, historyStart, month.Month())
+			expense += seasonalAverage(h.credit, historyStart, month.Month())
+		}
+		for _, item := range recurring {
+			if recurringApplies(item, month) {
+				income += item.Debit
+				expense += item.Credit
+			}
+		}
+
+		net := income - expense
+		cumulative += net
+		spread := bandZ * sigma * math.Sqrt(float64(k+1))
+
+		months = append(months, Month{
+			Month:      month,
+			Income:     income,
+			Expense:    expense,
+			Net:        net,
+			Cumulative: cumulative,
+			Low:        cumulative - spread,
+			High:       cumulative + spread,
+		})
+	}
+	return months, nil
+}
+
+// среднее по месяцам истории с тем же календарным месяцем,
+// если таких нет - среднее по всей истории
+func seasonalAverage(values []float64, historyStart time.Time, month time.Month) float64 {
+	var sum, all float64
+	var count int
+	for i, value := range values {
+		all += value
+		if historyStart.AddDate(0, i, 0).Month() == month {
+			sum += value
+			count++
+		}
+	}
+	if count > 0 {
+		return sum / float64(count)
+	}
+	if len(values) == 0 {
+		return 0
+	}
+	return all / float64(len(values))
+}
+
+// среднеквадратичное отклонение фактической прибыли месяцев истории от сезонной оценки
+func residualSigma(history map[string]*articleHistory, historyStart time.Time, historyMonths int) float64 {
+	if historyMonths < 2 {
+		return 0
+	}
+
+	var squares float64
+	for i := 0; i < historyMonths; i++ {
+		month := historyStart.AddDate(0, i, 0).Month()
+		var actual, estimate float64
+		for _, h := range history {
+			actual += h.debit[i] - h.credit[i]
+			estimate += seasonalAverage(h.debit, historyStart, month) - seasonalAverage(h.credit, historyStart, month)
+		}
+		squares += (actual - estimate) * (actual - estimate)
+	}
+	return math.Sqrt(squares / float64(historyMonths-1))
+}
+
+// регулярный платёж приходится на месяц, если месяц в его сроке и кратен периодичности
+func recurringApplies(item models.RecurringItem, month time.Time) bool {
+	start := monthStart(item.StartDate)
+	if month.Before(start) {
+		return false
+	}
+	if item.EndDate != nil && month.After(*item.EndDate) {
+		return false
+	}
+	every := item.EveryMonths
+	if every <= 0 {
+		every = 1
+	}
+	return monthsBetween(start, month)%every == 0
+}
+
+func monthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func monthsBetween(from, to time.Time) int {
+	return (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month())
+}
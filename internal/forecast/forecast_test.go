@@ -0,0 +1,197 @@
+package forecast
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/EmptyInsid/db_gui/internal/database"
+	"github.com/EmptyInsid/db_gui/internal/models"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+// сервис с историей по статьям, регулярными платежами и остатком в памяти
+type forecastDB struct {
+	database.Service
+	totals    []database.ArticleMonthTotal
+	recurring []models.RecurringItem
+	onHand    float64
+
+	startDate, endDate, onHandDate string
+}
+
+func (db *forecastDB) GetMonthlyArticleTotals(ctx context.Context, startDate, endDate string) ([]database.ArticleMonthTotal, error) {
+	db.startDate, db.endDate = startDate, endDate
+	return db.totals, nil
+}
+
+func (db *forecastDB) GetRecurringItems(ctx context.Context) ([]models.RecurringItem, error) {
+	return db.recurring, nil
+}
+
+func (db *forecastDB) GetMoneyOnHand(ctx context.Context, date string) (float64, error) {
+	db.onHandDate = date
+	return db.onHand, nil
+}
+
+func TestRecurringApplies(t *testing.T) {
+	end := date(2024, time.June, 10)
+	quarterly := models.RecurringItem{StartDate: date(2024, time.January, 15), EveryMonths: 3}
+	limited := models.RecurringItem{StartDate: date(2024, time.January, 15), EndDate: &end, EveryMonths: 1}
+	monthly := models.RecurringItem{StartDate: date(2024, time.January, 31)}
+
+	tests := []struct {
+		name  string
+		item  models.RecurringItem
+		month time.Time
+		want  bool
+	}{
+		{"месяц начала, хотя день позже первого", quarterly, date(2024, time.January, 1), true},
+		{"до начала", quarterly, date(2023, time.December, 1), false},
+		{"не кратно периодичности", quarterly, date(2024, time.February, 1), false},
+		{"через квартал", quarterly, date(2024, time.April, 1), true},
+		{"через год", quarterly, date(2025, time.January, 1), true},
+		{"месяц окончания", limited, date(2024, time.June, 1), true},
+		{"после окончания", limited, date(2024, time.July, 1), false},
+		{"периодичность не задана - ежемесячно", monthly, date(2024, time.February, 1), true},
+	}
+	for _, tt := range tests {
+		if got := recurringApplies(tt.item, tt.month); got != tt.want {
+			t.Errorf("%s: recurringApplies = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSeasonalAverage(t *testing.T) {
+	historyStart := date(2023, time.November, 1)
+	// ноябрь 2023 - октябрь 2025, два значения на каждый календарный месяц
+	values := make([]float64, 24)
+	for i := range values {
+		values[i] = 100
+	}
+	values[0], values[12] = 50, 150 // ноябри
+	values[1] = 400                 // декабрь 2023
+
+	tests := []struct {
+		name   string
+		values []float64
+		month  time.Month
+		want   float64
+	}{
+		{"среднее ноябрей", values, time.November, 100},
+		{"среднее декабрей", values, time.December, 250},
+		{"обычный месяц", values, time.March, 100},
+		{"месяца нет в истории - среднее всех", []float64{10, 20, 60}, time.May, 30},
+		{"пустая история", nil, time.May, 0},
+	}
+	for _, tt := range tests {
+		if got := seasonalAverage(tt.values, historyStart, tt.month); !near(got, tt.want) {
+			t.Errorf("%s: seasonalAverage = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestResidualSigma(t *testing.T) {
+	historyStart := date(2023, time.January, 1)
+	flat := func(value float64) []float64 {
+		values := make([]float64, 24)
+		for i := range values {
+			values[i] = value
+		}
+		return values
+	}
+
+	seasonal := &articleHistory{debit: flat(100), credit: flat(0)}
+	noisy := &articleHistory{debit: flat(100), credit: flat(0)}
+	noisy.debit[0], noisy.debit[12] = 140, 60 // январи отклоняются от среднего на ±40
+
+	tests := []struct {
+		name    string
+		history map[string]*articleHistory
+		months  int
+		want    float64
+	}{
+		{"история совпадает с сезонной оценкой", map[string]*articleHistory{"a": seasonal}, 24, 0},
+		{"отклонения в январях", map[string]*articleHistory{"a": noisy}, 24, math.Sqrt(2 * 40 * 40 / 23.0)},
+		{"слишком короткая история", map[string]*articleHistory{"a": noisy}, 1, 0},
+	}
+	for _, tt := range tests {
+		if got := residualSigma(tt.history, historyStart, tt.months); !near(got, tt.want) {
+			t.Errorf("%s: residualSigma = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBuild(t *testing.T) {
+	today := date(2024, time.November, 20)
+	historyStart := date(2022, time.November, 1)
+
+	db := &forecastDB{onHand: 5000}
+	for i := 0; i < 24; i++ {
+		month := historyStart.AddDate(0, i, 0)
+		food := 300.0
+		if month.Equal(date(2023, time.December, 1)) {
+			food = 500
+		}
+		db.totals = append(db.totals,
+			database.ArticleMonthTotal{ArticleName: "Зарплата", Month: month, TotalDebit: 1000},
+			database.ArticleMonthTotal{ArticleName: "Еда", Month: month, TotalCredit: food},
+			// статья с регулярным платежом не усредняется по истории
+			database.ArticleMonthTotal{ArticleName: "Аренда", Month: month, TotalCredit: 9999},
+		)
+	}
+	db.recurring = []models.RecurringItem{
+		{ArticleName: "Аренда", Credit: 400, StartDate: date(2024, time.January, 1), EveryMonths: 1},
+		{ArticleName: "Премия", Debit: 600, StartDate: date(2024, time.February, 1), EveryMonths: 12},
+	}
+
+	months, err := Build(db, context.Background(), Params{Months: 3, HistoryMonths: 24}, today)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if db.startDate != "2022-11-01" || db.endDate != "2024-10-31" || db.onHandDate != "2024-11-20" {
+		t.Errorf("history %s - %s, on hand at %s", db.startDate, db.endDate, db.onHandDate)
+	}
+
+	// декабрь: еда в среднем 400 по двум декабрям; февраль - годовая премия
+	sigma := math.Sqrt(2 * 100 * 100 / 23.0)
+	want := []struct {
+		month                            time.Time
+		income, expense, net, cumulative float64
+	}{
+		{date(2024, time.December, 1), 1000, 800, 200, 5200},
+		{date(2025, time.January, 1), 1000, 700, 300, 5500},
+		{date(2025, time.February, 1), 1600, 700, 900, 6400},
+	}
+	if len(months) != len(want) {
+		t.Fatalf("got %d months, want %d", len(months), len(want))
+	}
+	for k, w := range want {
+		got := months[k]
+		if !got.Month.Equal(w.month) || !near(got.Income, w.income) || !near(got.Expense, w.expense) ||
+			!near(got.Net, w.net) || !near(got.Cumulative, w.cumulative) {
+			t.Errorf("month %d: got %+v, want %+v", k, got, w)
+		}
+		spread := bandZ * sigma * math.Sqrt(float64(k+1))
+		if !near(got.Low, w.cumulative-spread) || !near(got.High, w.cumulative+spread) {
+			t.Errorf("month %d: band %v - %v, want ±%v", k, got.Low, got.High, spread)
+		}
+	}
+}
+
+func TestBuildInvalidParams(t *testing.T) {
+	for _, params := range []Params{{Months: 0, HistoryMonths: 12}, {Months: 3, HistoryMonths: 0}} {
+		if _, err := Build(&forecastDB{}, context.Background(), params, date(2024, time.November, 20)); err != ErrInvalidParams {
+			t.Errorf("Build(%+v) error = %v, want ErrInvalidParams", params, err)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package gui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/EmptyInsid/db_gui/internal/database"
+	"github.com/EmptyInsid/db_gui/internal/models"
+)
+
+// названия сущностей журнала изменений
+var auditEntities = map[string]string{
+	database.AuditArticle:   "Статья",
+	database.AuditOperation: "Операция",
+	database.AuditBalance:   "Баланс",
+	database.AuditHousehold: "Бюджет",
+	database.AuditRecurring: "Регулярный платёж",
+}
+
+func TranslateEntity(entity string) string {
+	if name, ok := auditEntities[entity]; ok {
+		return name
+	}
+	return entity
+}
+
+// журнал изменений с фильтрами по пользователю, объекту и периоду
+func AuditViewer(w fyne.Window, db database.Service) (*container.Split, error) {
+	title := MadeTitle("Журнал изменений")
+
+	username := widget.NewEntry()
+	username.SetPlaceHolder("все")
+
+	entity := widget.NewSelect([]string{"Все", "Статья", "Операция", "Баланс", "Бюджет", "Регулярный платёж"}, nil)
+	entity.SetSelected("Все")
+
+	startDate, endDate := MadeDateFields()
+
+	filter := func() database.AuditFilter {
+		f := database.AuditFilter{
+			Username:  username.Text,
+			StartDate: startDate.Text,
+			EndDate:   endDate.Text,
+		}
+		for key, name := range auditEntities {
+			if name == entity.Selected {
+				f.Entity = key
+			}
+		}
+		return f
+	}
+
+	table, err := AuditTable(w, db, filter())
+	if err != nil {
+		return nil, err
+	}
+	tableContainer := container.NewStack(table)
+
+	showButton := widget.NewButton("Показать", func() {
+		f := filter()
+		if f.StartDate != "" && f.EndDate != "" {
+			if err := CompareDate(f.StartDate, f.EndDate); err != nil {
+				dialog.ShowError(ErrEndLessStart, w)
+				return
+			}
+		}
+
+		newTable, err := AuditTable(w, db, f)
+		if err != nil {
+			dialog.ShowError(ErrGetAudit, w)
+			return
+		}
+		tableContainer.Objects = []fyne.CanvasObject{newTable}
+		tableContainer.Refresh()
+	})
+
+	inputContainer := container.NewVBox(
+		title,
+		widget.NewLabel("Пользователь:"),
+		username,
+		widget.NewLabel("Объект:"),
+		entity,
+		widget.NewLabel("Начальная дата:"),
+		startDate,
+		widget.NewLabel("Конечная дата:"),
+		endDate,
+		showButton,
+	)
+
+	mainContent := container.NewHSplit(tableContainer, inputContainer)
+	mainContent.SetOffset(0.7) // Устанавливает пропорцию (70% для таблицы, 30% для правой панели)
+
+	return mainContent, nil
+}
+
+// подробности записи журнала: состояние до и после изменения
+func ShowAuditEntry(w fyne.Window, entry models.AuditEntry) {
+	before := widget.NewLabel(prettyJSON(entry.Before))
+	before.TextStyle = fyne.TextStyle{Monospace: true}
+	after := widget.NewLabel(prettyJSON(entry.After))
+	after.TextStyle = fyne.TextStyle{Monospace: true}
+
+	content := container.NewAppTabs(
+		container.NewTabItem("До", container.NewScroll(before)),
+		container.NewTabItem("После", container.NewScroll(after)),
+	)
+
+	title := fmt.Sprintf("%s: %s (%s, %s)",
+		TranslateEntity(entry.Entity), entry.Action, entry.Username, entry.Date.Format("2006-01-02 15:04:05"))
+
+	d := dialog.NewCustom(title, "Закрыть", content, w)
+	d.Resize(fyne.NewSize(600, 400))
+	d.Show()
+}
+
+func prettyJSON(data string) string {
+	if data == "" {
+		return "-"
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, []byte(data), "", "  "); err != nil {
+		return data
+	}
+	return out.String()
+}
+
+// обрезать длинный текст для ячейки таблицы
+func shortText(text string, size int) string {
+	runes := []rune(text)
+	if len(runes) <= size {
+		return text
+	}
+	return string(runes[:size]) + "..."
+}
@@ -2,6 +2,7 @@ package gui
 
 import (
 	"context"
+	"errors"
 	"log"
 
 	"fyne.io/fyne/v2"
@@ -44,14 +45,25 @@ func AuthForm(myApp fyne.App, w fyne.Window, db database.Service) {
 
 		ctx := context.Background()
 
-		_, role, err := auth.AuthenticateUser(db, ctx, login.Text, password.Text)
+		username, role, err := auth.AuthenticateUser(db, ctx, login.Text, password.Text)
+		if errors.Is(err, auth.ErrSecondFactorRequired) {
+			SecondFactorForm(myApp, w, db, username, role)
+			return
+		}
 		if err != nil {
 			log.Printf("Failed to fetch user names: %v", err)
-			dialog.ShowError(ErrAuth, w)
+			switch {
+			case errors.Is(err, auth.ErrAccountLocked):
+				dialog.ShowError(ErrAuthLocked, w)
+			case errors.Is(err, auth.ErrTooManyAttempts):
+				dialog.ShowError(ErrAuthBackoff, w)
+			default:
+				dialog.ShowError(ErrAuth, w)
+			}
 			return
 		}
 
-		MainWindow(myApp, w, db, role)
+		EnterApp(myApp, w, db, username, role)
 	}
 
 	form.CancelText = "Отмена"
@@ -62,3 +74,55 @@ func AuthForm(myApp fyne.App, w fyne.Window, db database.Service) {
 
 	w.SetContent(container.NewCenter(form))
 }
+
+// второй шаг входа при включённой двухфакторной аутентификации
+func SecondFactorForm(myApp fyne.App, w fyne.Window, db database.Service, username, role string) {
+	code := widget.NewEntry()
+	code.SetPlaceHolder("123456")
+
+	form := widget.NewForm(
+		widget.NewFormItem("Код", code),
+	)
+	form.Items[0].HintText = "Код из приложения или код восстановления"
+
+	form.SubmitText = "Подтвердить"
+	form.OnSubmit = func() {
+		log.Printf("User %s is entering second factor", username)
+
+		ctx := context.Background()
+
+		if err := auth.VerifySecondFactor(db, ctx, username, code.Text); err != nil {
+			log.Printf("Failed to verify second factor: %v", err)
+			switch {
+			case errors.Is(err, auth.ErrAccountLocked):
+				dialog.ShowError(ErrAuthLocked, w)
+				LoginMenu(myApp, w, db)
+			case errors.Is(err, auth.ErrTooManyAttempts):
+				dialog.ShowError(ErrAuthBackoff, w)
+			default:
+				dialog.ShowError(ErrAuthCode, w)
+			}
+			return
+		}
+
+		EnterApp(myApp, w, db, username, role)
+	}
+
+	form.CancelText = "Отмена"
+	form.OnCancel = func() {
+		log.Println("User clicked 'Отмена'")
+		LoginMenu(myApp, w, db)
+	}
+
+	w.SetContent(container.NewCenter(form))
+}
+
+// открыть сессию и главное окно после успешного входа
+func EnterApp(myApp fyne.App, w fyne.Window, db database.Service, username, role string) {
+	if err := StartSession(db, username); err != nil {
+		log.Printf("Failed to start session: %v", err)
+		dialog.ShowError(ErrUseHousehold, w)
+		return
+	}
+	MainWindow(myApp, w, db, username, role)
+}
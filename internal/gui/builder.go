@@ -0,0 +1,237 @@
+package gui
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/EmptyInsid/db_gui/internal/database"
+	"github.com/EmptyInsid/db_gui/internal/report"
+)
+
+// подпись шага в форме конструктора и обратно
+func builderLabels(keys []string, columns map[string]report.Column) []string {
+	labels := make([]string, len(keys))
+	for i, key := range keys {
+		labels[i] = columns[key].Header
+	}
+	return labels
+}
+
+func builderKeys(labels []string, keys []string, columns map[string]report.Column) []string {
+	var selected []string
+	for _, label := range labels {
+		for _, key := range keys {
+			if columns[key].Header == label {
+				selected = append(selected, key)
+			}
+		}
+	}
+	return selected
+}
+
+// MainReportBuilder - конструктор отчётов: измерения, показатели, фильтры и сохранённые отчёты пользователя
+func MainReportBuilder(w fyne.Window, db database.Service) (*fyne.Container, error) {
+	ctx := context.Background()
+
+	dimensions := widget.NewCheckGroup(builderLabels(report.Dimensions, report.DimensionColumns), nil)
+	measures := widget.NewCheckGroup(builderLabels(report.Measures, report.MeasureColumns), nil)
+	startDate, endDate := MadeDateFields()
+	periodSelect := MadePresetSelect(func(preset string) {
+		if preset == "" {
+			return
+		}
+		start, end, err := report.PresetPeriod(preset, time.Now())
+		if err != nil {
+			dialog.ShowError(ErrParseDate, w)
+			return
+		}
+		startDate.SetText(start.Format("2006-01-02"))
+		endDate.SetText(end.Format("2006-01-02"))
+	})
+	articlesContainer, addArticleButton, delArticleButton, err := MadeArticlesButton(db)
+	if err != nil {
+		return nil, err
+	}
+	minAmount := widget.NewEntry()
+	minAmount.SetPlaceHolder("от")
+	maxAmount := widget.NewEntry()
+	maxAmount.SetPlaceHolder("до")
+
+	// параметры из формы; пустые фильтры не ограничивают выборку
+	readSpec := func() (database.AdhocSpec, error) {
+		spec := database.AdhocSpec{
+			Dimensions: builderKeys(dimensions.Selected, report.Dimensions, report.DimensionColumns),
+			Measures:   builderKeys(measures.Selected, report.Measures, report.MeasureColumns),
+			StartDate:  strings.TrimSpace(startDate.Text),
+			EndDate:    strings.TrimSpace(endDate.Text),
+		}
+		if spec.StartDate != "" && spec.EndDate != "" {
+			if err := CompareDate(spec.StartDate, spec.EndDate); err != nil {
+				return spec, ErrEndLessStart
+			}
+		}
+		spec.Articles = LoadArticles(articlesContainer)
+		for _, bound := range []struct {
+			entry  *widget.Entry
+			target **float64
+		}{{minAmount, &spec.MinAmount}, {maxAmount, &spec.MaxAmount}} {
+			if bound.entry.Text == "" {
+				continue
+			}
+			value, err := strconv.ParseFloat(bound.entry.Text, 64)
+			if err != nil {
+				return spec, ErrParseAmount
+			}
+			*bound.target = &value
+		}
+		if err := spec.Validate(); err != nil {
+			return spec, ErrBuilderSpec
+		}
+		return spec, nil
+	}
+
+	// заполнить форму сохранённым отчётом
+	applySpec := func(spec database.AdhocSpec) {
+		dimensions.SetSelected(builderLabels(spec.Dimensions, report.DimensionColumns))
+		measures.SetSelected(builderLabels(spec.Measures, report.MeasureColumns))
+		startDate.SetText(spec.StartDate)
+		endDate.SetText(spec.EndDate)
+
+		articlesContainer.Objects = articlesContainer.Objects[:1]
+		first := articlesContainer.Objects[0].(*widget.Select)
+		first.ClearSelected()
+		for i, article := range spec.Articles {
+			if i > 0 {
+				addArticleButton.OnTapped()
+			}
+			articlesContainer.Objects[i].(*widget.Select).SetSelected(article)
+		}
+		articlesContainer.Refresh()
+
+		minAmount.SetText(formatOptionalFloat(spec.MinAmount))
+		maxAmount.SetText(formatOptionalFloat(spec.MaxAmount))
+	}
+
+	reportName := widget.NewEntry()
+	reportName.SetPlaceHolder("название отчёта")
+
+	var saved []database.SavedReport
+	savedSelect := widget.NewSelect(nil, func(name string) {
+		for _, item := range saved {
+			if item.Name == name {
+				reportName.SetText(item.Name)
+				applySpec(item.Spec)
+				return
+			}
+		}
+	})
+	loadSaved := func() error {
+		var err error
+		saved, err = db.GetSavedReports(ctx)
+		if err != nil {
+			return err
+		}
+		names := make([]string, len(saved))
+		for i, item := range saved {
+			names[i] = item.Name
+		}
+		savedSelect.Options = names
+		savedSelect.ClearSelected()
+		savedSelect.Refresh()
+		return nil
+	}
+	if err := loadSaved(); err != nil {
+		return nil, err
+	}
+
+	saveSpecButton := widget.NewButton("Сохранить отчёт", func() {
+		name := strings.TrimSpace(reportName.Text)
+		if name == "" {
+			dialog.ShowError(ErrEmptyReportName, w)
+			return
+		}
+		spec, err := readSpec()
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if err := db.SaveReport(ctx, name, spec); err != nil {
+			dialog.ShowError(ErrSaveReport, w)
+			return
+		}
+		if err := loadSaved(); err != nil {
+			dialog.ShowError(ErrGetSavedReports, w)
+			return
+		}
+		dialog.ShowInformation("Конструктор отчётов", "Отчёт сохранён!", w)
+	})
+
+	deleteSpecButton := widget.NewButton("Удалить отчёт", func() {
+		for _, item := range saved {
+			if item.Name != savedSelect.Selected {
+				continue
+			}
+			if err := db.DeleteSavedReport(ctx, item.ID); err != nil {
+				dialog.ShowError(ErrDelSavedReport, w)
+				return
+			}
+			if err := loadSaved(); err != nil {
+				dialog.ShowError(ErrGetSavedReports, w)
+				return
+			}
+			dialog.ShowInformation("Конструктор отчётов", "Отчёт удалён", w)
+			return
+		}
+		dialog.ShowError(ErrEmptyReportName, w)
+	})
+
+	form := container.NewVBox(
+		MadeTitle("Конструктор отчётов."),
+		widget.NewLabel("Сохранённые отчёты:"),
+		savedSelect,
+		deleteSpecButton,
+		widget.NewLabel("Группировать по:"),
+		dimensions,
+		widget.NewLabel("Показатели:"),
+		measures,
+		widget.NewLabel("Период (необязательно):"),
+		periodSelect,
+		startDate,
+		endDate,
+		widget.NewLabel("Статьи (необязательно):"),
+		articlesContainer,
+		addArticleButton,
+		delArticleButton,
+		widget.NewLabel("Сумма операции (необязательно):"),
+		container.NewAdaptiveGrid(2, minAmount, maxAmount),
+		widget.NewLabel("Название:"),
+		reportName,
+		saveSpecButton,
+	)
+
+	pane := ReportPane(w, db, form, func() (*report.Definition, report.Values, error) {
+		spec, err := readSpec()
+		if err != nil {
+			return nil, nil, err
+		}
+		name := strings.TrimSpace(reportName.Text)
+		if name == "" {
+			name = "Отчёт конструктора"
+		}
+		return report.Adhoc(name, spec), nil, nil
+	})
+	return container.NewStack(pane), nil
+}
+
+func formatOptionalFloat(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'f', -1, 64)
+}
@@ -0,0 +1,291 @@
+package gui
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/EmptyInsid/db_gui/internal/report"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgimg"
+)
+
+// названия видов графика в переключателе
+var chartKindNames = map[report.ChartKind]string{
+	report.ChartLine:        "Линии",
+	report.ChartBar:         "Столбцы",
+	report.ChartStackedArea: "Области",
+	report.ChartPie:         "Круговая",
+	report.ChartDonut:       "Кольцевая",
+}
+
+// виды графика, между которыми можно переключаться, по виду из описания отчёта
+func chartKindsFor(kind report.ChartKind) []report.ChartKind {
+	if isPie(kind) {
+		return []report.ChartKind{report.ChartPie, report.ChartDonut, report.ChartBar}
+	}
+	return []report.ChartKind{report.ChartLine, report.ChartBar, report.ChartStackedArea}
+}
+
+func isPie(kind report.ChartKind) bool {
+	return kind == report.ChartPie || kind == report.ChartDonut
+}
+
+// InteractiveChart - график отчёта с переключением вида, выбором серий и подсказками при наведении
+func InteractiveChart(def *report.Definition, result *report.Result) fyne.CanvasObject {
+	view := NewChartView(def, result)
+	chartKinds := chartKindsFor(def.Chart.Kind)
+
+	kindNames := make([]string, len(chartKinds))
+	for i, kind := range chartKinds {
+		kindNames[i] = chartKindNames[kind]
+	}
+	kinds := widget.NewRadioGroup(kindNames, func(name string) {
+		for _, kind := range chartKinds {
+			if chartKindNames[kind] == name {
+				view.SetKind(kind)
+			}
+		}
+	})
+	kinds.Horizontal = true
+	kinds.Required = true
+	kinds.SetSelected(chartKindNames[def.Chart.Kind])
+
+	seriesNames := make([]string, len(def.Chart.Series))
+	for i, series := range def.Chart.Series {
+		seriesNames[i] = series.Name
+	}
+	series := widget.NewCheckGroup(seriesNames, func(selected []string) {
+		hidden := make(map[int]bool)
+		for i, name := range seriesNames {
+			hidden[i] = true
+			for _, s := range selected {
+				if s == name {
+					hidden[i] = false
+				}
+			}
+		}
+		view.SetHidden(hidden)
+	})
+	series.Horizontal = true
+	series.SetSelected(seriesNames)
+
+	controls := container.NewVBox(kinds)
+	if len(seriesNames) > 1 {
+		controls.Add(series)
+	}
+	return container.NewBorder(controls, nil, nil, nil, view)
+}
+
+// ChartView перерисовывает график под свой размер и показывает значения точки под курсором
+type ChartView struct {
+	widget.BaseWidget
+
+	def     *report.Definition
+	result  *report.Result
+	options report.PlotOptions
+
+	image   *canvas.Image
+	tip     *widget.Label
+	tipBox  *fyne.Container
+	size    fyne.Size
+	pointsX []float32 // координаты точек по X в пикселях изображения
+
+	// для круговой диаграммы: сектора, центр и радиус в пикселях изображения
+	slices    []report.PieSlice
+	pieCenter fyne.Position
+	pieRadius float32
+}
+
+var _ desktop.Hoverable = (*ChartView)(nil)
+
+func NewChartView(def *report.Definition, result *report.Result) *ChartView {
+	view := &ChartView{
+		def:     def,
+		result:  result,
+		options: report.PlotOptions{Kind: def.Chart.Kind, Hidden: map[int]bool{}},
+		image:   canvas.NewImageFromImage(nil),
+		tip:     widget.NewLabel(""),
+	}
+	view.image.FillMode = canvas.ImageFillStretch
+
+	background := canvas.NewRectangle(theme.Color(theme.ColorNameOverlayBackground))
+	background.StrokeColor = theme.Color(theme.ColorNameSeparator)
+	background.StrokeWidth = 1
+	view.tipBox = container.NewStack(background, view.tip)
+	view.tipBox.Hide()
+
+	view.ExtendBaseWidget(view)
+	return view
+}
+
+func (c *ChartView) SetKind(kind report.ChartKind) {
+	c.options.Kind = kind
+	c.redraw()
+}
+
+func (c *ChartView) SetHidden(hidden map[int]bool) {
+	c.options.Hidden = hidden
+	c.redraw()
+}
+
+func (c *ChartView) CreateRenderer() fyne.WidgetRenderer {
+	return &chartViewRenderer{view: c}
+}
+
+func (c *ChartView) MinSize() fyne.Size {
+	return fyne.NewSize(300, 200)
+}
+
+// перерисовать изображение графика в текущем размере
+func (c *ChartView) redraw() {
+	c.tipBox.Hide()
+	if c.size.Width < 1 || c.size.Height < 1 {
+		return
+	}
+
+	p, err := report.PlotWith(c.def, c.result, c.options)
+	if err != nil || p == nil {
+		log.Printf("Error while plot report %s: %v", c.def.ID, err)
+		c.image.Image = nil
+		c.image.Refresh()
+		return
+	}
+
+	// при 72 точках на дюйм единица vg совпадает с пикселем изображения
+	width, height := vg.Length(c.size.Width), vg.Length(c.size.Height)
+	img := vgimg.NewWith(vgimg.UseWH(width, height), vgimg.UseDPI(72))
+	dc := draw.New(img)
+	p.Draw(dc)
+
+	dataCanvas := p.DataCanvas(dc)
+	c.pointsX, c.slices = nil, nil
+	if isPie(c.options.Kind) {
+		// у изображения ось Y направлена вниз, у vg - вверх
+		center, radius := report.PieGeometry(dataCanvas)
+		c.pieCenter = fyne.NewPos(float32(center.X), c.size.Height-float32(center.Y))
+		c.pieRadius = float32(radius)
+		c.slices = report.ChartSlices(c.def, c.result)
+	} else {
+		toX, _ := p.Transforms(&dataCanvas)
+		c.pointsX = make([]float32, len(c.result.Rows))
+		for i := range c.result.Rows {
+			c.pointsX[i] = float32(toX(float64(i)))
+		}
+	}
+
+	c.image.Image = img.Image()
+	c.image.Refresh()
+}
+
+func (c *ChartView) MouseIn(event *desktop.MouseEvent) {
+	c.MouseMoved(event)
+}
+
+// подсказка со значениями видимых серий в ближайшей по X точке,
+// для круговой - с сектором под курсором
+func (c *ChartView) MouseMoved(event *desktop.MouseEvent) {
+	if isPie(c.options.Kind) {
+		c.pieTip(event.Position)
+		return
+	}
+
+	index := -1
+	best := float32(math.MaxFloat32)
+	for i, x := range c.pointsX {
+		if d := float32(math.Abs(float64(x - event.Position.X))); d < best {
+			index, best = i, d
+		}
+	}
+	if index < 0 {
+		c.tipBox.Hide()
+		return
+	}
+
+	lines := []string{report.Labels(c.def, c.result)[index]}
+	for i, series := range c.def.Chart.Series {
+		if c.options.Hidden[i] {
+			continue
+		}
+		value := c.result.Rows[index][series.Column]
+		lines = append(lines, fmt.Sprintf("%s: %s", series.Name, report.Format(c.def.Columns[series.Column], value)))
+	}
+	c.showTip(strings.Join(lines, "\n"), event.Position)
+}
+
+func (c *ChartView) pieTip(pos fyne.Position) {
+	dx := float64(pos.X - c.pieCenter.X)
+	dy := float64(c.pieCenter.Y - pos.Y)
+	distance := float32(math.Hypot(dx, dy))
+	if distance > c.pieRadius || (c.options.Kind == report.ChartDonut && distance < c.pieRadius*report.DonutHole) {
+		c.tipBox.Hide()
+		return
+	}
+
+	// угол от верхней точки по часовой стрелке, как у секторов
+	angle := math.Mod(math.Pi/2-math.Atan2(dy, dx)+2*math.Pi, 2*math.Pi)
+	for _, slice := range c.slices {
+		if angle >= slice.Start && angle < slice.End {
+			c.showTip(fmt.Sprintf("%s\n%.2f%%", slice.Label, slice.Share), pos)
+			return
+		}
+	}
+	c.tipBox.Hide()
+}
+
+func (c *ChartView) showTip(text string, position fyne.Position) {
+	c.tip.SetText(text)
+
+	tipSize := c.tip.MinSize()
+	c.tipBox.Resize(tipSize)
+
+	// подсказка справа от курсора, у правого края - слева
+	pos := position.Add(fyne.NewPos(12, 12))
+	if pos.X+tipSize.Width > c.size.Width {
+		pos.X = position.X - tipSize.Width - 12
+	}
+	if pos.Y+tipSize.Height > c.size.Height {
+		pos.Y = c.size.Height - tipSize.Height
+	}
+	c.tipBox.Move(pos)
+	c.tipBox.Show()
+}
+
+func (c *ChartView) MouseOut() {
+	c.tipBox.Hide()
+}
+
+type chartViewRenderer struct {
+	view *ChartView
+}
+
+func (r *chartViewRenderer) Layout(size fyne.Size) {
+	r.view.image.Resize(size)
+	r.view.image.Move(fyne.NewPos(0, 0))
+	if size != r.view.size {
+		r.view.size = size
+		r.view.redraw()
+	}
+}
+
+func (r *chartViewRenderer) MinSize() fyne.Size {
+	return r.view.MinSize()
+}
+
+func (r *chartViewRenderer) Refresh() {
+	canvas.Refresh(r.view)
+}
+
+func (r *chartViewRenderer) Objects() []fyne.CanvasObject {
+	return []fyne.CanvasObject{r.view.image, r.view.tipBox}
+}
+
+func (r *chartViewRenderer) Destroy() {}
@@ -0,0 +1,123 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"image/color"
+	"strconv"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/EmptyInsid/db_gui/internal/database"
+)
+
+// мастер пакетного закрытия периодов
+func MainClosing(w fyne.Window, db database.Service, role string) (*container.Split, error) {
+	table := ClosingTable(nil)
+	tableContainer := container.NewStack(table)
+
+	editor := container.NewVBox(MadeTitle("Закрытие периодов"), canvas.NewLine(color.White), WinCloseBalances(w, db, tableContainer))
+
+	mainContent := container.NewHSplit(tableContainer, editor)
+	mainContent.SetOffset(0.7)
+	return mainContent, nil
+}
+
+func WinCloseBalances(w fyne.Window, db database.Service, tableContainer *fyne.Container) *fyne.Container {
+	ctx := context.Background()
+
+	period := MadeSelectPeriod()
+	// произвольные периоды закрываются только вручную
+	period.Options = period.Options[:len(period.Options)-1]
+
+	_, untilDate := MadeDateFields()
+	untilDate.SetText(time.Now().Format("2006-01-02"))
+
+	minProf := widget.NewEntry()
+	minProf.SetPlaceHolder("100.0")
+
+	cont := container.NewAdaptiveGrid(
+		1,
+		widget.NewLabel("Тип периода:"), period,
+		widget.NewLabel("Закрыть по дату:"), untilDate,
+		widget.NewLabel("Минимальный профит:"), minProf,
+	)
+
+	summary := widget.NewLabel("")
+	summary.Wrapping = fyne.TextWrapWord
+
+	run := func(dryRun bool) {
+		if _, err := time.Parse("2006-01-02", untilDate.Text); err != nil {
+			dialog.ShowError(ErrParseDate, w)
+			return
+		}
+		floatMinProf, err := strconv.ParseFloat(minProf.Text, 64)
+		if err != nil {
+			dialog.ShowError(ErrParseDebit, w)
+			return
+		}
+
+		periods, err := db.CloseBalances(ctx, SelectedPeriod(period), untilDate.Text, floatMinProf, dryRun)
+		tableContainer.Objects = []fyne.CanvasObject{ClosingTable(periods)}
+		tableContainer.Refresh()
+		if err != nil {
+			dialog.ShowError(ErrCloseBalances, w)
+			return
+		}
+		summary.SetText(closingSummary(periods, dryRun))
+	}
+
+	btnPreview := widget.NewButton("Предпросмотр", func() { run(true) })
+	btnClose := widget.NewButton("Закрыть периоды", func() {
+		dialog.ShowConfirm(
+			"Закрыть периоды",
+			"Балансы будут сформированы по порядку до первого периода с прибылью ниже порога. Продолжить?",
+			func(ok bool) {
+				if ok {
+					run(false)
+				}
+			},
+			w)
+	})
+
+	return container.NewVBox(cont, btnPreview, btnClose, summary)
+}
+
+func closingSummary(periods []database.ClosingPeriod, dryRun bool) string {
+	if len(periods) == 0 {
+		return "Незакрытых периодов нет."
+	}
+
+	var passed, failed, closed int
+	for _, period := range periods {
+		switch {
+		case period.Closed:
+			closed++
+		case period.Passes:
+			passed++
+		default:
+			failed++
+		}
+	}
+
+	if dryRun {
+		return fmt.Sprintf("Периодов: %d\nПройдут порог: %d\nНиже порога: %d", len(periods), passed, failed)
+	}
+	return fmt.Sprintf("Периодов: %d\nЗакрыто: %d\nНе закрыто: %d", len(periods), closed, len(periods)-closed)
+}
+
+// состояние периода в мастере закрытия
+func ClosingStatus(period database.ClosingPeriod) string {
+	switch {
+	case period.Closed:
+		return "закрыт"
+	case period.Passes:
+		return "пройдёт порог"
+	default:
+		return "ниже порога"
+	}
+}
@@ -0,0 +1,289 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"image/color"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
+	"github.com/EmptyInsid/db_gui/internal/database"
+	"github.com/EmptyInsid/db_gui/internal/models"
+	"github.com/EmptyInsid/db_gui/internal/report"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/vgimg"
+)
+
+// как часто главная проверяет, изменились ли данные
+const dashboardPollInterval = 5 * time.Second
+
+// сколько месяцев показывает график прибыли
+const dashboardMonths = 12
+
+// данные плиток главной
+type dashboardData struct {
+	month       time.Time
+	income      float64
+	expense     float64
+	topExpenses []database.ArticleTotalMoney
+	unaccounted []database.ArticleTotalMoney
+	lastBalance *models.Balance
+	profits     []float64 // прибыль по месяцам, последний - текущий
+}
+
+func loadDashboard(ctx context.Context, db database.Service, today time.Time) (dashboardData, error) {
+	data := dashboardData{month: time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)}
+
+	first := data.month.AddDate(0, 1-dashboardMonths, 0)
+	last := data.month.AddDate(0, 1, -1)
+	totals, err := db.GetMonthlyArticleTotals(ctx, first.Format("2006-01-02"), last.Format("2006-01-02"))
+	if err != nil {
+		return data, err
+	}
+
+	data.profits = make([]float64, dashboardMonths)
+	monthExpenses := make(map[string]float64)
+	for _, total := range totals {
+		index := (total.Month.Year()-first.Year())*12 + int(total.Month.Month()) - int(first.Month())
+		if index < 0 || index >= dashboardMonths {
+			continue
+		}
+		data.profits[index] += total.TotalDebit - total.TotalCredit
+		if index == dashboardMonths-1 {
+			data.income += total.TotalDebit
+			data.expense += total.TotalCredit
+			monthExpenses[total.ArticleName] += total.TotalCredit
+		}
+	}
+
+	for name, credit := range monthExpenses {
+		if credit > 0 {
+			data.topExpenses = append(data.topExpenses, database.ArticleTotalMoney{ArticleName: name, TotalCredit: credit})
+		}
+	}
+	sort.Slice(data.topExpenses, func(i, j int) bool {
+		return data.topExpenses[i].TotalCredit > data.topExpenses[j].TotalCredit
+	})
+	if len(data.topExpenses) > 5 {
+		data.topExpenses = data.topExpenses[:5]
+	}
+
+	unaccounted, err := db.GetViewUnaccountedOpertions(ctx)
+	if err != nil {
+		return data, err
+	}
+	for _, article := range unaccounted {
+		if article.TotalDebit != 0 || article.TotalCredit != 0 {
+			data.unaccounted = append(data.unaccounted, article)
+		}
+	}
+
+	balances, err := db.GetAllBalances(ctx)
+	if err != nil {
+		return data, err
+	}
+	for i := range balances {
+		if data.lastBalance == nil || balances[i].Date.After(data.lastBalance.Date) {
+			data.lastBalance = &balances[i]
+		}
+	}
+
+	return data, nil
+}
+
+// MainDashboard - главная после входа: плитки со сводкой, по нажатию открывается нужный раздел.
+// Плитки перечитываются, когда в бюджете появляются новые изменения
+func MainDashboard(w fyne.Window, db database.Service, role string) (*fyne.Container, error) {
+	ctx := context.Background()
+
+	data, err := loadDashboard(ctx, db, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	version, err := db.GetDataVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	open := func(content func() (fyne.CanvasObject, error), fail error) func() {
+		return func() {
+			cont, err := content()
+			if err != nil {
+				dialog.ShowError(fail, w)
+				return
+			}
+			w.SetContent(cont)
+		}
+	}
+	openReport := func(def *report.Definition) func() {
+		return open(func() (fyne.CanvasObject, error) { return MainReport(w, db, role, def) }, ErrReport)
+	}
+
+	monthTile := newDashboardTile("", open(func() (fyne.CanvasObject, error) { return MainDir(w, db, role) }, ErrShowDir))
+	topTile := newDashboardTile("Топ-5 расходов месяца", openReport(report.FinancialPercentages))
+	unaccountedTile := newDashboardTile("Операции вне балансов", open(func() (fyne.CanvasObject, error) { return MainUnaccounted(w, db) }, ErrShowUnaccounted))
+	balanceTile := newDashboardTile("Последний баланс", open(func() (fyne.CanvasObject, error) { return MainJorney(w, db, role) }, ErrShowJorney))
+	profitTile := newDashboardTile(fmt.Sprintf("Прибыль за %d месяцев", dashboardMonths), openReport(report.TotalProfitDate))
+
+	fill := func(data dashboardData) {
+		monthTile.SetTitle("Текущий месяц: " + data.month.Format("2006-01"))
+		monthTile.SetContent(widget.NewLabel(fmt.Sprintf("Доход: %.2f\nРасход: %.2f\nПрибыль: %.2f",
+			data.income, data.expense, data.income-data.expense)))
+
+		topTile.SetContent(widget.NewLabel(dashboardTopText(data.topExpenses)))
+		unaccountedTile.SetContent(widget.NewLabel(dashboardUnaccountedText(data.unaccounted)))
+		balanceTile.SetContent(widget.NewLabel(dashboardBalanceText(data.lastBalance)))
+
+		spark, err := sparkline(data.profits)
+		if err != nil {
+			log.Printf("Error while draw profit sparkline: %v", err)
+			profitTile.SetContent(widget.NewLabel("-"))
+			return
+		}
+		var total float64
+		for _, profit := range data.profits {
+			total += profit
+		}
+		profitTile.SetContent(container.NewBorder(nil, widget.NewLabel(fmt.Sprintf("Итого: %.2f", total)), nil, nil, spark))
+	}
+	fill(data)
+
+	root := container.NewBorder(
+		MadeTitle("Главная"), nil, nil, nil,
+		container.NewGridWithColumns(3, monthTile, topTile, unaccountedTile, balanceTile, profitTile),
+	)
+
+	// перечитать плитки при новых изменениях, пока главная на экране
+	go func() {
+		ticker := time.NewTicker(dashboardPollInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if w.Content() != fyne.CanvasObject(root) {
+				return
+			}
+			current, err := db.GetDataVersion(ctx)
+			if err != nil || current == version {
+				continue
+			}
+			data, err := loadDashboard(ctx, db, time.Now())
+			if err != nil {
+				log.Printf("Error while refresh dashboard: %v", err)
+				continue
+			}
+			version = current
+			fill(data)
+		}
+	}()
+
+	return root, nil
+}
+
+// MainUnaccounted - суммы по статьям операций, не вошедших ни в один баланс
+func MainUnaccounted(w fyne.Window, db database.Service) (*fyne.Container, error) {
+	table, err := UnaccountedOpertionsMoneyTable(db)
+	if err != nil {
+		return nil, err
+	}
+	return container.NewBorder(MadeTitle("Операции вне балансов"), nil, nil, nil, table), nil
+}
+
+func dashboardTopText(top []database.ArticleTotalMoney) string {
+	if len(top) == 0 {
+		return "Расходов в этом месяце нет"
+	}
+	lines := make([]string, len(top))
+	for i, article := range top {
+		lines[i] = fmt.Sprintf("%d. %s: %.2f", i+1, article.ArticleName, article.TotalCredit)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func dashboardUnaccountedText(unaccounted []database.ArticleTotalMoney) string {
+	if len(unaccounted) == 0 {
+		return "Все операции учтены в балансах"
+	}
+	var debit, credit float64
+	for _, article := range unaccounted {
+		debit += article.TotalDebit
+		credit += article.TotalCredit
+	}
+	return fmt.Sprintf("Статей: %d\nДоход: %.2f\nРасход: %.2f", len(unaccounted), debit, credit)
+}
+
+func dashboardBalanceText(balance *models.Balance) string {
+	if balance == nil {
+		return "Балансов ещё нет"
+	}
+	return fmt.Sprintf("%s - %s (%s)\nПрибыль: %.2f\nНа руках: %.2f\nСостояние: %s",
+		balance.StartDate.Format("2006-01-02"), balance.Date.Format("2006-01-02"), TranslatePeriod(balance.PeriodType),
+		balance.Amount, balance.OnHand, BalanceStatus(*balance))
+}
+
+// маленький график прибыли по месяцам без осей
+func sparkline(values []float64) (*canvas.Image, error) {
+	p := plot.New()
+	p.HideAxes()
+
+	points := make(plotter.XYs, len(values))
+	for i, value := range values {
+		points[i].X = float64(i)
+		points[i].Y = value
+	}
+	line, err := plotter.NewLine(points)
+	if err != nil {
+		return nil, err
+	}
+	line.Color = color.RGBA{R: 0, G: 90, B: 200, A: 255}
+	line.Width = vg.Points(2)
+
+	zero, err := plotter.NewLine(plotter.XYs{{X: 0, Y: 0}, {X: float64(len(values) - 1), Y: 0}})
+	if err != nil {
+		return nil, err
+	}
+	zero.Color = color.Gray{Y: 180}
+	p.Add(zero, line)
+
+	spark := canvas.NewImageFromImage(report.PlotImage(p, 3*vg.Inch, vg.Inch, vgimg.DefaultDPI))
+	spark.FillMode = canvas.ImageFillContain
+	spark.SetMinSize(fyne.NewSize(200, 60))
+	return spark, nil
+}
+
+// dashboardTile - карточка главной, нажатие открывает связанный раздел
+type dashboardTile struct {
+	widget.Card
+	onTapped func()
+}
+
+var (
+	_ fyne.Tappable      = (*dashboardTile)(nil)
+	_ desktop.Cursorable = (*dashboardTile)(nil)
+)
+
+func newDashboardTile(title string, onTapped func()) *dashboardTile {
+	tile := &dashboardTile{onTapped: onTapped}
+	tile.Title = title
+	tile.Content = widget.NewLabel("")
+	tile.ExtendBaseWidget(tile)
+	return tile
+}
+
+func (t *dashboardTile) Tapped(*fyne.PointEvent) {
+	if t.onTapped != nil {
+		t.onTapped()
+	}
+}
+
+func (t *dashboardTile) Cursor() desktop.Cursor {
+	return desktop.PointerCursor
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"image/color"
 	"strconv"
+	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
@@ -11,6 +13,7 @@ import (
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 	"github.com/EmptyInsid/db_gui/internal/database"
+	"github.com/EmptyInsid/db_gui/internal/report"
 )
 
 func MainDir(w fyne.Window, db database.Service, role string) (*fyne.Container, error) {
@@ -55,7 +58,8 @@ func ArticleViewer(w fyne.Window, db database.Service, role string) (*container.
 		editor.Hide()
 	}
 
-	return GridViewer(db, table, editor, role), nil
+	save := SaveXLSXButton(w, func() ([]report.Sheet, error) { return ArticleSheet(db) })
+	return GridViewer(db, table, editor, role, save), nil
 }
 
 func OperationsViewer(w fyne.Window, db database.Service, role string) (*container.Split, error) {
@@ -72,18 +76,21 @@ func OperationsViewer(w fyne.Window, db database.Service, role string) (*contain
 		editor.Hide()
 	}
 
-	return GridViewer(db, table, editor, role), nil
+	save := SaveXLSXButton(w, func() ([]report.Sheet, error) { return OperationSheet(db) })
+	return GridViewer(db, table, editor, role, save), nil
 }
 
 // СПИСОК ДЕЙСТВИЙ ДЛЯ СТАТЕЙ
 func AccordionDirArticle(w fyne.Window, db database.Service, table *widget.Table, role string) (*widget.Accordion, error) {
 	accAdd := AddArticle(w, db, table, role)
 	accEdit := EditArticle(w, db, table, role)
+	accTag := TagArticle(w, db, table)
 	accDel := DelArticle(w, db, table, role)
 
 	editor := widget.NewAccordion(
 		widget.NewAccordionItem("Добавить", accAdd),
 		widget.NewAccordionItem("Редактировать", accEdit),
+		widget.NewAccordionItem("Метка", accTag),
 		widget.NewAccordionItem("Удалить", accDel),
 	)
 	return editor, nil
@@ -114,6 +121,7 @@ func WinAddArticle(w fyne.Window, db database.Service, table *widget.Table, role
 			dialog.ShowError(ErrAddArt, w)
 			return
 		} else {
+			undoHistory.push(undoAddArticle(db, article.Text))
 			dialog.ShowInformation("Добавить статью", "Новая статья успешно добавлена!", w)
 		}
 
@@ -165,6 +173,7 @@ func WinEditArticle(w fyne.Window, db database.Service, table *widget.Table, rol
 			dialog.ShowError(ErrUpdArtName, w)
 			return
 		} else {
+			undoHistory.push(undoEditArticle(db, oldName.Selected, newName.Text))
 			dialog.ShowInformation("Изменить имя", "Название статьи успешно изменено!", w)
 		}
 		err = UpdateArticleTable(db, table)
@@ -184,6 +193,44 @@ func WinEditArticle(w fyne.Window, db database.Service, table *widget.Table, rol
 	return container.NewVBox(fieldsCont, btn)
 }
 
+// РАЗДЕЛ МЕТКИ СТАТЬИ
+func TagArticle(w fyne.Window, db database.Service, table *widget.Table) *fyne.Container {
+	winTagArticle := WinTagArticle(w, db, table)
+	return container.NewVBox(canvas.NewLine(color.White), winTagArticle)
+}
+func WinTagArticle(w fyne.Window, db database.Service, table *widget.Table) *fyne.Container {
+	ctx := context.Background()
+
+	article := MadeSelectArticle(w, db)
+	tag := widget.NewEntry()
+	tag.SetPlaceHolder("обязательные")
+
+	cont := container.NewAdaptiveGrid(
+		2,
+		widget.NewLabel("Статья"), article,
+		widget.NewLabel("Метка"), tag,
+	)
+
+	btn := widget.NewButton("Задать метку", func() {
+		if article.Selected == "" {
+			dialog.ShowError(ErrEmptyArt, w)
+			return
+		}
+
+		if err := db.SetArticleTag(ctx, article.Selected, strings.TrimSpace(tag.Text)); err != nil {
+			dialog.ShowError(ErrSetArticleTag, w)
+			return
+		}
+		dialog.ShowInformation("Метка статьи", "Метка статьи сохранена!", w)
+
+		if err := UpdateArticleTable(db, table); err != nil {
+			dialog.ShowError(ErrUpdArt, w)
+		}
+	})
+
+	return container.NewVBox(cont, btn)
+}
+
 // РАЗДЕЛ УДАЛЕНИЯ СТАТЬИ
 func DelArticle(w fyne.Window, db database.Service, table *widget.Table, role string) *fyne.Container {
 	winDelArticle := WinDelArticle(w, db, table, role)
@@ -203,11 +250,24 @@ func WinDelArticle(w fyne.Window, db database.Service, table *widget.Table, role
 			return
 		}
 
-		err := db.DeleteArticle(ctx, article.Selected)
+		// статья и даты её операций запоминаются для отмены удаления
+		id, err := articleID(ctx, db, article.Selected)
 		if err != nil {
 			dialog.ShowError(ErrDelArt, w)
 			return
+		}
+		operations, err := articleOperations(ctx, db, article.Selected)
+		if err != nil {
+			dialog.ShowError(ErrDelArt, w)
+			return
+		}
+
+		err = db.DeleteArticle(ctx, article.Selected)
+		if err != nil {
+			dialog.ShowError(closedPeriodOr(err, ErrDelArt), w)
+			return
 		} else {
+			undoHistory.push(undoDeleteArticle(db, id, article.Selected, operations))
 			dialog.ShowInformation("Удалить статью", "Сатья успешно удалена!", w)
 		}
 		err = UpdateArticleTable(db, table)
@@ -285,11 +345,18 @@ func WinAddOperation(w fyne.Window, db database.Service, table *widget.Table) *f
 			return
 		}
 
-		err = db.AddOperation(ctx, article.Selected, floatDebit, floatCredit, date.Text)
+		opDate, err := time.Parse("2006-01-02", date.Text)
+		if err != nil {
+			dialog.ShowError(ErrParseDate, w)
+			return
+		}
+
+		id, err := db.AddOperation(ctx, article.Selected, floatDebit, floatCredit, date.Text)
 		if err != nil {
-			dialog.ShowError(ErrAddOp, w)
+			dialog.ShowError(closedPeriodOr(err, ErrAddOp), w)
 			return
 		} else {
+			undoHistory.push(undoAddOperation(db, id, article.Selected, floatDebit, floatCredit, opDate))
 			dialog.ShowInformation("Добавить операцию", "Новая операция успешно добавлена!", w)
 		}
 
@@ -355,11 +422,18 @@ func WinEditOperation(w fyne.Window, db database.Service, table *widget.Table) *
 			return
 		}
 
-		err = db.UpdateOpertions(ctx, int(intId), article.Selected, floatDebit, floatCredit)
+		before, err := db.GetOperation(ctx, int(intId))
 		if err != nil {
 			dialog.ShowError(ErrUpdOpData, w)
 			return
+		}
+
+		err = db.UpdateOpertions(ctx, int(intId), article.Selected, floatDebit, floatCredit)
+		if err != nil {
+			dialog.ShowError(closedPeriodOr(err, ErrUpdOpData), w)
+			return
 		} else {
+			undoHistory.push(undoEditOperation(db, before, article.Selected, floatDebit, floatCredit))
 			dialog.ShowInformation("Изменить операцию", "Операция успешно изменена!", w)
 		}
 
@@ -399,11 +473,12 @@ func WinIncreaseOperation(w fyne.Window, db database.Service, table *widget.Tabl
 			return
 		}
 
-		err = db.IncreaseExpensesForArticle(ctx, article.Selected, floatAmount)
+		changes, err := db.IncreaseExpensesForArticle(ctx, article.Selected, floatAmount)
 		if err != nil {
-			dialog.ShowError(ErrIncArtCredit, w)
+			dialog.ShowError(closedPeriodOr(err, ErrIncArtCredit), w)
 			return
 		} else {
+			undoHistory.push(undoIncreaseExpenses(db, article.Selected, changes))
 			dialog.ShowInformation("Повысить расходы по статье", "Расход по статье успешно изменён!", w)
 		}
 		err = UpdateOperationTable(db, table)
@@ -441,11 +516,18 @@ func WinDelOperation(w fyne.Window, db database.Service, table *widget.Table) *f
 			return
 		}
 
-		err = db.DeleteOperation(ctx, int(intId))
+		before, err := db.GetOperation(ctx, int(intId))
 		if err != nil {
 			dialog.ShowError(ErrDelOp, w)
 			return
+		}
+
+		err = db.DeleteOperation(ctx, int(intId))
+		if err != nil {
+			dialog.ShowError(closedPeriodOr(err, ErrDelOp), w)
+			return
 		} else {
+			undoHistory.push(undoDeleteOperation(db, before))
 			dialog.ShowInformation("Удалить операцию", "Операция успешно удалена", w)
 		}
 		err = UpdateOperationTable(db, table)
@@ -3,7 +3,27 @@ package gui
 import "errors"
 
 var (
-	ErrAuth = errors.New("Ошибка входа - неверный логин или пароль.")
+	ErrAuth        = errors.New("Ошибка входа - неверный логин или пароль.")
+	ErrAuthLocked  = errors.New("Учётная запись временно заблокирована из-за большого числа неудачных попыток входа. Обратитесь к администратору.")
+	ErrAuthBackoff = errors.New("Слишком много попыток входа - подождите немного и попробуйте снова.")
+	ErrAuthCode    = errors.New("Ошибка входа - неверный код подтверждения.")
+
+	ErrGetHouseholds      = errors.New("Упс! Не удалось загрузить список бюджетов.")
+	ErrUseHousehold       = errors.New("Не удалось открыть бюджет - возможно, у вас нет к нему доступа.")
+	ErrAddHousehold       = errors.New("Упс! Не удалось создать бюджет - проверьте название и попробуйте ещё раз.")
+	ErrAddHouseholdMember = errors.New("Не удалось добавить участника - проверьте, что такой пользователь существует.")
+	ErrEmptyHousehold     = errors.New("Ошибка ввода - обязательно введите название бюджета!")
+
+	ErrShowTwoFactor    = errors.New("Упс! при открытии настроек двухфакторной аутентификации что-то пошло не так.")
+	ErrEnableTwoFactor  = errors.New("Не удалось подключить двухфакторную аутентификацию - проверьте код из приложения.")
+	ErrDisableTwoFactor = errors.New("Не удалось отключить двухфакторную аутентификацию - проверьте код.")
+	ErrTwoFactorQR      = errors.New("Упс! Не удалось сформировать QR-код.")
+
+	ErrGetUsers   = errors.New("Упс! Не удалось загрузить историю входов.")
+	ErrUnlockUser = errors.New("Не удалось разблокировать пользователя - проверьте введённый логин.")
+	ErrEmptyUser  = errors.New("Ошибка ввода - обязательно введите логин пользователя!")
+	ErrShowUsers  = errors.New("Упс! при открытии раздела пользователей что-то пошло не так.")
+	ErrGetAudit   = errors.New("Упс! Не удалось загрузить журнал изменений - проверьте корректность фильтров.")
 
 	ErrGetArt     = errors.New("Упс! Ошибка сервиса - не удалось загрузить статьи.")
 	ErrAddArt     = errors.New("Неудалось добавить статью: возможно, статья с таким именем уже существует.")
@@ -24,6 +44,7 @@ var (
 	ErrUpdOp     = errors.New("Упс! Ошибка сервера - неудалось обновить таблицу операций.")
 
 	ErrIncArtCredit = errors.New("Упс! Не удалось повысить расходы по статье. Возможно, эта статья включена в закрытые балансы.")
+	ErrClosedPeriod = errors.New("Изменение затрагивает период, закрытый балансом. Администратор может открыть период повторно в разделе Журнал.")
 
 	ErrGetBalance = errors.New("Упс! Не удалось получить таблицу балансов.")
 	ErrUpdBalance = errors.New("Упс! Ошибка сервера - неудалось обновить таблицу балансов.")
@@ -32,16 +53,72 @@ var (
 	ErrGetTotalCredit  = errors.New("Упс! Не удалось получить сумму расхода за статью. Убедитесь, что ввели верный отрезок времени и название статьи.")
 	ErrGetBalanceCount = errors.New("Упс! Не удалось получить балансы за указанную статью. Убедитесь, что ввели верное название статьи.")
 
-	ErrCreateBalance     = errors.New("Не удалось создать баланс - проверьте, что ввели верную дату (конец месяца).")
+	ErrCreateBalance     = errors.New("Не удалось создать баланс - проверьте, что ввели верные даты периода.")
 	ErrMinBalanceProfit  = errors.New("Профит баланса меньше минимально допустимого порога.")
-	ErrCreateBalanceDate = errors.New("Не удалось создать баланс - введённая дата не является концом месяца.")
+	ErrCreateBalanceDate = errors.New("Не удалось создать баланс - границы не соответствуют типу периода (неделя с понедельника, месяц, квартал или год целиком).")
+	ErrPeriodOverlap     = errors.New("Не удалось создать баланс - период пересекается с уже сформированным балансом.")
+	ErrPeriodGap         = errors.New("Не удалось создать баланс - период должен начинаться сразу после конца другого баланса или заканчиваться перед его началом.")
 	ErrDelBalance        = errors.New("Ошибка удаления баланса - проверьте, что баланс на заданную дату действительно существует.")
 	ErrDelMinBalance     = errors.New("Ошибка удаления минимального баланса - проверьте, что балансы действительно существуют.")
+	ErrReopenPeriod      = errors.New("Ошибка открытия периода - проверьте, что баланс на заданную дату существует и период ещё закрыт.")
+	ErrClosePeriod       = errors.New("Ошибка закрытия периода - проверьте, что период на заданную дату был открыт повторно.")
+	ErrEmptyReason       = errors.New("Укажите причину повторного открытия периода.")
+	ErrGetOnHand         = errors.New("Упс! Не удалось посчитать деньги на руках на указанную дату.")
+	ErrSetOpening        = errors.New("Упс! Не удалось сохранить начальный остаток.")
+	ErrCloseBalances     = errors.New("Упс! Не удалось закрыть периоды - проверьте тип периода и дату.")
+	ErrShowClosing       = errors.New("Упс! при открытии мастера закрытия периодов что-то пошло не так.")
+	ErrShowIntegrity     = errors.New("Упс! Не удалось проверить балансы.")
+	ErrRepairBalances    = errors.New("Упс! Не удалось исправить балансы - изменения отменены.")
+
+	ErrNothingToUndo    = errors.New("Нет действий для отмены.")
+	ErrNothingToRedo    = errors.New("Нет действий для повтора.")
+	ErrUndoClosedPeriod = errors.New("Действие нельзя отменить - период операций уже закрыт балансом.")
+	ErrUndo             = errors.New("Упс! Не удалось отменить действие.")
+	ErrRedo             = errors.New("Упс! Не удалось повторить действие.")
+
+	ErrShowTrash             = errors.New("Упс! при открытии корзины что-то пошло не так.")
+	ErrEmptyTrashItem        = errors.New("Выберите запись корзины для восстановления.")
+	ErrRestore               = errors.New("Упс! Не удалось восстановить запись.")
+	ErrRestoreConflict       = errors.New("Нельзя восстановить запись - уже существует статья с таким названием или баланс на эту дату.")
+	ErrRestoreArticleDeleted = errors.New("Нельзя восстановить операцию - её статья удалена. Сначала восстановите статью.")
+	ErrTrashDays             = errors.New("Срок хранения должен быть целым неотрицательным числом дней.")
+	ErrPurgeTrash            = errors.New("Упс! Не удалось очистить корзину.")
+
+	ErrComparison     = errors.New("Ошибка при сравнении периодов - проверьте корректность введённых дат и статей.")
+	ErrForecast       = errors.New("Упс! Не удалось построить прогноз.")
+	ErrForecastParams = errors.New("Ошибка ввода - число месяцев должно быть целым положительным числом.")
+	ErrReportNumber   = errors.New("Ошибка ввода - числовые параметры отчёта должны быть целыми положительными числами.")
+	ErrGetRecurring   = errors.New("Упс! Не удалось загрузить регулярные платежи.")
+	ErrAddRecurring   = errors.New("Не удалось добавить регулярный платёж - проверьте, что статья существует и даты верны.")
+	ErrDelRecurring   = errors.New("Ошибка удаления регулярного платежа - проверьте, что платёж с таким ID существует.")
+
+	ErrBuilderSpec     = errors.New("Ошибка ввода - выберите хотя бы одно измерение и один показатель, минимальная сумма не больше максимальной.")
+	ErrEmptyReportName = errors.New("Ошибка ввода - укажите название отчёта или выберите сохранённый отчёт.")
+	ErrSaveReport      = errors.New("Упс! Не удалось сохранить отчёт.")
+	ErrDelSavedReport  = errors.New("Упс! Не удалось удалить сохранённый отчёт.")
+	ErrGetSavedReports = errors.New("Упс! Не удалось загрузить сохранённые отчёты.")
+	ErrShowBuilder     = errors.New("Упс! при открытии конструктора отчётов что-то пошло не так.")
+	ErrSetArticleTag   = errors.New("Ошибка изменения метки - проверьте, что такая статья существует.")
+
+	ErrEmptyPresetName = errors.New("Ошибка ввода - укажите название набора параметров или выберите сохранённый набор.")
+	ErrSavePreset      = errors.New("Упс! Не удалось сохранить параметры отчёта.")
+	ErrDelPreset       = errors.New("Упс! Не удалось удалить сохранённые параметры отчёта.")
+	ErrGetPresets      = errors.New("Упс! Не удалось загрузить сохранённые параметры отчёта.")
+
+	ErrGetSchedules  = errors.New("Упс! Не удалось загрузить расписания отчётов.")
+	ErrAddSchedule   = errors.New("Упс! Не удалось добавить расписание - проверьте папку и дату первого запуска.")
+	ErrDelSchedule   = errors.New("Ошибка удаления расписания - проверьте, что такое расписание существует.")
+	ErrRunSchedule   = errors.New("Упс! Не удалось построить отчёт по расписанию - подробности в колонке \"Итог\".")
+	ErrScheduleInput = errors.New("Ошибка ввода - выберите отчёт и укажите папку для сохранения.")
+	ErrShowSchedules = errors.New("Упс! при открытии расписаний отчётов что-то пошло не так.")
 
 	ErrReport     = errors.New("Упс! При создании отчёта что-то пошло не так...")
 	ErrShowJorney = errors.New("Упс! при открытии журнала что-то пошло не так.")
 	ErrShowDir    = errors.New("Упс! при открытии справочника что-то пошло не так.")
 
+	ErrShowDashboard   = errors.New("Упс! при открытии главной что-то пошло не так.")
+	ErrShowUnaccounted = errors.New("Упс! при открытии операций вне балансов что-то пошло не так.")
+
 	ErrIncomeExpence  = errors.New("Ошибка при создании таблицы динамики изменения расходов и доходов - проверьте корректность введённых параметров.")
 	ErrFinPercTable   = errors.New("Ошибка при создании таблицы процентного соотношения финансовых потоков - проверьте корректность введённых параметров.")
 	ErrTotalProfTable = errors.New("Ошибка при создании таблицы чистой прибыли бюджета от времени - проверьте корректность введённых данных.")
@@ -0,0 +1,147 @@
+package gui
+
+import (
+	"context"
+	"image/color"
+	"strconv"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/EmptyInsid/db_gui/internal/database"
+	"github.com/EmptyInsid/db_gui/internal/models"
+)
+
+// вкладка регулярных платежей на экране прогноза
+func RecurringTab(w fyne.Window, db database.Service, role string) (*container.TabItem, error) {
+	content, err := RecurringItems(w, db, role)
+	if err != nil {
+		return nil, err
+	}
+	return container.NewTabItem("Регулярные платежи", content), nil
+}
+
+// РАЗДЕЛ РЕГУЛЯРНЫХ ПЛАТЕЖЕЙ
+func RecurringItems(w fyne.Window, db database.Service, role string) (*container.Split, error) {
+	table, err := RecurringTable(db)
+	if err != nil {
+		return nil, err
+	}
+
+	editor := widget.NewAccordion()
+	if role == "admin" {
+		editor.Append(widget.NewAccordionItem("Добавить", container.NewVBox(canvas.NewLine(color.White), WinAddRecurring(w, db, table))))
+		editor.Append(widget.NewAccordionItem("Удалить", container.NewVBox(canvas.NewLine(color.White), WinDelRecurring(w, db, table))))
+	}
+	return GridViewer(db, table, editor, role), nil
+}
+
+func WinAddRecurring(w fyne.Window, db database.Service, table *widget.Table) *fyne.Container {
+	ctx := context.Background()
+
+	article := MadeSelectArticle(w, db)
+	debit := widget.NewEntry()
+	debit.SetPlaceHolder("0")
+	credit := widget.NewEntry()
+	credit.SetPlaceHolder("0")
+	startDate, endDate := MadeDateFields()
+	endDate.SetPlaceHolder("без окончания")
+	every := widget.NewEntry()
+	every.SetText("1")
+
+	cont := container.NewAdaptiveGrid(
+		2,
+		widget.NewLabel("Статья"), article,
+		widget.NewLabel("Доход"), debit,
+		widget.NewLabel("Расход"), credit,
+		widget.NewLabel("Начало"), startDate,
+		widget.NewLabel("Окончание"), endDate,
+		widget.NewLabel("Раз в месяцев"), every,
+	)
+
+	btn := widget.NewButton("Добавить платёж", func() {
+		if article.Selected == "" {
+			dialog.ShowError(ErrEmptyArt, w)
+			return
+		}
+
+		item := models.RecurringItem{ArticleName: article.Selected}
+		var err error
+		if item.Debit, err = parseOptionalFloat(debit.Text); err != nil {
+			dialog.ShowError(ErrParseDebit, w)
+			return
+		}
+		if item.Credit, err = parseOptionalFloat(credit.Text); err != nil {
+			dialog.ShowError(ErrParseCredit, w)
+			return
+		}
+		if item.StartDate, err = time.Parse("2006-01-02", startDate.Text); err != nil {
+			dialog.ShowError(ErrParseDate, w)
+			return
+		}
+		if endDate.Text != "" {
+			end, err := time.Parse("2006-01-02", endDate.Text)
+			if err != nil {
+				dialog.ShowError(ErrParseDate, w)
+				return
+			}
+			item.EndDate = &end
+		}
+		if item.EveryMonths, err = strconv.Atoi(every.Text); err != nil || item.EveryMonths <= 0 {
+			dialog.ShowError(ErrForecastParams, w)
+			return
+		}
+
+		if err := db.AddRecurringItem(ctx, item); err != nil {
+			dialog.ShowError(ErrAddRecurring, w)
+			return
+		}
+		dialog.ShowInformation("Добавить платёж", "Регулярный платёж добавлен!", w)
+
+		if err := UpdateRecurringTable(db, table); err != nil {
+			dialog.ShowError(ErrGetRecurring, w)
+		}
+	})
+
+	return container.NewVBox(cont, btn)
+}
+
+func WinDelRecurring(w fyne.Window, db database.Service, table *widget.Table) *fyne.Container {
+	ctx := context.Background()
+
+	id := widget.NewEntry()
+	id.SetPlaceHolder("id")
+
+	cont := container.NewAdaptiveGrid(2, widget.NewLabel("ID платежа"), id)
+
+	btn := widget.NewButton("Удалить платёж", func() {
+		intId, err := strconv.Atoi(id.Text)
+		if err != nil {
+			dialog.ShowError(ErrParseId, w)
+			return
+		}
+
+		if err := db.DeleteRecurringItem(ctx, intId); err != nil {
+			dialog.ShowError(ErrDelRecurring, w)
+			return
+		}
+		dialog.ShowInformation("Удалить платёж", "Регулярный платёж удалён", w)
+
+		if err := UpdateRecurringTable(db, table); err != nil {
+			dialog.ShowError(ErrGetRecurring, w)
+		}
+	})
+
+	return container.NewVBox(cont, btn)
+}
+
+// пустое поле суммы считается нулём
+func parseOptionalFloat(text string) (float64, error) {
+	if text == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(text, 64)
+}
@@ -1,15 +1,19 @@
 package gui
 
 import (
+	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/widget"
 	"github.com/EmptyInsid/db_gui/internal/database"
 )
 
-// table + toolbar
-func GridViewer(db database.Service, table *widget.Table, toolbar *widget.Accordion, role string) *container.Split {
+// table + toolbar, extra - кнопки под панелью действий
+func GridViewer(db database.Service, table *widget.Table, toolbar *widget.Accordion, role string, extra ...fyne.CanvasObject) *container.Split {
 	tableContainer := container.NewStack(table)
 	toolBarContainer := container.NewVBox(toolbar)
+	for _, obj := range extra {
+		toolBarContainer.Add(obj)
+	}
 	mainContent := container.NewHSplit(tableContainer, toolBarContainer)
 	mainContent.SetOffset(0.7) // Устанавливает пропорцию (70% для таблицы, 30% для правой панели)
 
@@ -0,0 +1,128 @@
+package gui
+
+import (
+	"context"
+	"log"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/EmptyInsid/db_gui/internal/database"
+)
+
+// открыть сессию пользователя в первом доступном домохозяйстве,
+// пользователю без домохозяйств создаётся личное
+func StartSession(db database.Service, username string) error {
+	ctx := context.Background()
+
+	households, err := db.GetUserHouseholds(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	if len(households) == 0 {
+		id, err := db.AddHousehold(ctx, database.PersonalHouseholdName(username), username)
+		if err != nil {
+			return err
+		}
+		return db.UseHousehold(ctx, username, id)
+	}
+
+	return db.UseHousehold(ctx, username, households[0].ID)
+}
+
+// меню выбора активного домохозяйства
+func HouseholdMenu(myApp fyne.App, w fyne.Window, db database.Service, username, role string) (*fyne.Menu, error) {
+	ctx := context.Background()
+
+	households, err := db.GetUserHouseholds(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*fyne.MenuItem
+	for _, household := range households {
+		id := household.ID
+		item := fyne.NewMenuItem(household.Name, func() {
+			if err := db.UseHousehold(ctx, username, id); err != nil {
+				dialog.ShowError(ErrUseHousehold, w)
+				return
+			}
+			log.Printf("User %s switched to household %d", username, id)
+			MainWindow(myApp, w, db, username, role)
+		})
+		item.Checked = id == db.CurrentHousehold()
+		items = append(items, item)
+	}
+
+	addHousehold := fyne.NewMenuItem("Создать бюджет", func() {
+		WinAddHousehold(myApp, w, db, username, role)
+	})
+	items = append(items, fyne.NewMenuItemSeparator(), addHousehold)
+
+	if role == "admin" {
+		addMember := fyne.NewMenuItem("Добавить участника", func() {
+			WinAddHouseholdMember(w, db)
+		})
+		items = append(items, addMember)
+	}
+
+	return fyne.NewMenu("Бюджет", items...), nil
+}
+
+// РАЗДЕЛ СОЗДАНИЯ ДОМОХОЗЯЙСТВА
+func WinAddHousehold(myApp fyne.App, w fyne.Window, db database.Service, username, role string) {
+	ctx := context.Background()
+
+	name := widget.NewEntry()
+	name.SetPlaceHolder("семья")
+
+	items := []*widget.FormItem{widget.NewFormItem("Название", name)}
+
+	dialog.ShowForm("Создать бюджет", "Создать", "Отмена", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		if name.Text == "" {
+			dialog.ShowError(ErrEmptyHousehold, w)
+			return
+		}
+
+		id, err := db.AddHousehold(ctx, name.Text, username)
+		if err != nil {
+			dialog.ShowError(ErrAddHousehold, w)
+			return
+		}
+		if err := db.UseHousehold(ctx, username, id); err != nil {
+			dialog.ShowError(ErrUseHousehold, w)
+			return
+		}
+		MainWindow(myApp, w, db, username, role)
+	}, w)
+}
+
+// РАЗДЕЛ ДОБАВЛЕНИЯ УЧАСТНИКА
+func WinAddHouseholdMember(w fyne.Window, db database.Service) {
+	ctx := context.Background()
+
+	member := widget.NewEntry()
+	member.SetPlaceHolder("логин")
+
+	items := []*widget.FormItem{widget.NewFormItem("Пользователь", member)}
+
+	dialog.ShowForm("Добавить участника", "Добавить", "Отмена", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		if member.Text == "" {
+			dialog.ShowError(ErrEmptyUser, w)
+			return
+		}
+
+		if err := db.AddHouseholdMember(ctx, member.Text); err != nil {
+			dialog.ShowError(ErrAddHouseholdMember, w)
+			return
+		}
+		dialog.ShowInformation("Добавить участника", "Пользователь добавлен в бюджет!", w)
+	}, w)
+}
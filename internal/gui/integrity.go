@@ -0,0 +1,94 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/EmptyInsid/db_gui/internal/database"
+)
+
+func MainIntegrity(w fyne.Window, db database.Service, role string) (*fyne.Container, error) {
+	report, err := db.VerifyBalances(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	title := MadeTitle("Проверка балансов")
+	summary := widget.NewLabel(integritySummary(report))
+
+	tabs := TabsIntegrity(report)
+
+	top := container.NewVBox(title, summary)
+	if role == "admin" {
+		top.Add(WinRepairBalances(w, db, role, report))
+	}
+
+	return container.NewBorder(top, nil, nil, nil, tabs), nil
+}
+
+func TabsIntegrity(report database.IntegrityReport) *container.AppTabs {
+	mismatches := container.NewTabItem(
+		fmt.Sprintf("Расхождения итогов (%d)", len(report.Mismatches)),
+		container.NewStack(BalanceMismatchTable(report.Mismatches)),
+	)
+	orphans := container.NewTabItem(
+		fmt.Sprintf("Ссылки на несуществующие балансы (%d)", len(report.Orphans)),
+		container.NewStack(IntegrityOperationTable(report.Orphans, "Ссылается на баланс")),
+	)
+	unlinked := container.NewTabItem(
+		fmt.Sprintf("Неучтённые операции закрытых периодов (%d)", len(report.Unlinked)),
+		container.NewStack(IntegrityOperationTable(report.Unlinked, "Баланс периода")),
+	)
+
+	tab := container.NewAppTabs(mismatches, orphans, unlinked)
+	tab.SetTabLocation(container.TabLocationTop)
+	return tab
+}
+
+// РАЗДЕЛ ИСПРАВЛЕНИЯ
+func WinRepairBalances(w fyne.Window, db database.Service, role string, report database.IntegrityReport) *fyne.Container {
+	ctx := context.Background()
+
+	btn := widget.NewButton("Исправить", func() {
+		dialog.ShowConfirm(
+			"Исправить",
+			"Будут сброшены ссылки на несуществующие балансы, операции закрытых периодов\n"+
+				"привязаны к своим балансам, а итоги балансов пересчитаны. Продолжить?",
+			func(ok bool) {
+				if !ok {
+					return
+				}
+				fixed, err := db.RepairBalances(ctx)
+				if err != nil {
+					dialog.ShowError(ErrRepairBalances, w)
+					return
+				}
+				dialog.ShowInformation("Исправить", "Исправлено:\n"+integritySummary(fixed), w)
+
+				integrityContent, err := MainIntegrity(w, db, role)
+				if err != nil {
+					dialog.ShowError(ErrShowIntegrity, w)
+					return
+				}
+				w.SetContent(integrityContent)
+			},
+			w)
+	})
+	if report.Empty() {
+		btn.Disable()
+	}
+
+	return container.NewVBox(btn)
+}
+
+func integritySummary(report database.IntegrityReport) string {
+	if report.Empty() {
+		return "Нарушений не найдено."
+	}
+	return fmt.Sprintf("Расхождений итогов: %d\nСсылок на несуществующие балансы: %d\nНеучтённых операций закрытых периодов: %d",
+		len(report.Mismatches), len(report.Orphans), len(report.Unlinked))
+}
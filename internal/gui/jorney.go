@@ -14,6 +14,8 @@ import (
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 	"github.com/EmptyInsid/db_gui/internal/database"
+	"github.com/EmptyInsid/db_gui/internal/models"
+	"github.com/EmptyInsid/db_gui/internal/report"
 )
 
 func MainJorney(w fyne.Window, db database.Service, role string) (*container.Split, error) {
@@ -24,7 +26,8 @@ func MainJorney(w fyne.Window, db database.Service, role string) (*container.Spl
 
 	editor := AccordionJorney(w, db, table, role)
 
-	return GridViewer(db, table, editor, role), nil
+	save := SaveXLSXButton(w, func() ([]report.Sheet, error) { return BalanceSheet(db) })
+	return GridViewer(db, table, editor, role, save), nil
 }
 
 func AccordionJorney(w fyne.Window, db database.Service, table *widget.Table, role string) *widget.Accordion {
@@ -49,8 +52,10 @@ func SummariesAccord(w fyne.Window, db database.Service) *fyne.Container {
 	winProfit := WinGetProfit(w, db)
 	winCredit := WinGetCredit(w, db)
 	winBalance := WinBalanceCount(w, db)
+	winOnHand := WinMoneyOnHand(w, db)
 
-	return container.NewVBox(canvas.NewLine(color.White), winProfit, canvas.NewLine(color.White), winCredit, canvas.NewLine(color.White), winBalance)
+	return container.NewVBox(canvas.NewLine(color.White), winProfit, canvas.NewLine(color.White), winCredit, canvas.NewLine(color.White), winBalance,
+		canvas.NewLine(color.White), winOnHand)
 }
 
 func WinGetProfit(w fyne.Window, db database.Service) *fyne.Container {
@@ -163,23 +168,77 @@ func WinBalanceCount(w fyne.Window, db database.Service) *fyne.Container {
 	return container.NewVBox(commCont, btnArtOp)
 }
 
+// деньги на руках на конец даты
+func WinMoneyOnHand(w fyne.Window, db database.Service) *fyne.Container {
+	ctx := context.Background()
+
+	_, date := MadeDateFields()
+	date.SetText(time.Now().Format("2006-01-02"))
+
+	fieldOnHand := widget.NewLabel("На руках: 0.00")
+
+	btn := widget.NewButton("Деньги на руках", func() {
+		if _, err := time.Parse("2006-01-02", date.Text); err != nil {
+			dialog.ShowError(ErrParseDate, w)
+			return
+		}
+
+		money, err := db.GetMoneyOnHand(ctx, date.Text)
+		if err != nil {
+			dialog.ShowError(ErrGetOnHand, w)
+			return
+		}
+
+		fieldOnHand.SetText(fmt.Sprintf("На руках: %.2f", money))
+	})
+
+	cont := container.NewAdaptiveGrid(2, widget.NewLabel("На дату:"), date, fieldOnHand)
+
+	return container.NewVBox(cont, btn)
+}
+
 // РАЗДЕЛ РЕДАКТИРОВАНИЯ
 func EditAccord(w fyne.Window, db database.Service, table *widget.Table) *fyne.Container {
 	winCreateBalance := WinCreateNewBalance(w, db, table)
 	winDelUnprof := WinDeleteUnprofitBalance(w, db, table)
 	winDelBalance := WinDelBalance(w, db, table)
+	winReopen := WinReopenPeriod(w, db, table)
+	winClose := WinClosePeriod(w, db, table)
+	winOpening := WinOpeningBalance(w, db, table)
 
-	return container.NewVBox(canvas.NewLine(color.White), winCreateBalance, canvas.NewLine(color.White), winDelBalance, canvas.NewLine(color.White), winDelUnprof)
+	return container.NewVBox(canvas.NewLine(color.White), winCreateBalance, canvas.NewLine(color.White), winDelBalance, canvas.NewLine(color.White), winDelUnprof,
+		canvas.NewLine(color.White), winReopen, canvas.NewLine(color.White), winClose, canvas.NewLine(color.White), winOpening)
 }
 func WinCreateNewBalance(w fyne.Window, db database.Service, table *widget.Table) *fyne.Container {
 	ctx := context.Background()
 
-	_, endDate := MadeDateFields()
+	period := MadeSelectPeriod()
+	startDate, endDate := MadeDateFields()
+
+	// для стандартных периодов границы выводятся из начала периода
+	fillEnd := func() {
+		periodType := SelectedPeriod(period)
+		date, err := time.Parse("2006-01-02", startDate.Text)
+		if err != nil || periodType == database.PeriodCustom {
+			return
+		}
+		start, end := database.PeriodBounds(periodType, date)
+		if start.Format("2006-01-02") != startDate.Text {
+			startDate.SetText(start.Format("2006-01-02"))
+		}
+		endDate.SetText(end.Format("2006-01-02"))
+	}
+	startDate.OnSubmitted = func(string) { fillEnd() }
+	period.OnChanged = func(string) { fillEnd() }
 
 	minProf := widget.NewEntry()
 	minProf.SetPlaceHolder("100.0")
 	periodCont := container.NewStack(container.NewAdaptiveGrid(
 		1,
+		widget.NewLabel("Тип периода:"),
+		period,
+		widget.NewLabel("Начало периода:"),
+		startDate,
 		widget.NewLabel("Конец периода:"),
 		endDate,
 		widget.NewLabel("Минимальный профит:"),
@@ -188,15 +247,12 @@ func WinCreateNewBalance(w fyne.Window, db database.Service, table *widget.Table
 
 	btnArtOp := widget.NewButton("Создать баланс", func() {
 
-		date, err := time.Parse("2006-01-02", endDate.Text)
-		if err != nil {
+		if _, err := time.Parse("2006-01-02", startDate.Text); err != nil {
 			dialog.ShowError(ErrParseDate, w)
 			return
 		}
-
-		// Проверяем, является ли дата концом месяца
-		if !isEndOfMonth(date) {
-			dialog.ShowError(ErrCreateBalanceDate, w)
+		if _, err := time.Parse("2006-01-02", endDate.Text); err != nil {
+			dialog.ShowError(ErrParseDate, w)
 			return
 		}
 
@@ -205,16 +261,21 @@ func WinCreateNewBalance(w fyne.Window, db database.Service, table *widget.Table
 			dialog.ShowError(ErrParseDebit, w)
 			return
 		}
-		// Получаем начало месяца
-		startOfMonth := getStartOfMonth(date)
 
-		err = db.CreateBalanceIfProfitable(ctx, startOfMonth.Format("2006-01-02"), endDate.Text, floatMinProf)
+		err = db.CreateBalanceIfProfitable(ctx, SelectedPeriod(period), startDate.Text, endDate.Text, floatMinProf)
 		if err != nil {
-			if errors.Is(err, database.ErrLessThenMin) {
+			switch {
+			case errors.Is(err, database.ErrLessThenMin):
 				dialog.ShowError(ErrMinBalanceProfit, w)
-				return
+			case errors.Is(err, database.ErrPeriodInvalid):
+				dialog.ShowError(ErrCreateBalanceDate, w)
+			case errors.Is(err, database.ErrPeriodOverlap):
+				dialog.ShowError(ErrPeriodOverlap, w)
+			case errors.Is(err, database.ErrPeriodGap):
+				dialog.ShowError(ErrPeriodGap, w)
+			default:
+				dialog.ShowError(ErrCreateBalance, w)
 			}
-			dialog.ShowError(ErrCreateBalance, w)
 			return
 		} else {
 			dialog.ShowInformation("Создать баланс", "Новый баланс создан успешно!", w)
@@ -279,3 +340,149 @@ func WinDeleteUnprofitBalance(w fyne.Window, db database.Service, table *widget.
 
 	return container.NewVBox(btn)
 }
+
+// повторное открытие закрытого периода для исправлений, фиксируется в журнале изменений
+func WinReopenPeriod(w fyne.Window, db database.Service, table *widget.Table) *fyne.Container {
+	ctx := context.Background()
+
+	date := widget.NewEntry()
+	date.SetPlaceHolder("дата баланса")
+	reason := widget.NewEntry()
+	reason.SetPlaceHolder("причина")
+
+	periodCont := container.NewAdaptiveGrid(2,
+		widget.NewLabel("Дата баланса:"), date,
+		widget.NewLabel("Причина:"), reason,
+	)
+
+	btn := widget.NewButton("Открыть период", func() {
+		if reason.Text == "" {
+			dialog.ShowError(ErrEmptyReason, w)
+			return
+		}
+
+		balance, err := balanceByDate(ctx, db, date.Text)
+		if err != nil {
+			dialog.ShowError(ErrReopenPeriod, w)
+			return
+		}
+
+		dialog.ShowConfirm(
+			"Открыть период",
+			"Операции периода станут доступны для изменения до повторного закрытия. Продолжить?",
+			func(ok bool) {
+				if !ok {
+					return
+				}
+				if err := db.ReopenPeriod(ctx, balance.ID, reason.Text); err != nil {
+					dialog.ShowError(ErrReopenPeriod, w)
+					return
+				}
+				dialog.ShowInformation("Открыть период", "Период открыт для исправлений.", w)
+				if err := UpdateBalanceTable(db, table); err != nil {
+					dialog.ShowError(ErrUpdBalance, w)
+				}
+			},
+			w)
+	})
+
+	return container.NewVBox(periodCont, btn)
+}
+
+// закрытие повторно открытого периода, итоги баланса пересчитываются
+func WinClosePeriod(w fyne.Window, db database.Service, table *widget.Table) *fyne.Container {
+	ctx := context.Background()
+
+	date := widget.NewEntry()
+	date.SetPlaceHolder("дата баланса")
+
+	periodCont := container.NewAdaptiveGrid(2, widget.NewLabel("Дата баланса:"), date)
+
+	btn := widget.NewButton("Закрыть период", func() {
+		balance, err := balanceByDate(ctx, db, date.Text)
+		if err != nil {
+			dialog.ShowError(ErrClosePeriod, w)
+			return
+		}
+
+		if err := db.ClosePeriod(ctx, balance.ID); err != nil {
+			dialog.ShowError(ErrClosePeriod, w)
+			return
+		}
+		dialog.ShowInformation("Закрыть период", "Период закрыт, итоги баланса пересчитаны.", w)
+		if err := UpdateBalanceTable(db, table); err != nil {
+			dialog.ShowError(ErrUpdBalance, w)
+		}
+	})
+
+	return container.NewVBox(periodCont, btn)
+}
+
+// найти баланс по дате закрытия периода
+func balanceByDate(ctx context.Context, db database.Service, date string) (models.Balance, error) {
+	balances, err := db.GetAllBalances(ctx)
+	if err != nil {
+		return models.Balance{}, err
+	}
+	for _, balance := range balances {
+		if balance.Date.Format("2006-01-02") == date {
+			return balance, nil
+		}
+	}
+	return models.Balance{}, database.ErrEmptyRow
+}
+
+// состояние периода баланса для таблицы
+func BalanceStatus(balance models.Balance) string {
+	if balance.ReopenedAt != nil {
+		return "открыт повторно"
+	}
+	return "закрыт"
+}
+
+// начальный остаток, от которого считаются деньги на руках
+func WinOpeningBalance(w fyne.Window, db database.Service, table *widget.Table) *fyne.Container {
+	ctx := context.Background()
+
+	amount := widget.NewEntry()
+	amount.SetPlaceHolder("0.00")
+	date := widget.NewEntry()
+	date.SetPlaceHolder("с первой операции")
+
+	if opening, err := db.GetOpeningBalance(ctx); err == nil {
+		amount.SetText(fmt.Sprintf("%.2f", opening.Amount))
+		if opening.Date != nil {
+			date.SetText(opening.Date.Format("2006-01-02"))
+		}
+	}
+
+	cont := container.NewAdaptiveGrid(2,
+		widget.NewLabel("Начальный остаток:"), amount,
+		widget.NewLabel("На начало даты:"), date,
+	)
+
+	btn := widget.NewButton("Сохранить остаток", func() {
+		floatAmount, err := strconv.ParseFloat(amount.Text, 64)
+		if err != nil {
+			dialog.ShowError(ErrParseAmount, w)
+			return
+		}
+		if date.Text != "" {
+			if _, err := time.Parse("2006-01-02", date.Text); err != nil {
+				dialog.ShowError(ErrParseDate, w)
+				return
+			}
+		}
+
+		if err := db.SetOpeningBalance(ctx, floatAmount, date.Text); err != nil {
+			dialog.ShowError(ErrSetOpening, w)
+			return
+		}
+		dialog.ShowInformation("Начальный остаток", "Начальный остаток сохранён!", w)
+		if err := UpdateBalanceTable(db, table); err != nil {
+			dialog.ShowError(ErrUpdBalance, w)
+		}
+	})
+
+	return container.NewVBox(cont, btn)
+}
@@ -6,42 +6,66 @@ import (
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 	"github.com/EmptyInsid/db_gui/internal/database"
+	"github.com/EmptyInsid/db_gui/internal/report"
 )
 
-func MainWindow(myApp fyne.App, w fyne.Window, db database.Service, role string) {
-	MainMenu(myApp, w, db, role)
-	emptyArea := container.NewStack()
+func MainWindow(myApp fyne.App, w fyne.Window, db database.Service, username, role string) {
+	// история правок относится к сессии и активному бюджету
+	undoHistory.Clear()
+	MainMenu(myApp, w, db, username, role)
 	w.Resize(fyne.NewSize(1000, 500))
 	w.CenterOnScreen()
-	w.SetContent(container.NewBorder(nil, nil, nil, nil, emptyArea))
+
+	dashboard, err := MainDashboard(w, db, role)
+	if err != nil {
+		dialog.ShowError(ErrShowDashboard, w)
+		w.SetContent(container.NewStack())
+		return
+	}
+	w.SetContent(dashboard)
 }
 
-func MainMenu(myApp fyne.App, w fyne.Window, db database.Service, role string) {
+func MainMenu(myApp fyne.App, w fyne.Window, db database.Service, username, role string) {
+
+	var reportItems []*fyne.MenuItem
+	for _, def := range report.All() {
+		reportItems = append(reportItems, fyne.NewMenuItem(def.Name, func() {
+			cont, err := MainReport(w, db, role, def)
+			if err != nil {
+				dialog.ShowError(ErrReport, w)
+				return
+			}
+			w.SetContent(cont)
+		}))
+	}
 
-	reportFirst := fyne.NewMenuItem("Отчёт 1", func() {
-		cont, err := MainReportFirst(w, db)
+	reportItems = append(reportItems, fyne.NewMenuItem("Конструктор", func() {
+		cont, err := MainReportBuilder(w, db)
 		if err != nil {
-			dialog.ShowError(ErrReport, w)
+			dialog.ShowError(ErrShowBuilder, w)
+			return
 		}
 		w.SetContent(cont)
-	})
-	reportSecond := fyne.NewMenuItem("Отчёт 2", func() {
-		cont, err := MainReportSecond(w, db)
+	}))
+	reportItems = append(reportItems, fyne.NewMenuItem("Расписания", func() {
+		cont, err := MainSchedules(w, db, role)
 		if err != nil {
-			dialog.ShowError(ErrReport, w)
+			dialog.ShowError(ErrShowSchedules, w)
+			return
 		}
 		w.SetContent(cont)
-	})
-	reportThird := fyne.NewMenuItem("Отчёт 3", func() {
-		cont, err := MainReportThird(w, db)
+	}))
+
+	reportMenu := fyne.NewMenu("Отчёт", reportItems...)
+
+	home := fyne.NewMenuItem("Главная", func() {
+		homeContent, err := MainDashboard(w, db, role)
 		if err != nil {
-			dialog.ShowError(ErrReport, w)
+			dialog.ShowError(ErrShowDashboard, w)
+			return
 		}
-		w.SetContent(cont)
+		w.SetContent(homeContent)
 	})
-
-	reportMenu := fyne.NewMenu("Отчёт", reportFirst, reportSecond, reportThird)
-
 	jorney := fyne.NewMenuItem("Балансы", func() {
 		jorneyContent, err := MainJorney(w, db, role)
 		if err != nil {
@@ -49,7 +73,26 @@ func MainMenu(myApp fyne.App, w fyne.Window, db database.Service, role string) {
 		}
 		w.SetContent(jorneyContent)
 	})
-	jorneyMenu := fyne.NewMenu("Журнал", jorney)
+	integrity := fyne.NewMenuItem("Проверка балансов", func() {
+		integrityContent, err := MainIntegrity(w, db, role)
+		if err != nil {
+			dialog.ShowError(ErrShowIntegrity, w)
+			return
+		}
+		w.SetContent(integrityContent)
+	})
+	jorneyMenu := fyne.NewMenu("Журнал", home, fyne.NewMenuItemSeparator(), jorney, integrity)
+	if role == "admin" {
+		closing := fyne.NewMenuItem("Закрытие периодов", func() {
+			closingContent, err := MainClosing(w, db, role)
+			if err != nil {
+				dialog.ShowError(ErrShowClosing, w)
+				return
+			}
+			w.SetContent(closingContent)
+		})
+		jorneyMenu.Items = append(jorneyMenu.Items, closing)
+	}
 
 	dir := fyne.NewMenuItem("Справочник", func() {
 		dirContent, err := MainDir(w, db, role)
@@ -59,6 +102,17 @@ func MainMenu(myApp fyne.App, w fyne.Window, db database.Service, role string) {
 		w.SetContent(dirContent)
 	})
 	dirMenu := fyne.NewMenu("Справочник", dir)
+	if role == "admin" {
+		trash := fyne.NewMenuItem("Корзина", func() {
+			trashContent, err := MainTrash(w, db, role)
+			if err != nil {
+				dialog.ShowError(ErrShowTrash, w)
+				return
+			}
+			w.SetContent(trashContent)
+		})
+		dirMenu.Items = append(dirMenu.Items, fyne.NewMenuItemSeparator(), trash)
+	}
 
 	infoContent := fyne.NewMenuItem("Информация", func() {
 		aboutWindow := createAboutWindow(myApp)
@@ -66,6 +120,26 @@ func MainMenu(myApp fyne.App, w fyne.Window, db database.Service, role string) {
 	})
 	infoMenu := fyne.NewMenu("О приложении", infoContent)
 
+	users := fyne.NewMenuItem("Пользователи", func() {
+		usersContent, err := MainUsers(w, db, role)
+		if err != nil {
+			dialog.ShowError(ErrShowUsers, w)
+			return
+		}
+		w.SetContent(usersContent)
+	})
+	adminMenu := fyne.NewMenu("Администрирование", users)
+
+	twoFactor := fyne.NewMenuItem("Двухфакторная аутентификация", func() {
+		twoFactorContent, err := MainTwoFactor(w, db, username)
+		if err != nil {
+			dialog.ShowError(ErrShowTwoFactor, w)
+			return
+		}
+		w.SetContent(twoFactorContent)
+	})
+	accountMenu := fyne.NewMenu("Учётная запись", twoFactor)
+
 	exit := fyne.NewMenuItem("Выход", func() {
 		dialog.ShowConfirm("Выход", "Вы уверены, что хотите выйти из приложения?",
 			func(bool) {
@@ -77,7 +151,23 @@ func MainMenu(myApp fyne.App, w fyne.Window, db database.Service, role string) {
 	})
 	exitMenu := fyne.NewMenu("Выход", exit)
 
-	w.SetMainMenu(fyne.NewMainMenu(jorneyMenu, dirMenu, reportMenu, infoMenu, exitMenu))
+	householdMenu, err := HouseholdMenu(myApp, w, db, username, role)
+	if err != nil {
+		dialog.ShowError(ErrGetHouseholds, w)
+		householdMenu = fyne.NewMenu("Бюджет")
+	}
+
+	menus := []*fyne.Menu{householdMenu}
+	if role == "admin" {
+		menus = append(menus, EditMenu(w, db, role))
+	}
+	menus = append(menus, jorneyMenu, dirMenu, reportMenu)
+	if role == "admin" {
+		menus = append(menus, adminMenu)
+	}
+	menus = append(menus, accountMenu, infoMenu, exitMenu)
+
+	w.SetMainMenu(fyne.NewMainMenu(menus...))
 }
 
 func createAboutWindow(app fyne.App) fyne.Window {
@@ -89,26 +179,84 @@ func createAboutWindow(app fyne.App) fyne.Window {
 	
 	1. Общий концепт - приложение предоставляет интерфейс для просмотра и редактирования операций 
 	с информацией о статье, доходе, расходе и дате. Статьи выбираются из списка ранее добавленных.
-	В конце месяца доступна функция формирования баланса за месяц с информацией о расходах, доходах
-	и прибыли за месяц.
+	По окончании периода доступна функция формирования баланса за период с информацией о расходах,
+	доходах и прибыли. Период баланса - неделя, месяц, квартал, год или произвольный отрезок;
+	периоды балансов не пересекаются и идут друг за другом без разрывов.
 	2. Предоставлено три вкладки - Журнал, Справочник, Отчёты.
+	После входа открывается главная (Журнал - Главная): доход, расход и прибыль текущего месяца,
+	топ-5 статей расходов, операции вне балансов, последний баланс и график прибыли за год.
+	Плитки обновляются сами при изменении данных, нажатие на плитку открывает нужный раздел.
 	3. В разделе Журнал предоставлен следующий интерфейс:
 	  3.1. Просмотр сформированных балансов.
-	  3.2. Просмотр сводных данных о доходах и расходах.
+	  3.2. Просмотр сводных данных о доходах и расходах, денег на руках на любую дату.
+	  Деньги на руках считаются от начального остатка, который задаёт администратор,
+	  и показываются в таблице балансов и отчёте 3.
 	  3.3. Формирование и расформирование балансов [admin]
+	  3.4. Повторное открытие закрытого периода для исправлений с указанием причины
+	  и его закрытие с пересчётом итогов баланса [admin]
+	  3.5. Проверка балансов: расхождения итогов с операциями, ссылки на несуществующие
+	  балансы и неучтённые операции закрытых периодов; исправление одной транзакцией [admin]
+	  3.6. Закрытие периодов: все незакрытые периоды до выбранной даты закрываются по порядку,
+	  предпросмотр показывает, какие периоды не пройдут порог минимального профита [admin]
 	4. В разделе Справочник предоставлен следующий интерфейс:
 	  4.1. Вкладка статей с возможностью добавить, редактировать, удалить статью
+	  Статье можно задать метку (например, "обязательные"), чтобы группировать по ней в конструкторе отчётов
 	  4.2. Вкладка операций с возможностью добавить, редактировать, удалить операцию
+	  4.3. Отмена (Ctrl+Z) и повтор (Ctrl+Y) правок справочника в течение сессии,
+	  если период операций ещё не закрыт балансом [admin]
+	  4.4. Корзина: удалённые статьи, операции и балансы можно восстановить
+	  (статья восстанавливается вместе со своими операциями) или удалить окончательно.
+	  Старые записи очищаются вручную из корзины; автоочистку при запуске включает
+	  срок хранения trash_days в config.ini (по умолчанию выключена) [admin]
 	5. В разделе отчёты предоставлен следующий интерфейс:
 	  5.1. Выбор типа отчёта из возможных
 	  5.2. Введение данных для формирования по ним отчёта
-	  5.3. Сохранение документа сформированного отчёта
-	
+	  По кнопке Превью рядом с таблицей строится график: вид (линии, столбцы, области)
+	  и серии переключаются, при наведении показываются точные значения
+	  Отчёт 2 показывает доли статей круговой или кольцевой диаграммой, статьи с малой
+	  долей объединяются в сектор "Прочее"
+	  5.3. Сохранение документа сформированного отчёта в PDF, CSV, XLSX или HTML.
+	  В XLSX числа и даты сохраняются с форматом, строка заголовков закреплена,
+	  параметры отчёта - на отдельном листе. Таблицы статей, операций и балансов
+	  тоже сохраняются в XLSX кнопкой под панелью действий.
+	  HTML - один файл для браузера или письма: параметры и время формирования в шапке,
+	  таблица и график внутри страницы.
+	  PDF начинается с шапки (название, параметры, кто и когда сформировал), таблица
+	  переносится на следующие страницы с повтором заголовков, в конце - итоги
+	  (и подытоги по первой колонке в конструкторе), затем график; внизу номера страниц.
+	  PDF использует встроенный шрифт с кириллицей; свой TTF-шрифт можно указать
+	  в config.ini (pdf_font и pdf_font_bold)
+	  5.4. Сравнение двух периодов (например, год к году или месяц к месяцу) по выбранным
+	  статьям: изменение в деньгах и процентах по каждой статье и в итоге, график,
+	  сохранение в PDF, CSV, XLSX и HTML
+	  5.5. Конструктор отчётов: группировка по статье, месяцу, неделе, метке или балансу,
+	  показатели (доход, расход, прибыль, число операций, средние) и фильтры по периоду,
+	  статьям и сумме операции. Отчёты сохраняются под именем для каждого пользователя
+	  5.6. Прогноз доходов, расходов и денег на руках на несколько месяцев вперёд
+	  по регулярным платежам и средним значениям прошлых лет, с доверительным интервалом
+	  и сохранением в PDF, CSV, XLSX и HTML. Регулярные платежи задаёт администратор [admin]
+	  5.7. Расписания отчётов: отчёт строится ежедневно, еженедельно или ежемесячно
+	  за относительный период (текущий или прошлый месяц, прошлый квартал, с начала года и др.)
+	  и сохраняется в выбранную папку в нужном формате. Пока приложение открыто, расписания
+	  проверяются каждые несколько минут; без GUI их выполняет команда "run-schedules"
+	  5.8. В каждом отчёте период выбирается одним нажатием (текущий и прошлый месяц,
+	  с начала квартала, последние 12 месяцев и др.) или вводится вручную. Параметры
+	  (период, статьи, тип потока) сохраняются набором под именем для каждого пользователя;
+	  относительный период при выборе набора пересчитывается от текущей даты
+	6. В разделе Администрирование [admin] предоставлен следующий интерфейс:
+	  6.1. Просмотр истории входов
+	  6.2. Разблокировка пользователей после неудачных попыток входа
+	  6.3. Журнал изменений данных: кто, когда и что изменил
+	7. В меню Бюджет выбирается активный бюджет (домохозяйство). Статьи, операции и
+	балансы каждого бюджета видны только его участникам.
+	8. В разделе Учётная запись можно подключить двухфакторную аутентификацию
+	через приложение-аутентификатор (TOTP) и получить коды восстановления.
+
 	Обратите внимание: 
 	- Все данные сохраняются автоматически.
 	- Для получения возможностей редактирования нелбходимо иметь роль администратора
-	- Сформированный баланс характеризует закрытый период, то есть нельзя редактировать
-	операции, который входят в закрытый период.
+	- Сформированный баланс характеризует закрытый период, то есть нельзя добавлять, редактировать
+	и удалять операции, который входят в закрытый период, пока он не открыт повторно.
 
 	Для вопросов и поддержки обратитесь к разработчику.`
 
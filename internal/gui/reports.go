@@ -2,766 +2,457 @@ package gui
 
 import (
 	"context"
-	"fmt"
-	"image/color"
+	"errors"
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/widget"
 	"github.com/EmptyInsid/db_gui/internal/database"
-	"github.com/signintech/gopdf"
-	"gonum.org/v1/plot"
-	"gonum.org/v1/plot/plotter"
+	"github.com/EmptyInsid/db_gui/internal/report"
 )
 
-func MainReportFirst(w fyne.Window, db database.Service) (*fyne.Container, error) {
+// ошибки загрузки данных по отчётам, остальные показывают ErrReport
+var reportErrors = map[string]error{
+	report.IncomeExpenseDynamics.ID: ErrIncomeExpence,
+	report.FinancialPercentages.ID:  ErrFinPercTable,
+	report.TotalProfitDate.ID:       ErrTotalProfTable,
+	report.Comparison.ID:            ErrComparison,
+	report.Forecast.ID:              ErrForecast,
+}
+
+// дополнительные вкладки экрана отчёта
+var reportTabs = map[string]func(w fyne.Window, db database.Service, role string) (*container.TabItem, error){
+	report.Forecast.ID: RecurringTab,
+}
 
-	title := MadeTitle("Отчёт 1 Динамика изменения расходов и доходов.")
-	articlesContainer, addArticleButton, delArticleButton, err := MadeArticlesButton(db)
+// MainReport - экран отчёта из реестра: форма параметров, таблица, график и выгрузки
+func MainReport(w fyne.Window, db database.Service, role string, def *report.Definition) (*fyne.Container, error) {
+	screen, err := ReportScreen(w, db, def)
 	if err != nil {
 		return nil, err
 	}
-	startDate, endDate := MadeDateFields()
-
-	inputContainer := container.NewVBox(
-		title,
-		widget.NewLabel("Введите параметры:"),
-		widget.NewLabel("Начальная дата:"),
-		startDate,
-		widget.NewLabel("Конечная дата:"),
-		endDate,
-		widget.NewLabel("Статьи:"),
-		articlesContainer,
-		addArticleButton,
-		delArticleButton,
-	)
-
-	tableContainer := container.NewStack()
-	updateTable := func() {
-		articles := LoadArticles(articlesContainer)
-
-		if err := CompareDate(startDate.Text, endDate.Text); err != nil {
-			dialog.ShowError(ErrEndLessStart, w)
-			return
-		}
 
-		newTable, err := IncomeExpenseDynamicsTable(db, articles, startDate.Text, endDate.Text)
-		if err != nil {
-			dialog.ShowError(ErrIncomeExpence, w)
-			return
-		}
-		tableContainer.Objects = []fyne.CanvasObject{newTable}
-		tableContainer.Refresh() // Обновление отображения
+	extraTab, ok := reportTabs[def.ID]
+	if !ok {
+		return container.NewStack(screen), nil
+	}
+	extra, err := extraTab(w, db, role)
+	if err != nil {
+		return nil, err
 	}
 
-	previewButton := widget.NewButton("Превью", nil)
-	previewButton.OnTapped = updateTable
-
-	saveButton := widget.NewButton("Сохранить", func() {
-		dialog.ShowFileSave(
-
-			func(uc fyne.URIWriteCloser, err error) {
-				if err != nil {
-					dialog.ShowError(ErrSaveFile, w)
-					return
-				}
-				if uc == nil {
-					return // Пользователь отменил выбор
-				}
-
-				defer uc.Close()
-
-				if err := CompareDate(startDate.Text, endDate.Text); err != nil {
-					dialog.ShowError(ErrEndLessStart, w)
-					return
-				}
-
-				articles := LoadArticles(articlesContainer)
-				data, err := db.GetIncomeExpenseDynamics(context.Background(), articles, startDate.Text, endDate.Text)
-				if err != nil {
-					dialog.ShowError(ErrIncomeExpence, w)
-					return
-				}
-
-				// Получаем путь к файлу
-				filename := uc.URI().Path()
-
-				// Проверяем расширение и добавляем, если отсутствует
-				if filepath.Ext(filename) != ".pdf" {
-					filename += ".pdf"
-				}
-
-				// Сохраняем в PDF
-				err = SaveToPDFFirst(data, filename)
-				if err != nil {
-					log.Printf("Error while save first to pdf %v", err)
-					dialog.ShowError(ErrSaveFile, w)
-				} else {
-					dialog.ShowInformation("Успех", "PDF успешно сохранён!", w)
-				}
-			}, w)
-	})
-
-	toolbar := container.NewHBox(previewButton, saveButton)
-	rightPane := container.NewVBox(inputContainer, toolbar)
-	mainContent := container.NewHSplit(tableContainer, rightPane)
-	mainContent.SetOffset(0.7) // Устанавливает пропорцию (70% для таблицы, 30% для правой панели)
-
-	// Основной контейнер
-	mainContainer := container.NewBorder(
-		nil,         // Верхняя часть
-		nil,         // Нижняя часть
-		nil,         // Левая часть
-		nil,         // Правая часть
-		mainContent, // Центральный контент
-	)
-
-	return mainContainer, nil
+	tab := container.NewAppTabs(container.NewTabItem(def.Name, screen), extra)
+	tab.SetTabLocation(container.TabLocationTop)
+	return container.NewStack(tab), nil
 }
 
-func MainReportSecond(w fyne.Window, db database.Service) (*fyne.Container, error) {
-	title := MadeTitle("Отчёт 2 Процентное соотношение финансовых потоков по статьям.")
-	articlesContainer, addArticleButton, delArticleButton, err := MadeArticlesButton(db)
+func ReportScreen(w fyne.Window, db database.Service, def *report.Definition) (*container.Split, error) {
+	form, values, err := ReportForm(w, db, def)
 	if err != nil {
 		return nil, err
 	}
-	startDate, endDate := MadeDateFields()
-
-	flow := widget.NewSelect([]string{"расход", "доход", "прибыль"}, func(value string) {
-		log.Printf("Set flow: %s\n", value)
-	})
-
-	inputContainer := container.NewVBox(
-		title,
-		widget.NewLabel("Введите параметры:"),
-		widget.NewLabel("Начальная дата:"),
-		startDate,
-		widget.NewLabel("Конечная дата:"),
-		endDate,
-		widget.NewLabel("Тип потока:"),
-		flow,
-		widget.NewLabel("Статьи:"),
-		articlesContainer,
-		addArticleButton,
-		delArticleButton,
-	)
-	tableContainer := container.NewStack()
+	return ReportPane(w, db, form, func() (*report.Definition, report.Values, error) {
+		return def, values(), nil
+	}), nil
+}
 
-	// Функция обновления таблицы
-	updateTable := func() {
-		if err := CompareDate(startDate.Text, endDate.Text); err != nil {
-			dialog.ShowError(ErrEndLessStart, w)
-			return
+// ReportPane - форма справа, таблица и график слева, кнопки превью и выгрузок.
+// build возвращает описание отчёта и параметры на момент нажатия кнопки
+func ReportPane(w fyne.Window, db database.Service, form fyne.CanvasObject, build func() (*report.Definition, report.Values, error)) *container.Split {
+	run := func() (*report.Definition, *report.Result, bool) {
+		def, values, err := build()
+		if err != nil {
+			dialog.ShowError(err, w)
+			return nil, nil, false
 		}
-
-		articles := LoadArticles(articlesContainer)
-
-		newTable, err := FinancialPercentagesTable(db, articles, TranslateFlow(flow.Selected), startDate.Text, endDate.Text)
+		result, err := def.Run(context.Background(), db, values)
 		if err != nil {
-			dialog.ShowError(ErrFinPercTable, w)
-			return
+			log.Printf("Error while run report %s: %v", def.ID, err)
+			dialog.ShowError(reportError(def, err), w)
+			return nil, nil, false
 		}
-
-		tableContainer.Objects = []fyne.CanvasObject{newTable}
-		tableContainer.Refresh() // Обновление отображения
+		return def, result, true
 	}
-	previewButton := widget.NewButton("Превью", nil)
-	previewButton.OnTapped = updateTable
-
-	saveButton := widget.NewButton("Сохранить", func() {
-		dialog.ShowFileSave(
-
-			func(uc fyne.URIWriteCloser, err error) {
-				if err != nil {
-					dialog.ShowError(ErrSaveFile, w)
-					return
-				}
-				if uc == nil {
-					return // Пользователь отменил выбор
-				}
-
-				defer uc.Close()
-
-				if err := CompareDate(startDate.Text, endDate.Text); err != nil {
-					dialog.ShowError(ErrEndLessStart, w)
-					return
-				}
 
-				articles := LoadArticles(articlesContainer)
-				ctx := context.Background()
-				data, err := db.GetFinancialPercentages(ctx, articles, TranslateFlow(flow.Selected), startDate.Text, endDate.Text)
-				if err != nil {
-					dialog.ShowError(ErrFinPercTable, w)
-					return
-				}
-
-				// Получаем путь к файлу
-				filename := uc.URI().Path()
-
-				// Проверяем расширение и добавляем, если отсутствует
-				if filepath.Ext(filename) != ".pdf" {
-					filename += ".pdf"
-				}
-
-				// Сохраняем в PDF
-				err = SaveToPDFSecond(data, filename)
-				if err != nil {
-					log.Printf("jkasdfkahfg %v", err)
-					dialog.ShowError(ErrSaveFile, w)
-				} else {
-					dialog.ShowInformation("Успех", "PDF успешно сохранён!", w)
-				}
-			}, w)
-	})
-
-	toolbar := container.NewHBox(previewButton, saveButton)
-	rightPane := container.NewVBox(inputContainer, toolbar)
-
-	// Центральная область: таблица
-	mainContent := container.NewHSplit(tableContainer, rightPane)
-	mainContent.SetOffset(0.7) // Устанавливает пропорцию (70% для таблицы, 30% для правой панели)
-
-	// Основной контейнер
-	mainContainer := container.NewBorder(
-		nil,         // Верхняя часть
-		nil,         // Нижняя часть
-		nil,         // Левая часть
-		nil,         // Правая часть
-		mainContent, // Центральный контент
-	)
-
-	return mainContainer, nil
-}
-func MainReportThird(w fyne.Window, db database.Service) (*fyne.Container, error) {
-	title := MadeTitle("Отчёт 3 Чистая прибыль бюджета от времени.")
-	startDate, endDate := MadeDateFields()
-
-	inputContainer := container.NewVBox(
-		title,
-		widget.NewLabel("Введите параметры:"),
-		widget.NewLabel("Начальная дата:"),
-		startDate,
-		widget.NewLabel("Конечная дата:"),
-		endDate,
-	)
-
-	previewButton := widget.NewButton("Превью", nil)
 	tableContainer := container.NewStack()
+	chartContainer := container.NewStack()
 
-	updateTable := func() {
-		if err := CompareDate(startDate.Text, endDate.Text); err != nil {
-			dialog.ShowError(ErrEndLessStart, w)
+	previewButton := widget.NewButton("Превью", func() {
+		def, result, ok := run()
+		if !ok {
 			return
 		}
 
-		newTable, err := TotalProfitDateTable(db, startDate.Text, endDate.Text)
-		if err != nil {
-			dialog.ShowError(ErrTotalProfTable, w)
-			return
+		tableContainer.Objects = []fyne.CanvasObject{ReportTable(def, result)}
+		tableContainer.Refresh() // Обновление отображения
+
+		chartContainer.Objects = nil
+		if def.Chart != nil {
+			chartContainer.Objects = []fyne.CanvasObject{InteractiveChart(def, result)}
 		}
+		chartContainer.Refresh()
+	})
 
-		tableContainer.Objects = []fyne.CanvasObject{newTable}
-		tableContainer.Refresh()
+	toolbar := container.NewHBox(previewButton)
+	for _, exporter := range report.Exporters {
+		toolbar.Add(widget.NewButton("Сохранить "+exporter.Name, func() {
+			// отчёт строится до выбора файла, чтобы при ошибке не оставлять пустой файл
+			def, result, ok := run()
+			if !ok {
+				return
+			}
+
+			save := dialog.NewFileSave(
+				func(uc fyne.URIWriteCloser, err error) {
+					if err != nil {
+						dialog.ShowError(ErrSaveFile, w)
+						return
+					}
+					if uc == nil {
+						return // Пользователь отменил выбор
+					}
+
+					if err := saveReport(uc, exporter, def, result); err != nil {
+						log.Printf("Error while save report %s to %s: %v", def.ID, exporter.Name, err)
+						dialog.ShowError(ErrSaveFile, w)
+					} else {
+						dialog.ShowInformation("Успех", exporter.Name+" успешно сохранён!", w)
+					}
+				}, w)
+			save.SetFileName(def.ID + exporter.Extension)
+			save.SetFilter(storage.NewExtensionFileFilter([]string{exporter.Extension}))
+			save.Show()
+		}))
 	}
 
-	previewButton.OnTapped = updateTable
-	saveButton := widget.NewButton("Сохранить", func() {
-		dialog.ShowFileSave(
-
-			func(uc fyne.URIWriteCloser, err error) {
-
-				if err != nil {
-					dialog.ShowError(ErrSaveFile, w)
-					return
-				}
-				if uc == nil {
-					return // Пользователь отменил выбор
-				}
-
-				defer uc.Close()
-
-				if err := CompareDate(startDate.Text, endDate.Text); err != nil {
-					dialog.ShowError(ErrEndLessStart, w)
-					return
-				}
-
-				ctx := context.Background()
-				data, err := db.GetTotalProfitDate(ctx, startDate.Text, endDate.Text)
-				if err != nil {
-					dialog.ShowError(ErrTotalProfTable, w)
-					return
-				}
-
-				// Получаем путь к файлу
-				filename := uc.URI().Path()
-
-				// Проверяем расширение и добавляем, если отсутствует
-				if filepath.Ext(filename) != ".pdf" {
-					filename += ".pdf"
-				}
-
-				// Сохраняем в PDF
-				err = SaveToPDFThird(data, filename)
-				if err != nil {
-					log.Printf("jkasdfkahfg %v", err)
-					dialog.ShowError(ErrSaveFile, w)
-				} else {
-					dialog.ShowInformation("Успех", "PDF успешно сохранён!", w)
-				}
-			}, w)
-	})
-
-	toolbar := container.NewHBox(previewButton, saveButton)
-	rightPane := container.NewVBox(inputContainer, toolbar)
-	mainContent := container.NewHSplit(tableContainer, rightPane)
+	rightPane := container.NewVScroll(container.NewVBox(form, toolbar))
+	leftPane := container.NewVSplit(tableContainer, chartContainer)
+	mainContent := container.NewHSplit(leftPane, rightPane)
 	mainContent.SetOffset(0.7) // Устанавливает пропорцию (70% для таблицы, 30% для правой панели)
 
-	// Основной контейнер
-	mainContainer := container.NewBorder(
-		nil,         // Верхняя часть
-		nil,         // Нижняя часть
-		nil,         // Левая часть
-		nil,         // Правая часть
-		mainContent, // Центральный контент
-	)
-
-	return mainContainer, nil
+	return mainContent
 }
 
-func SaveToPDFFirst(data []database.DateTotalMoney, filename string) error {
-
-	pdf, err := createPdf()
-	if err != nil {
-		return err
-	}
-
-	headers := []string{"Дата", "Общий расход", "Общий доход"}
-	tableStartY := 10.0
-	marginLeft := 10.0
-
-	// Create a new table layout
-	table := pdf.NewTableLayout(marginLeft, tableStartY, 25, 5)
-	for _, row := range headers {
-		table.AddColumn(row, 100, "left")
-	}
-	// Add rows to the table
-	for _, row := range data {
-		if row.Date.Format("2006-01-02") != "" {
-			table.AddRow([]string{
-				row.Date.Format("2006-01-02"),
-				fmt.Sprintf("%.2f", row.TotalCredit),
-				fmt.Sprintf("%.2f", row.TotalDebit),
-			})
+// сохранить отчёт в файл, выбранный в диалоге. Документ пишется в открытый диалогом поток;
+// если расширение не указано, пустой файл диалога удаляется и отчёт пишется в файл с расширением
+func saveReport(uc fyne.URIWriteCloser, exporter report.Exporter, def *report.Definition, result *report.Result) error {
+	filename := uc.URI().Path()
+	if filepath.Ext(filename) != exporter.Extension {
+		uc.Close()
+		if err := os.Remove(filename); err != nil {
+			log.Printf("Error while remove empty file %s: %v", filename, err)
 		}
-		log.Println(row.Date.Format("2006-01-02"))
-
+		return exporter.Write(def, result, filename+exporter.Extension)
 	}
 
-	table.SetTableStyle(gopdf.CellStyle{
-		BorderStyle: gopdf.BorderStyle{
-			Top:    true,
-			Left:   true,
-			Bottom: true,
-			Right:  true,
-			Width:  1.0,
-		},
-		FillColor: gopdf.RGBColor{R: 255, G: 0, B: 255},
-		TextColor: gopdf.RGBColor{R: 0, G: 0, B: 0},
-		FontSize:  10,
-	})
-
-	// Set the style for table header
-	table.SetHeaderStyle(gopdf.CellStyle{
-		BorderStyle: gopdf.BorderStyle{
-			Top:      true,
-			Left:     true,
-			Bottom:   true,
-			Right:    true,
-			Width:    2.0,
-			RGBColor: gopdf.RGBColor{R: 100, G: 150, B: 255},
-		},
-		FillColor: gopdf.RGBColor{R: 255, G: 200, B: 200},
-		TextColor: gopdf.RGBColor{R: 255, G: 100, B: 100},
-		Font:      "Arial",
-		FontSize:  12,
-	})
-
-	table.SetCellStyle(gopdf.CellStyle{
-		BorderStyle: gopdf.BorderStyle{
-			Top:      true,
-			Left:     true,
-			Bottom:   true,
-			Right:    true,
-			Width:    0.5,
-			RGBColor: gopdf.RGBColor{R: 0, G: 0, B: 0},
-		},
-		FillColor: gopdf.RGBColor{R: 255, G: 255, B: 255},
-		TextColor: gopdf.RGBColor{R: 0, G: 0, B: 0},
-		Font:      "Arial",
-		FontSize:  10,
-	})
-
-	// Draw the table
-	table.DrawTable()
-
-	// Сохраняем график как изображение
-	plotFile := "chart.png"
-	err = createFirstPlot(data, plotFile)
-	if err != nil {
-		return fmt.Errorf("ошибка создания графика: %v", err)
-	}
-
-	// Добавляем новую страницу в PDF
-	pdf.AddPage()
-
-	// Добавляем график на новую страницу
-	pdf.Image(plotFile, 20, 50, &gopdf.Rect{W: 400, H: 400}) // Размещение графика на второй странице
-
-	// Сохраняем PDF в файл
-	err = pdf.WritePdf(filename)
-	if err != nil {
-		return fmt.Errorf("ошибка сохранения PDF: %v", err)
+	if err := exporter.Render(def, result, uc); err != nil {
+		uc.Close()
+		os.Remove(filename)
+		return err
 	}
-
-	os.Remove(plotFile)
-
-	return nil
+	return uc.Close()
 }
 
-func SaveToPDFSecond(data []database.FinancialPercentage, filename string) error {
+// ReportForm строит поля по параметрам отчёта и возвращает функцию чтения введённых значений.
+// Период выбирается быстрым пресетом, наборы параметров сохраняются для пользователя
+func ReportForm(w fyne.Window, db database.Service, def *report.Definition) (*fyne.Container, func() report.Values, error) {
+	ctx := context.Background()
 
-	// Создаём новый PDF
-	pdf := &gopdf.GoPdf{}
-	pdf.Start(gopdf.Config{PageSize: *gopdf.PageSizeA4}) // Размер страницы A4
-	pdf.AddPage()
-
-	err := pdf.AddTTFFont("Arial", "C:/Windows/Fonts/arial.ttf")
-	if err != nil {
-		return fmt.Errorf("ошибка добавления шрифта: %v", err)
-	}
-
-	// Устанавливаем шрифт и его размер
-	err = pdf.SetFont("Arial", "", 12)
+	fields, values, apply, err := ReportFields(w, db, def, true)
 	if err != nil {
-		return fmt.Errorf("ошибка установки шрифта: %v", err)
+		return nil, nil, err
 	}
 
-	headers := []string{"Статья", "Общий расход", "Общий доход", "Прибыль", "Процент"}
-	// Set the starting Y position for the table
-	tableStartY := 10.0
-	// Set the left margin for the table
-	marginLeft := 10.0
-
-	// Create a new table layout
-	table := pdf.NewTableLayout(marginLeft, tableStartY, 25, 5)
-
-	// Add columns to the table
-	for _, row := range headers {
-		table.AddColumn(row, 100, "left")
-	}
+	form := container.NewVBox(MadeTitle(def.Title))
 
-	// Add rows to the table
-	for _, row := range data {
-		if row.ArticleName != "" {
-			table.AddRow([]string{
-				row.ArticleName,
-				fmt.Sprintf("%.2f", row.TotalCredit),
-				fmt.Sprintf("%.2f", row.TotalDebit),
-				fmt.Sprintf("%.2f", row.TotalProfit),
-				fmt.Sprintf("%.2f", row.TotalProc),
-			})
+	// относительный период подставляется во все периоды отчёта
+	var periodSelect *widget.Select
+	applyPreset := func(preset string) {
+		if preset == "" {
+			return
 		}
+		v, err := def.PresetValues(preset, values(), time.Now())
+		if err != nil {
+			dialog.ShowError(ErrParseDate, w)
+			return
+		}
+		apply(v)
 	}
-
-	// Set the style for table cells
-	table.SetTableStyle(gopdf.CellStyle{
-		BorderStyle: gopdf.BorderStyle{
-			Top:    true,
-			Left:   true,
-			Bottom: true,
-			Right:  true,
-			Width:  1.0,
-		},
-		FillColor: gopdf.RGBColor{R: 255, G: 255, B: 255},
-		TextColor: gopdf.RGBColor{R: 0, G: 0, B: 0},
-		FontSize:  10,
-	})
-
-	// Set the style for table header
-	table.SetHeaderStyle(gopdf.CellStyle{
-		BorderStyle: gopdf.BorderStyle{
-			Top:      true,
-			Left:     true,
-			Bottom:   true,
-			Right:    true,
-			Width:    2.0,
-			RGBColor: gopdf.RGBColor{R: 100, G: 150, B: 255},
-		},
-		FillColor: gopdf.RGBColor{R: 255, G: 200, B: 200},
-		TextColor: gopdf.RGBColor{R: 255, G: 100, B: 100},
-		Font:      "font2",
-		FontSize:  12,
-	})
-
-	table.SetCellStyle(gopdf.CellStyle{
-		BorderStyle: gopdf.BorderStyle{
-			Top:      true,
-			Left:     true,
-			Bottom:   true,
-			Right:    true,
-			Width:    0.5,
-			RGBColor: gopdf.RGBColor{R: 0, G: 0, B: 0},
-		},
-		FillColor: gopdf.RGBColor{R: 255, G: 255, B: 255},
-		TextColor: gopdf.RGBColor{R: 0, G: 0, B: 0},
-		Font:      "font1",
-		FontSize:  10,
-	})
-
-	// Draw the table
-	table.DrawTable()
-
-	// Сохраняем PDF в файл
-	err = pdf.WritePdf(filename)
-	if err != nil {
-		return fmt.Errorf("ошибка сохранения PDF: %v", err)
+	if hasPeriods(def) {
+		periodSelect = MadePresetSelect(applyPreset)
 	}
 
-	return nil
-}
-
-func SaveToPDFThird(data []database.DateProfit, filename string) error {
-
-	pdf, err := createPdf()
-	if err != nil {
-		return err
+	presetName := widget.NewEntry()
+	presetName.SetPlaceHolder("название набора")
+
+	var saved []database.ReportPreset
+	savedSelect := widget.NewSelect(nil, func(name string) {
+		for _, item := range saved {
+			if item.Name != name {
+				continue
+			}
+			presetName.SetText(item.Name)
+			apply(item.Values)
+			if periodSelect != nil {
+				periodSelect.SetSelected(presetLabel(item.Period))
+				applyPreset(item.Period)
+			}
+			return
+		}
+	})
+	savedSelect.PlaceHolder = "сохранённые параметры"
+	loadSaved := func() error {
+		var err error
+		saved, err = db.GetReportPresets(ctx, def.ID)
+		if err != nil {
+			return err
+		}
+		names := make([]string, len(saved))
+		for i, item := range saved {
+			names[i] = item.Name
+		}
+		savedSelect.Options = names
+		savedSelect.ClearSelected()
+		savedSelect.Refresh()
+		return nil
 	}
-
-	headers := []string{"Дата", "Прибыль"}
-	tableStartY := 10.0
-	marginLeft := 10.0
-
-	// Create a new table layout
-	table := pdf.NewTableLayout(marginLeft, tableStartY, 25, 5)
-	for _, row := range headers {
-		table.AddColumn(row, 100, "left")
+	if err := loadSaved(); err != nil {
+		return nil, nil, err
 	}
 
-	for _, row := range data {
-		if row.Date.Format("2006-01-02") != "" {
-			table.AddRow([]string{
-				row.Date.Format("2006-01-02"),
-				fmt.Sprintf("%.2f", row.TotalProfit)})
+	savePresetButton := widget.NewButton("Сохранить параметры", func() {
+		name := strings.TrimSpace(presetName.Text)
+		if name == "" {
+			dialog.ShowError(ErrEmptyPresetName, w)
+			return
 		}
-	}
-
-	table.SetTableStyle(gopdf.CellStyle{
-		BorderStyle: gopdf.BorderStyle{
-			Top:    true,
-			Left:   true,
-			Bottom: true,
-			Right:  true,
-			Width:  1.0,
-		},
-		FillColor: gopdf.RGBColor{R: 255, G: 255, B: 255},
-		TextColor: gopdf.RGBColor{R: 0, G: 0, B: 0},
-		FontSize:  10,
-	})
-
-	// Set the style for table header
-	table.SetHeaderStyle(gopdf.CellStyle{
-		BorderStyle: gopdf.BorderStyle{
-			Top:      true,
-			Left:     true,
-			Bottom:   true,
-			Right:    true,
-			Width:    2.0,
-			RGBColor: gopdf.RGBColor{R: 100, G: 150, B: 255},
-		},
-		FillColor: gopdf.RGBColor{R: 255, G: 200, B: 200},
-		TextColor: gopdf.RGBColor{R: 255, G: 100, B: 100},
-		Font:      "Arial",
-		FontSize:  12,
+		preset := database.ReportPreset{ReportID: def.ID, Name: name, Values: values()}
+		if periodSelect != nil {
+			preset.Period = matchedPreset(def, presetKey(periodSelect.Selected), preset.Values)
+		}
+		if err := db.SaveReportPreset(ctx, preset); err != nil {
+			dialog.ShowError(ErrSavePreset, w)
+			return
+		}
+		if err := loadSaved(); err != nil {
+			dialog.ShowError(ErrGetPresets, w)
+			return
+		}
+		dialog.ShowInformation("Параметры отчёта", "Параметры сохранены!", w)
 	})
 
-	table.SetCellStyle(gopdf.CellStyle{
-		BorderStyle: gopdf.BorderStyle{
-			Top:      true,
-			Left:     true,
-			Bottom:   true,
-			Right:    true,
-			Width:    0.5,
-			RGBColor: gopdf.RGBColor{R: 0, G: 0, B: 0},
-		},
-		FillColor: gopdf.RGBColor{R: 255, G: 255, B: 255},
-		TextColor: gopdf.RGBColor{R: 0, G: 0, B: 0},
-		Font:      "Arial",
-		FontSize:  10,
+	deletePresetButton := widget.NewButton("Удалить параметры", func() {
+		for _, item := range saved {
+			if item.Name != savedSelect.Selected {
+				continue
+			}
+			if err := db.DeleteReportPreset(ctx, item.ID); err != nil {
+				dialog.ShowError(ErrDelPreset, w)
+				return
+			}
+			if err := loadSaved(); err != nil {
+				dialog.ShowError(ErrGetPresets, w)
+				return
+			}
+			dialog.ShowInformation("Параметры отчёта", "Параметры удалены", w)
+			return
+		}
+		dialog.ShowError(ErrEmptyPresetName, w)
 	})
 
-	// Draw the table
-	table.DrawTable()
-
-	// Сохраняем график как изображение
-	plotFile := "chart.png"
-	err = createThirdPlot(data, plotFile)
-	if err != nil {
-		return fmt.Errorf("ошибка создания графика: %v", err)
-	}
-
-	// Добавляем новую страницу в PDF
-	pdf.AddPage()
-
-	// Добавляем график на новую страницу
-	pdf.Image(plotFile, 20, 50, &gopdf.Rect{W: 400, H: 400}) // Размещение графика на второй странице
-
-	// Сохраняем PDF в файл
-	err = pdf.WritePdf(filename)
-	if err != nil {
-		return fmt.Errorf("ошибка сохранения PDF: %v", err)
+	form.Add(widget.NewLabel("Сохранённые параметры:"))
+	form.Add(savedSelect)
+	if periodSelect != nil {
+		form.Add(widget.NewLabel("Период:"))
+		form.Add(periodSelect)
 	}
-
-	os.Remove(plotFile)
-
-	return nil
+	form.Add(widget.NewLabel("Введите параметры:"))
+	form.Add(fields)
+	form.Add(presetName)
+	form.Add(container.NewHBox(savePresetButton, deletePresetButton))
+	return form, values, nil
 }
 
-func createFirstPlot(data []database.DateTotalMoney, filename string) error {
-	// Создаем новый график
-	p := plot.New()
-
-	// Заголовок графика
-	p.Title.Text = "Credit and Debit Over Time"
-	p.X.Label.Text = "Date"
-	p.Y.Label.Text = "Amount"
-
-	// Создаем графики для кредитов и дебетов
-	creditPoints := make(plotter.XYs, len(data))
-	debitPoints := make(plotter.XYs, len(data))
-
-	// Сохраняем даты для подписей
-	dates := make([]string, len(data))
-
-	for i, row := range data {
-		if row.Date.Format("2006-01-02") != "" {
-			creditPoints[i].X = float64(i)
-			creditPoints[i].Y = row.TotalCredit
-			debitPoints[i].X = float64(i)
-			debitPoints[i].Y = row.TotalDebit
+func hasPeriods(def *report.Definition) bool {
+	for _, param := range def.Params {
+		if param.Kind == report.ParamPeriod {
+			return true
 		}
-
-		dates[i] = row.Date.Format("2006-01-02") // Форматируем дату для подписи
 	}
+	return false
+}
 
-	// Добавляем линии для кредитов и дебетов
-	creditLine, err := plotter.NewLine(creditPoints)
-	if err != nil {
-		return fmt.Errorf("ошибка создания линии кредита: %v", err)
+// относительный период сохраняется, только если даты в форме ему соответствуют,
+// иначе их поправили вручную и набор хранит сами даты
+func matchedPreset(def *report.Definition, preset string, values report.Values) string {
+	if preset == "" {
+		return ""
 	}
-	creditLine.Color = color.RGBA{R: 255, G: 0, B: 0} // Красный для кредита
-
-	debitLine, err := plotter.NewLine(debitPoints)
+	expected, err := def.PresetValues(preset, values, time.Now())
 	if err != nil {
-		return fmt.Errorf("ошибка создания линии дебета: %v", err)
+		return ""
 	}
-	debitLine.Color = color.RGBA{R: 0, G: 0, B: 255} // Синий для дебета
-
-	// Добавляем линии в график
-	p.Add(creditLine, debitLine)
-
-	// Настраиваем подписи оси X (даты)
-	p.X.Tick.Marker = plot.ConstantTicks(ticksForDates(dates))
-
-	// Настраиваем оси
-	p.Y.Tick.Marker = plot.DefaultTicks{}
-
-	// Сохраняем график как PNG
-	err = p.Save(400, 400, filename)
-	if err != nil {
-		return fmt.Errorf("ошибка сохранения графика: %v", err)
+	for _, param := range def.Params {
+		if param.Kind == report.ParamPeriod && !slices.Equal(expected[param.Key], values[param.Key]) {
+			return ""
+		}
 	}
-
-	return nil
+	return preset
 }
 
-func createThirdPlot(data []database.DateProfit, filename string) error {
-	// Создаем новый график
-	p := plot.New()
-
-	// Заголовок графика
-	p.Title.Text = "Profit Over Time"
-	p.X.Label.Text = "Date"
-	p.Y.Label.Text = "Amount"
-
-	// Создаем графики для кредитов и дебетов
-	creditPoints := make(plotter.XYs, len(data))
+// ReportFields - поля параметров отчёта, функции чтения и заполнения значений.
+// Без периодов - для расписаний, где период задаётся относительно даты запуска
+func ReportFields(w fyne.Window, db database.Service, def *report.Definition, withPeriods bool) (*fyne.Container, func() report.Values, func(report.Values), error) {
+	form := container.NewVBox()
 
-	// Сохраняем даты для подписей
-	dates := make([]string, len(data))
+	periods := make(map[string][2]*widget.Entry)
+	readers := make(map[string]func() []string)
+	writers := make(map[string]func([]string))
 
-	for i, row := range data {
-		if row.Date.Format("2006-01-02") != "" {
-			creditPoints[i].X = float64(i)
-			creditPoints[i].Y = row.TotalProfit
+	for _, param := range def.Params {
+		if param.Kind == report.ParamPeriod && !withPeriods {
+			continue
+		}
+		form.Add(widget.NewLabel(param.Label + ":"))
+
+		switch param.Kind {
+		case report.ParamPeriod:
+			startDate, endDate := MadeDateFields()
+			periods[param.Key] = [2]*widget.Entry{startDate, endDate}
+			readers[param.Key] = func() []string { return []string{startDate.Text, endDate.Text} }
+			writers[param.Key] = func(value []string) {
+				if len(value) == 2 {
+					startDate.SetText(value[0])
+					endDate.SetText(value[1])
+				}
+			}
+
+			if from, ok := periods[param.ShiftFrom]; ok {
+				// тот же отрезок годом или месяцем раньше
+				shift := func(years, months int) {
+					start, err := report.ShiftDate(from[0].Text, years, months)
+					if err != nil {
+						dialog.ShowError(ErrParseDate, w)
+						return
+					}
+					end, err := report.ShiftDate(from[1].Text, years, months)
+					if err != nil {
+						dialog.ShowError(ErrParseDate, w)
+						return
+					}
+					startDate.SetText(start)
+					endDate.SetText(end)
+				}
+				form.Add(container.NewHBox(
+					widget.NewButton("Год назад", func() { shift(-1, 0) }),
+					widget.NewButton("Месяц назад", func() { shift(0, -1) }),
+				))
+			}
+			form.Add(startDate)
+			form.Add(endDate)
+
+		case report.ParamArticles:
+			articlesContainer, addArticleButton, delArticleButton, err := MadeArticlesButton(db)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			readers[param.Key] = func() []string { return LoadArticles(articlesContainer) }
+			writers[param.Key] = func(value []string) {
+				articlesContainer.Objects = articlesContainer.Objects[:1]
+				articlesContainer.Objects[0].(*widget.Select).ClearSelected()
+				for i, article := range value {
+					if i > 0 {
+						addArticleButton.OnTapped()
+					}
+					articlesContainer.Objects[i].(*widget.Select).SetSelected(article)
+				}
+				articlesContainer.Refresh()
+			}
+			form.Add(articlesContainer)
+			form.Add(addArticleButton)
+			form.Add(delArticleButton)
+
+		case report.ParamChoice:
+			labels := make([]string, len(param.Options))
+			for i, option := range param.Options {
+				labels[i] = option.Label
+			}
+			choice := widget.NewSelect(labels, func(value string) {
+				log.Printf("Set %s: %s\n", param.Key, value)
+			})
+			if len(labels) > 0 {
+				choice.SetSelected(labels[0])
+			}
+			readers[param.Key] = func() []string {
+				for _, option := range param.Options {
+					if option.Label == choice.Selected {
+						return []string{option.Value}
+					}
+				}
+				return nil
+			}
+			writers[param.Key] = func(value []string) {
+				for _, option := range param.Options {
+					if len(value) > 0 && option.Value == value[0] {
+						choice.SetSelected(option.Label)
+					}
+				}
+			}
+			form.Add(choice)
+
+		case report.ParamNumber:
+			number := widget.NewEntry()
+			number.SetText(param.Default)
+			readers[param.Key] = func() []string { return []string{number.Text} }
+			writers[param.Key] = func(value []string) {
+				if len(value) > 0 {
+					number.SetText(value[0])
+				}
+			}
+			form.Add(number)
 		}
-
-		dates[i] = row.Date.Format("2006-01-02") // Форматируем дату для подписи
-	}
-
-	// Добавляем линии для кредитов и дебетов
-	creditLine, err := plotter.NewLine(creditPoints)
-	if err != nil {
-		return fmt.Errorf("ошибка создания линии кредита: %v", err)
 	}
-	creditLine.Color = color.RGBA{R: 255, G: 255, B: 0} // Красный для кредита
-
-	// Добавляем линии в график
-	p.Add(creditLine)
 
-	// Настраиваем подписи оси X (даты)
-	p.X.Tick.Marker = plot.ConstantTicks(ticksForDates(dates))
-
-	// Настраиваем оси
-	p.Y.Tick.Marker = plot.DefaultTicks{}
-
-	// Сохраняем график как PNG
-	err = p.Save(400, 400, filename)
-	if err != nil {
-		return fmt.Errorf("ошибка сохранения графика: %v", err)
+	values := func() report.Values {
+		v := make(report.Values, len(readers))
+		for key, read := range readers {
+			v[key] = read()
+		}
+		return v
 	}
-
-	return nil
-}
-
-func ticksForDates(dates []string) []plot.Tick {
-	var ticks []plot.Tick
-	for i, date := range dates {
-		ticks = append(ticks, plot.Tick{
-			Value: float64(i), // Координата точки на оси X
-			Label: date,       // Подпись (дата)
-		})
+	// значения отсутствующих в наборе параметров не меняются
+	apply := func(v report.Values) {
+		for key, write := range writers {
+			if value, ok := v[key]; ok {
+				write(value)
+			}
+		}
 	}
-	return ticks
+	return form, values, apply, nil
 }
 
-func createPdf() (*gopdf.GoPdf, error) {
-	pdf := &gopdf.GoPdf{}
-	pdf.Start(gopdf.Config{PageSize: *gopdf.PageSizeA4}) // Размер страницы A4
-	pdf.AddPage()
-
-	err := pdf.AddTTFFont("Arial", "C:/Windows/Fonts/arial.ttf")
-	if err != nil {
-		return nil, fmt.Errorf("ошибка добавления шрифта: %v", err)
+// понятное сообщение об ошибке отчёта
+func reportError(def *report.Definition, err error) error {
+	switch {
+	case errors.Is(err, report.ErrEndBeforeStart):
+		return ErrEndLessStart
+	case errors.Is(err, report.ErrInvalidDate):
+		return ErrParseDate
+	case errors.Is(err, report.ErrInvalidNumber):
+		return ErrReportNumber
+	case errors.Is(err, database.ErrAdhocSpec):
+		return ErrBuilderSpec
 	}
-
-	err = pdf.SetFont("Arial", "", 12)
-	if err != nil {
-		return nil, fmt.Errorf("ошибка установки шрифта: %v", err)
+	if mapped, ok := reportErrors[def.ID]; ok {
+		return mapped
 	}
-
-	return pdf, nil
+	return ErrReport
 }
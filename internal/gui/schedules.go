@@ -0,0 +1,339 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"image/color"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/EmptyInsid/db_gui/internal/database"
+	"github.com/EmptyInsid/db_gui/internal/report"
+	"github.com/EmptyInsid/db_gui/internal/schedule"
+)
+
+// названия периодичности расписаний
+var frequencyNames = map[string]string{
+	database.ScheduleDaily:   "ежедневно",
+	database.ScheduleWeekly:  "еженедельно",
+	database.ScheduleMonthly: "ежемесячно",
+}
+
+// РАЗДЕЛ РАСПИСАНИЙ ОТЧЁТОВ
+func MainSchedules(w fyne.Window, db database.Service, role string) (*fyne.Container, error) {
+	table, err := ScheduleTable(db)
+	if err != nil {
+		return nil, err
+	}
+
+	editor := widget.NewAccordion(
+		widget.NewAccordionItem("Добавить", container.NewVBox(canvas.NewLine(color.White), WinAddSchedule(w, db, table))),
+		widget.NewAccordionItem("Запустить сейчас", container.NewVBox(canvas.NewLine(color.White), WinRunSchedule(w, db, table))),
+		widget.NewAccordionItem("Удалить", container.NewVBox(canvas.NewLine(color.White), WinDelSchedule(w, db, table))),
+	)
+	return container.NewStack(GridViewer(db, table, editor, role)), nil
+}
+
+func WinAddSchedule(w fyne.Window, db database.Service, table *widget.Table) *fyne.Container {
+	ctx := context.Background()
+
+	defs := report.All()
+	names := make([]string, len(defs))
+	for i, def := range defs {
+		names[i] = def.Name
+	}
+
+	// поля параметров меняются вместе с отчётом, период задаётся относительным
+	var def *report.Definition
+	var values func() report.Values
+	fields := container.NewVBox()
+	reportSelect := widget.NewSelect(names, func(name string) {
+		for _, d := range defs {
+			if d.Name != name {
+				continue
+			}
+			form, read, _, err := ReportFields(w, db, d, false)
+			if err != nil {
+				dialog.ShowError(ErrGetArt, w)
+				return
+			}
+			def, values = d, read
+			fields.Objects = []fyne.CanvasObject{form}
+			fields.Refresh()
+		}
+	})
+
+	presetLabels := make([]string, len(report.Presets))
+	for i, preset := range report.Presets {
+		presetLabels[i] = preset.Label
+	}
+	preset := widget.NewSelect(presetLabels, nil)
+	preset.SetSelected(presetLabels[1])
+
+	formats := make([]string, len(report.Exporters))
+	for i, exporter := range report.Exporters {
+		formats[i] = exporter.Name
+	}
+	format := widget.NewSelect(formats, nil)
+	format.SetSelected(formats[0])
+
+	directory := widget.NewEntry()
+	directory.SetPlaceHolder("папка для отчётов")
+	chooseDirectory := widget.NewButton("Выбрать папку", func() {
+		dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+			if err != nil || uri == nil {
+				return
+			}
+			directory.SetText(uri.Path())
+		}, w)
+	})
+
+	var frequencies []string
+	for _, frequency := range database.ScheduleFrequencies {
+		frequencies = append(frequencies, frequencyNames[frequency])
+	}
+	frequency := widget.NewSelect(frequencies, nil)
+	frequency.SetSelected(frequencyNames[database.ScheduleMonthly])
+
+	// по умолчанию - первое число следующего месяца
+	firstRun := widget.NewEntry()
+	now := time.Now()
+	firstRun.SetText(time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.Local).Format("2006-01-02"))
+
+	cont := container.NewAdaptiveGrid(
+		2,
+		widget.NewLabel("Отчёт"), reportSelect,
+		widget.NewLabel("Период"), preset,
+		widget.NewLabel("Формат"), format,
+		widget.NewLabel("Папка"), directory,
+		widget.NewLabel(""), chooseDirectory,
+		widget.NewLabel("Повтор"), frequency,
+		widget.NewLabel("Первый запуск"), firstRun,
+	)
+
+	btn := widget.NewButton("Добавить расписание", func() {
+		if def == nil || strings.TrimSpace(directory.Text) == "" {
+			dialog.ShowError(ErrScheduleInput, w)
+			return
+		}
+		nextRun, err := time.ParseInLocation("2006-01-02", firstRun.Text, time.Local)
+		if err != nil {
+			dialog.ShowError(ErrParseDate, w)
+			return
+		}
+
+		s := database.ReportSchedule{
+			ReportID:  def.ID,
+			Period:    report.Presets[0].Value,
+			Values:    values(),
+			Format:    format.Selected,
+			Directory: strings.TrimSpace(directory.Text),
+			Frequency: database.ScheduleMonthly,
+			NextRun:   nextRun,
+		}
+		for _, p := range report.Presets {
+			if p.Label == preset.Selected {
+				s.Period = p.Value
+			}
+		}
+		for key, name := range frequencyNames {
+			if name == frequency.Selected {
+				s.Frequency = key
+			}
+		}
+
+		if err := db.AddReportSchedule(ctx, s); err != nil {
+			dialog.ShowError(ErrAddSchedule, w)
+			return
+		}
+		dialog.ShowInformation("Добавить расписание", "Расписание добавлено!", w)
+
+		if err := UpdateScheduleTable(db, table); err != nil {
+			dialog.ShowError(ErrGetSchedules, w)
+		}
+	})
+
+	return container.NewVBox(cont, fields, btn)
+}
+
+// построить отчёт расписания сразу, плановый запуск не меняется
+func WinRunSchedule(w fyne.Window, db database.Service, table *widget.Table) *fyne.Container {
+	ctx := context.Background()
+
+	id := widget.NewEntry()
+	id.SetPlaceHolder("id")
+
+	cont := container.NewAdaptiveGrid(2, widget.NewLabel("ID расписания"), id)
+
+	btn := widget.NewButton("Запустить", func() {
+		intId, err := strconv.Atoi(id.Text)
+		if err != nil {
+			dialog.ShowError(ErrParseId, w)
+			return
+		}
+
+		schedules, err := db.GetReportSchedules(ctx)
+		if err != nil {
+			dialog.ShowError(ErrGetSchedules, w)
+			return
+		}
+		for _, s := range schedules {
+			if s.ID != intId {
+				continue
+			}
+
+			filename, err := schedule.Run(ctx, db, s, time.Now())
+			status, message := database.ScheduleOK, filename
+			if err != nil {
+				log.Printf("Error while run report schedule %d: %v", s.ID, err)
+				status, message = database.ScheduleError, err.Error()
+			}
+			if err := db.FinishReportSchedule(ctx, s.ID, status, message); err != nil {
+				log.Printf("Error while finish report schedule %d: %v", s.ID, err)
+			}
+			if status == database.ScheduleOK {
+				dialog.ShowInformation("Запустить расписание", "Отчёт сохранён: "+filename, w)
+			} else {
+				dialog.ShowError(ErrRunSchedule, w)
+			}
+
+			if err := UpdateScheduleTable(db, table); err != nil {
+				dialog.ShowError(ErrGetSchedules, w)
+			}
+			return
+		}
+		dialog.ShowError(ErrRunSchedule, w)
+	})
+
+	return container.NewVBox(cont, btn)
+}
+
+func WinDelSchedule(w fyne.Window, db database.Service, table *widget.Table) *fyne.Container {
+	ctx := context.Background()
+
+	id := widget.NewEntry()
+	id.SetPlaceHolder("id")
+
+	cont := container.NewAdaptiveGrid(2, widget.NewLabel("ID расписания"), id)
+
+	btn := widget.NewButton("Удалить расписание", func() {
+		intId, err := strconv.Atoi(id.Text)
+		if err != nil {
+			dialog.ShowError(ErrParseId, w)
+			return
+		}
+
+		if err := db.DeleteReportSchedule(ctx, intId); err != nil {
+			dialog.ShowError(ErrDelSchedule, w)
+			return
+		}
+		dialog.ShowInformation("Удалить расписание", "Расписание удалено", w)
+
+		if err := UpdateScheduleTable(db, table); err != nil {
+			dialog.ShowError(ErrGetSchedules, w)
+		}
+	})
+
+	return container.NewVBox(cont, btn)
+}
+
+func ScheduleTable(db database.Service) (*widget.Table, error) {
+	table := widget.NewTable(nil,
+		func() fyne.CanvasObject {
+			return widget.NewLabel("very very wide content")
+		}, nil)
+
+	if err := UpdateScheduleTable(db, table); err != nil {
+		return nil, err
+	}
+
+	table.SetColumnWidth(0, widget.NewLabel("Number").MinSize().Width)
+	table.SetColumnWidth(1, widget.NewLabel("Сравнение периодов").MinSize().Width)
+	table.SetColumnWidth(2, widget.NewLabel("Последние 12 месяцев").MinSize().Width)
+	table.SetColumnWidth(3, widget.NewLabel("XLSX").MinSize().Width)
+	table.SetColumnWidth(4, widget.NewLabel("very very wide content").MinSize().Width)
+	table.SetColumnWidth(5, widget.NewLabel("еженедельно").MinSize().Width)
+	table.SetColumnWidth(6, widget.NewLabel("2024-11-01 10:00").MinSize().Width)
+	table.SetColumnWidth(7, widget.NewLabel("2024-11-01 10:00").MinSize().Width)
+	table.SetColumnWidth(8, widget.NewLabel("very very wide content").MinSize().Width)
+
+	return table, nil
+}
+
+func UpdateScheduleTable(db database.Service, table *widget.Table) error {
+	ctx := context.Background()
+
+	data, err := db.GetReportSchedules(ctx)
+	if err != nil {
+		log.Printf("Error while get report schedules: %v", err)
+		return err
+	}
+
+	header := []string{"ID", "Отчёт", "Период", "Формат", "Папка", "Повтор", "Следующий", "Последний", "Итог"}
+
+	table.Length = func() (int, int) {
+		return len(data) + 1, len(header)
+	}
+	table.UpdateCell = func(i widget.TableCellID, o fyne.CanvasObject) {
+		label := o.(*widget.Label)
+		col, row := i.Col, i.Row
+
+		if row == 0 {
+			label.SetText(header[col])
+			return
+		}
+		label.SetText(scheduleCell(data[row-1], col))
+	}
+
+	table.Refresh()
+	return nil
+}
+
+func scheduleCell(s database.ReportSchedule, col int) string {
+	switch col {
+	case 0:
+		return fmt.Sprint(s.ID)
+	case 1:
+		if def, err := report.Get(s.ReportID); err == nil {
+			return def.Name
+		}
+		return s.ReportID
+	case 2:
+		for _, preset := range report.Presets {
+			if preset.Value == s.Period {
+				return preset.Label
+			}
+		}
+		return s.Period
+	case 3:
+		return s.Format
+	case 4:
+		return s.Directory
+	case 5:
+		return frequencyNames[s.Frequency]
+	case 6:
+		return s.NextRun.Local().Format("2006-01-02 15:04")
+	case 7:
+		if s.LastRun == nil {
+			return "-"
+		}
+		return s.LastRun.Local().Format("2006-01-02 15:04")
+	case 8:
+		switch s.LastStatus {
+		case database.ScheduleOK:
+			return "готово: " + s.LastMessage
+		case database.ScheduleError:
+			return "ошибка: " + s.LastMessage
+		}
+		return "-"
+	default:
+		return "-"
+	}
+}
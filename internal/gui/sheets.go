@@ -0,0 +1,122 @@
+package gui
+
+import (
+	"context"
+	"log"
+	"path/filepath"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/EmptyInsid/db_gui/internal/database"
+	"github.com/EmptyInsid/db_gui/internal/report"
+)
+
+// SaveXLSXButton - кнопка выгрузки таблиц справочника и журнала в книгу XLSX.
+// Листы собираются на момент сохранения, чтобы попали последние правки
+func SaveXLSXButton(w fyne.Window, sheets func() ([]report.Sheet, error)) *widget.Button {
+	return widget.NewButton("Сохранить XLSX", func() {
+		dialog.ShowFileSave(
+			func(uc fyne.URIWriteCloser, err error) {
+				if err != nil {
+					dialog.ShowError(ErrSaveFile, w)
+					return
+				}
+				if uc == nil {
+					return // Пользователь отменил выбор
+				}
+				defer uc.Close()
+
+				data, err := sheets()
+				if err != nil {
+					log.Printf("Error while load sheets for xlsx: %v", err)
+					dialog.ShowError(ErrSaveFile, w)
+					return
+				}
+
+				filename := uc.URI().Path()
+				if filepath.Ext(filename) != ".xlsx" {
+					filename += ".xlsx"
+				}
+				if err := report.WriteWorkbook(filename, data...); err != nil {
+					log.Printf("Error while save xlsx: %v", err)
+					dialog.ShowError(ErrSaveFile, w)
+				} else {
+					dialog.ShowInformation("Успех", "XLSX успешно сохранён!", w)
+				}
+			}, w)
+	})
+}
+
+// лист статей справочника
+func ArticleSheet(db database.Service) ([]report.Sheet, error) {
+	data, err := db.GetAllArticles(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	sheet := report.Sheet{
+		Name:    "Статьи",
+		Columns: []report.Column{{Header: "Статья"}, {Header: "Метка"}},
+	}
+	for _, article := range data {
+		sheet.Rows = append(sheet.Rows, []any{article.Name, article.Tag})
+	}
+	return []report.Sheet{sheet}, nil
+}
+
+// лист операций справочника
+func OperationSheet(db database.Service) ([]report.Sheet, error) {
+	data, err := db.GetArticlesWithOperations(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	sheet := report.Sheet{
+		Name: "Операции",
+		Columns: []report.Column{
+			{Header: "Id", Kind: report.ColCount},
+			{Header: "Статья"},
+			{Header: "Доход", Kind: report.ColMoney},
+			{Header: "Расход", Kind: report.ColMoney},
+			{Header: "Дата", Kind: report.ColDate},
+			{Header: "Учёт"},
+		},
+	}
+	for _, oper := range data {
+		accounted := "Не учтена"
+		if oper.BalanceID != nil {
+			accounted = "Учтена"
+		}
+		sheet.Rows = append(sheet.Rows, []any{
+			int64(oper.OperationID), oper.ArticleName, oper.Debit, oper.Credit, oper.CreateDate, accounted,
+		})
+	}
+	return []report.Sheet{sheet}, nil
+}
+
+// лист балансов журнала
+func BalanceSheet(db database.Service) ([]report.Sheet, error) {
+	data, err := db.GetAllBalances(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	sheet := report.Sheet{
+		Name: "Балансы",
+		Columns: []report.Column{
+			{Header: "Начало", Kind: report.ColDate},
+			{Header: "Конец", Kind: report.ColDate},
+			{Header: "Тип"},
+			{Header: "Доход", Kind: report.ColMoney},
+			{Header: "Расход", Kind: report.ColMoney},
+			{Header: "Итог", Kind: report.ColMoney, Signed: true},
+			{Header: "На руках", Kind: report.ColMoney},
+			{Header: "Период"},
+		},
+	}
+	for _, balance := range data {
+		sheet.Rows = append(sheet.Rows, []any{
+			balance.StartDate, balance.Date, TranslatePeriod(balance.PeriodType),
+			balance.Debit, balance.Credit, balance.Amount, balance.OnHand, BalanceStatus(balance),
+		})
+	}
+	return []report.Sheet{sheet}, nil
+}
@@ -8,6 +8,8 @@ import (
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/widget"
 	"github.com/EmptyInsid/db_gui/internal/database"
+	"github.com/EmptyInsid/db_gui/internal/models"
+	"github.com/EmptyInsid/db_gui/internal/report"
 )
 
 func OperationsTable(db database.Service) (*widget.Table, error) {
@@ -81,7 +83,7 @@ func BalanceTable(db database.Service) (*widget.Table, error) {
 		return nil, err
 	}
 
-	header := []string{"Номер", "Дата", "Доход", "Расход", "Итог"}
+	header := []string{"Номер", "Начало", "Конец", "Тип", "Доход", "Расход", "Итог", "На руках", "Период"}
 
 	table := widget.NewTable(
 		func() (int, int) {
@@ -101,13 +103,21 @@ func BalanceTable(db database.Service) (*widget.Table, error) {
 				case 0:
 					lable.SetText(fmt.Sprint(row))
 				case 1:
-					lable.SetText(fmt.Sprint(data[row-1].Date.Format("2006-01-02")))
+					lable.SetText(data[row-1].StartDate.Format("2006-01-02"))
 				case 2:
-					lable.SetText(fmt.Sprint(data[row-1].Debit))
+					lable.SetText(fmt.Sprint(data[row-1].Date.Format("2006-01-02")))
 				case 3:
-					lable.SetText(fmt.Sprint(data[row-1].Credit))
+					lable.SetText(TranslatePeriod(data[row-1].PeriodType))
 				case 4:
+					lable.SetText(fmt.Sprint(data[row-1].Debit))
+				case 5:
+					lable.SetText(fmt.Sprint(data[row-1].Credit))
+				case 6:
 					lable.SetText(fmt.Sprint(data[row-1].Amount))
+				case 7:
+					lable.SetText(fmt.Sprintf("%.2f", data[row-1].OnHand))
+				case 8:
+					lable.SetText(BalanceStatus(data[row-1]))
 				default:
 					lable.SetText("-")
 				}
@@ -117,9 +127,13 @@ func BalanceTable(db database.Service) (*widget.Table, error) {
 
 	table.SetColumnWidth(0, widget.NewLabel("Number").MinSize().Width)
 	table.SetColumnWidth(1, widget.NewLabel("2024-11-01").MinSize().Width)
-	table.SetColumnWidth(2, widget.NewLabel("10000000").MinSize().Width)
-	table.SetColumnWidth(3, widget.NewLabel("10000000").MinSize().Width)
+	table.SetColumnWidth(2, widget.NewLabel("2024-11-01").MinSize().Width)
+	table.SetColumnWidth(3, widget.NewLabel("произвольный").MinSize().Width)
 	table.SetColumnWidth(4, widget.NewLabel("10000000").MinSize().Width)
+	table.SetColumnWidth(5, widget.NewLabel("10000000").MinSize().Width)
+	table.SetColumnWidth(6, widget.NewLabel("10000000").MinSize().Width)
+	table.SetColumnWidth(7, widget.NewLabel("10000000").MinSize().Width)
+	table.SetColumnWidth(8, widget.NewLabel("открыт повторно").MinSize().Width)
 
 	return table, nil
 }
@@ -132,7 +146,7 @@ func ArticleTable(db database.Service) (*widget.Table, error) {
 		return nil, err
 	}
 
-	header := []string{"Номер", "Статья"}
+	header := []string{"Номер", "Статья", "Метка"}
 
 	table := widget.NewTable(
 		func() (int, int) {
@@ -153,6 +167,8 @@ func ArticleTable(db database.Service) (*widget.Table, error) {
 					lable.SetText(fmt.Sprint(row))
 				case 1:
 					lable.SetText(fmt.Sprint(data[row-1].Name))
+				case 2:
+					lable.SetText(data[row-1].Tag)
 				default:
 					lable.SetText("-")
 				}
@@ -162,6 +178,7 @@ func ArticleTable(db database.Service) (*widget.Table, error) {
 
 	table.SetColumnWidth(0, widget.NewLabel("Number").MinSize().Width)
 	table.SetColumnWidth(1, widget.NewLabel("very very wide content").MinSize().Width)
+	table.SetColumnWidth(2, widget.NewLabel("very wide content").MinSize().Width)
 
 	return table, nil
 }
@@ -307,7 +324,7 @@ func UpdateArticleTable(db database.Service, table *widget.Table) error {
 		return err
 	}
 
-	header := []string{"Номер", "Статья"}
+	header := []string{"Номер", "Статья", "Метка"}
 
 	// Обновляем таблицу
 	table.Length = func() (int, int) {
@@ -324,6 +341,8 @@ func UpdateArticleTable(db database.Service, table *widget.Table) error {
 				label.SetText(fmt.Sprint(row))
 			} else if col == 1 {
 				label.SetText(data[row-1].Name)
+			} else if col == 2 {
+				label.SetText(data[row-1].Tag)
 			}
 		}
 	}
@@ -340,7 +359,7 @@ func UpdateBalanceTable(db database.Service, table *widget.Table) error {
 		return err
 	}
 
-	header := []string{"Номер", "Дата", "Доход", "Расход", "Итог"}
+	header := []string{"Номер", "Начало", "Конец", "Тип", "Доход", "Расход", "Итог", "На руках", "Период"}
 
 	// Обновляем таблицу
 	table.Length = func() (int, int) {
@@ -357,13 +376,21 @@ func UpdateBalanceTable(db database.Service, table *widget.Table) error {
 			case 0:
 				lable.SetText(fmt.Sprint(row))
 			case 1:
-				lable.SetText(fmt.Sprint(data[row-1].Date.Format("2006-01-02")))
+				lable.SetText(data[row-1].StartDate.Format("2006-01-02"))
 			case 2:
-				lable.SetText(fmt.Sprint(data[row-1].Debit))
+				lable.SetText(fmt.Sprint(data[row-1].Date.Format("2006-01-02")))
 			case 3:
-				lable.SetText(fmt.Sprint(data[row-1].Credit))
+				lable.SetText(TranslatePeriod(data[row-1].PeriodType))
 			case 4:
+				lable.SetText(fmt.Sprint(data[row-1].Debit))
+			case 5:
+				lable.SetText(fmt.Sprint(data[row-1].Credit))
+			case 6:
 				lable.SetText(fmt.Sprint(data[row-1].Amount))
+			case 7:
+				lable.SetText(fmt.Sprintf("%.2f", data[row-1].OnHand))
+			case 8:
+				lable.SetText(BalanceStatus(data[row-1]))
 			default:
 				lable.SetText("-")
 			}
@@ -428,19 +455,135 @@ func UpdateOperationTable(db database.Service, table *widget.Table) error {
 }
 
 // ДЛЯ ОТЧЁТОВ
-func IncomeExpenseDynamicsTable(db database.Service, articles []string, startDate, endDate string) (*widget.Table, error) {
+// ReportTable - таблица отчёта по колонкам из описания, итоговая строка без номера
+func ReportTable(def *report.Definition, result *report.Result) *widget.Table {
+	header := append([]string{"Номер"}, make([]string, len(def.Columns))...)
+	for i, column := range def.Columns {
+		header[i+1] = column.Header
+	}
+
+	rows := len(result.Rows)
+	if result.Total != nil {
+		rows++
+	}
+
+	table := widget.NewTable(
+		func() (int, int) {
+			return rows + 1, len(header)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("very very wide content")
+		},
+		func(i widget.TableCellID, o fyne.CanvasObject) {
+			lable := o.(*widget.Label)
+			col, row := i.Col, i.Row
+
+			if row == 0 {
+				lable.SetText(header[col])
+				return
+			}
+
+			values := result.Total
+			number := ""
+			if row <= len(result.Rows) {
+				values = result.Rows[row-1]
+				number = fmt.Sprint(row)
+			}
+
+			if col == 0 {
+				lable.SetText(number)
+			} else {
+				lable.SetText(report.Format(def.Columns[col-1], values[col-1]))
+			}
+		})
+
+	table.SetColumnWidth(0, widget.NewLabel("Number").MinSize().Width)
+	for i, column := range def.Columns {
+		sample := "10000000.00"
+		switch column.Kind {
+		case report.ColText:
+			sample = "very very wide content"
+		case report.ColDate:
+			sample = "2024-11-01 50"
+		}
+		width := widget.NewLabel(sample).MinSize().Width
+		if headerWidth := widget.NewLabel(column.Header).MinSize().Width; headerWidth > width {
+			width = headerWidth
+		}
+		table.SetColumnWidth(i+1, width)
+	}
+
+	return table
+}
+
+// ДЛЯ ПОЛЬЗОВАТЕЛЕЙ
+func LoginHistoryTable(db database.Service) (*widget.Table, error) {
 	ctx := context.Background()
 
-	data, err := db.GetIncomeExpenseDynamics(ctx, articles, startDate, endDate)
+	data, err := db.GetLoginHistory(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, a := range articles {
-		log.Print(a)
+	header := []string{"Номер", "Логин", "Результат", "Причина", "Время"}
+
+	table := widget.NewTable(
+		func() (int, int) {
+			return len(data) + 1, len(header)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("very very wide content")
+		},
+		func(i widget.TableCellID, o fyne.CanvasObject) {
+			lable := o.(*widget.Label)
+			col, row := i.Col, i.Row
+
+			if row == 0 {
+				lable.SetText(header[col])
+			} else {
+				switch col {
+				case 0:
+					lable.SetText(fmt.Sprint(row))
+				case 1:
+					lable.SetText(data[row-1].Username)
+				case 2:
+					text := "Отказ"
+					switch {
+					case data[row-1].Event == models.LoginEventUnlock:
+						text = "Разблокировка"
+					case data[row-1].Success:
+						text = "Успех"
+					}
+					lable.SetText(text)
+				case 3:
+					lable.SetText(data[row-1].Reason)
+				case 4:
+					lable.SetText(data[row-1].Date.Format("2006-01-02 15:04:05"))
+				default:
+					lable.SetText("-")
+				}
+
+			}
+		})
+
+	table.SetColumnWidth(0, widget.NewLabel("Number").MinSize().Width)
+	table.SetColumnWidth(1, widget.NewLabel("very wide login").MinSize().Width)
+	table.SetColumnWidth(2, widget.NewLabel("Разблокировка").MinSize().Width)
+	table.SetColumnWidth(3, widget.NewLabel("very very wide content").MinSize().Width)
+	table.SetColumnWidth(4, widget.NewLabel("2024-11-01 00:00:00").MinSize().Width)
+
+	return table, nil
+}
+
+func LoginAttemptsTable(db database.Service) (*widget.Table, error) {
+	ctx := context.Background()
+
+	data, err := db.GetAllLoginAttempts(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	header := []string{"Номер", "Дата", "Общий доход", "Общий расход"}
+	header := []string{"Номер", "Логин", "Неудачных попыток", "Заблокирован до"}
 
 	table := widget.NewTable(
 		func() (int, int) {
@@ -460,11 +603,15 @@ func IncomeExpenseDynamicsTable(db database.Service, articles []string, startDat
 				case 0:
 					lable.SetText(fmt.Sprint(row))
 				case 1:
-					lable.SetText(fmt.Sprint(data[row-1].Date.Format("2006-01-02")))
+					lable.SetText(data[row-1].Username)
 				case 2:
-					lable.SetText(fmt.Sprint(data[row-1].TotalDebit))
+					lable.SetText(fmt.Sprint(data[row-1].FailedCount))
 				case 3:
-					lable.SetText(fmt.Sprint(data[row-1].TotalCredit))
+					text := "-"
+					if data[row-1].LockedUntil != nil {
+						text = data[row-1].LockedUntil.Format("2006-01-02 15:04:05")
+					}
+					lable.SetText(text)
 				default:
 					lable.SetText("-")
 				}
@@ -473,22 +620,22 @@ func IncomeExpenseDynamicsTable(db database.Service, articles []string, startDat
 		})
 
 	table.SetColumnWidth(0, widget.NewLabel("Number").MinSize().Width)
-	table.SetColumnWidth(1, widget.NewLabel("2024-11-01 50").MinSize().Width)
-	table.SetColumnWidth(2, widget.NewLabel("10000000 5000000").MinSize().Width)
-	table.SetColumnWidth(3, widget.NewLabel("10000000 5000000").MinSize().Width)
+	table.SetColumnWidth(1, widget.NewLabel("very wide login").MinSize().Width)
+	table.SetColumnWidth(2, widget.NewLabel("Неудачных попыток").MinSize().Width)
+	table.SetColumnWidth(3, widget.NewLabel("2024-11-01 00:00:00").MinSize().Width)
 
 	return table, nil
 }
-func FinancialPercentagesTable(db database.Service, articles []string, flow, startDate, endDate string) (*widget.Table, error) {
-	//GetFinancialPercentages(ctx context.Context, articles []string, flow, startDate, endDate string) ([]FinancialPercentage, error)
+
+func AuditTable(w fyne.Window, db database.Service, filter database.AuditFilter) (*widget.Table, error) {
 	ctx := context.Background()
 
-	data, err := db.GetFinancialPercentages(ctx, articles, flow, startDate, endDate)
+	data, err := db.GetAuditLog(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
 
-	header := []string{"Номер", "Статья", "Общий доход", "Общий расход", "Прибыль", "Процент"}
+	header := []string{"Номер", "Время", "Пользователь", "Действие", "Объект", "До", "После"}
 
 	table := widget.NewTable(
 		func() (int, int) {
@@ -508,15 +655,17 @@ func FinancialPercentagesTable(db database.Service, articles []string, flow, sta
 				case 0:
 					lable.SetText(fmt.Sprint(row))
 				case 1:
-					lable.SetText(fmt.Sprint(data[row-1].ArticleName))
+					lable.SetText(data[row-1].Date.Format("2006-01-02 15:04:05"))
 				case 2:
-					lable.SetText(fmt.Sprint(data[row-1].TotalDebit))
+					lable.SetText(data[row-1].Username)
 				case 3:
-					lable.SetText(fmt.Sprint(data[row-1].TotalCredit))
+					lable.SetText(data[row-1].Action)
 				case 4:
-					lable.SetText(fmt.Sprint(data[row-1].TotalProfit))
+					lable.SetText(TranslateEntity(data[row-1].Entity))
 				case 5:
-					lable.SetText(fmt.Sprint(data[row-1].TotalProc))
+					lable.SetText(shortText(data[row-1].Before, 30))
+				case 6:
+					lable.SetText(shortText(data[row-1].After, 30))
 				default:
 					lable.SetText("-")
 				}
@@ -524,25 +673,35 @@ func FinancialPercentagesTable(db database.Service, articles []string, flow, sta
 			}
 		})
 
+	// подробности изменения по выбору строки
+	table.OnSelected = func(id widget.TableCellID) {
+		table.Unselect(id)
+		if id.Row == 0 {
+			return
+		}
+		ShowAuditEntry(w, data[id.Row-1])
+	}
+
 	table.SetColumnWidth(0, widget.NewLabel("Number").MinSize().Width)
-	table.SetColumnWidth(1, widget.NewLabel("very very wide content").MinSize().Width)
-	table.SetColumnWidth(2, widget.NewLabel("10000000 5000000").MinSize().Width)
-	table.SetColumnWidth(3, widget.NewLabel("10000000 5000000").MinSize().Width)
-	table.SetColumnWidth(4, widget.NewLabel("10000000 5000000").MinSize().Width)
-	table.SetColumnWidth(5, widget.NewLabel("10000000 5000000").MinSize().Width)
+	table.SetColumnWidth(1, widget.NewLabel("2024-11-01 00:00:00").MinSize().Width)
+	table.SetColumnWidth(2, widget.NewLabel("very wide login").MinSize().Width)
+	table.SetColumnWidth(3, widget.NewLabel("add member").MinSize().Width)
+	table.SetColumnWidth(4, widget.NewLabel("Операция").MinSize().Width)
+	table.SetColumnWidth(5, widget.NewLabel("very very wide content").MinSize().Width)
+	table.SetColumnWidth(6, widget.NewLabel("very very wide content").MinSize().Width)
 
 	return table, nil
 }
-func TotalProfitDateTable(db database.Service, startDate, endDate string) (*widget.Table, error) {
-	//GetTotalProfitDate(ctx context.Context, startDate, endDate string) ([]DateProfit, error)
+
+func TrashTable(db database.Service, onSelected func(item database.TrashItem)) (*widget.Table, error) {
 	ctx := context.Background()
 
-	data, err := db.GetTotalProfitDate(ctx, startDate, endDate)
+	data, err := db.GetTrash(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	header := []string{"Номер", "Дата", "Прибыль"}
+	header := []string{"Номер", "Объект", "Статья", "Дата", "Доход", "Расход", "Удалено"}
 
 	table := widget.NewTable(
 		func() (int, int) {
@@ -558,13 +717,141 @@ func TotalProfitDateTable(db database.Service, startDate, endDate string) (*widg
 			if row == 0 {
 				lable.SetText(header[col])
 			} else {
+				item := data[row-1]
 				switch col {
 				case 0:
 					lable.SetText(fmt.Sprint(row))
 				case 1:
-					lable.SetText(fmt.Sprint(data[row-1].Date.Format("2006-01-02")))
+					lable.SetText(TranslateEntity(item.Entity))
+				case 2:
+					if item.Name == "" {
+						lable.SetText("-")
+					} else {
+						lable.SetText(item.Name)
+					}
+				case 3:
+					if item.Date == nil {
+						lable.SetText("-")
+					} else {
+						lable.SetText(item.Date.Format("2006-01-02"))
+					}
+				case 4:
+					if item.Entity == database.TrashArticle {
+						lable.SetText("-")
+					} else {
+						lable.SetText(fmt.Sprintf("%.2f", item.Debit))
+					}
+				case 5:
+					if item.Entity == database.TrashArticle {
+						lable.SetText("-")
+					} else {
+						lable.SetText(fmt.Sprintf("%.2f", item.Credit))
+					}
+				case 6:
+					lable.SetText(item.DeletedAt.Format("2006-01-02 15:04:05"))
+				default:
+					lable.SetText("-")
+				}
+
+			}
+		})
+
+	table.OnSelected = func(id widget.TableCellID) {
+		if id.Row == 0 {
+			return
+		}
+		onSelected(data[id.Row-1])
+	}
+
+	table.SetColumnWidth(0, widget.NewLabel("Number").MinSize().Width)
+	table.SetColumnWidth(1, widget.NewLabel("Операция").MinSize().Width)
+	table.SetColumnWidth(2, widget.NewLabel("very wide article").MinSize().Width)
+	table.SetColumnWidth(3, widget.NewLabel("2024-11-01").MinSize().Width)
+	table.SetColumnWidth(4, widget.NewLabel("1000000.00").MinSize().Width)
+	table.SetColumnWidth(5, widget.NewLabel("1000000.00").MinSize().Width)
+	table.SetColumnWidth(6, widget.NewLabel("2024-11-01 00:00:00").MinSize().Width)
+
+	return table, nil
+}
+
+func BalanceMismatchTable(data []database.BalanceMismatch) *widget.Table {
+	header := []string{"Номер", "Дата", "Доход", "Доход по операциям", "Расход", "Расход по операциям", "Итог", "Итог по операциям"}
+
+	table := widget.NewTable(
+		func() (int, int) {
+			return len(data) + 1, len(header)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("very very wide content")
+		},
+		func(i widget.TableCellID, o fyne.CanvasObject) {
+			lable := o.(*widget.Label)
+			col, row := i.Col, i.Row
+
+			if row == 0 {
+				lable.SetText(header[col])
+			} else {
+				switch col {
+				case 0:
+					lable.SetText(fmt.Sprint(row))
+				case 1:
+					lable.SetText(data[row-1].Date.Format("2006-01-02"))
+				case 2:
+					lable.SetText(fmt.Sprint(data[row-1].Debit))
+				case 3:
+					lable.SetText(fmt.Sprint(data[row-1].ActualDebit))
+				case 4:
+					lable.SetText(fmt.Sprint(data[row-1].Credit))
+				case 5:
+					lable.SetText(fmt.Sprint(data[row-1].ActualCredit))
+				case 6:
+					lable.SetText(fmt.Sprint(data[row-1].Amount))
+				case 7:
+					lable.SetText(fmt.Sprint(data[row-1].ActualAmount))
+				default:
+					lable.SetText("-")
+				}
+
+			}
+		})
+
+	table.SetColumnWidth(0, widget.NewLabel("Number").MinSize().Width)
+	table.SetColumnWidth(1, widget.NewLabel("2024-11-01").MinSize().Width)
+	for col := 2; col < len(header); col++ {
+		table.SetColumnWidth(col, widget.NewLabel("Расход по операциям").MinSize().Width)
+	}
+
+	return table
+}
+
+func IntegrityOperationTable(data []database.IntegrityOperation, balanceHeader string) *widget.Table {
+	header := []string{"Номер", "ID операции", "Статья", "Дата", balanceHeader}
+
+	table := widget.NewTable(
+		func() (int, int) {
+			return len(data) + 1, len(header)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("very very wide content")
+		},
+		func(i widget.TableCellID, o fyne.CanvasObject) {
+			lable := o.(*widget.Label)
+			col, row := i.Col, i.Row
+
+			if row == 0 {
+				lable.SetText(header[col])
+			} else {
+				switch col {
+				case 0:
+					lable.SetText(fmt.Sprint(row))
+				case 1:
+					lable.SetText(fmt.Sprint(data[row-1].OperationID))
 				case 2:
-					lable.SetText(fmt.Sprint(data[row-1].TotalProfit))
+					lable.SetText(data[row-1].ArticleName)
+				case 3:
+					lable.SetText(data[row-1].Date.Format("2006-01-02"))
+				case 4:
+					lable.SetText(fmt.Sprint(data[row-1].BalanceID))
 				default:
 					lable.SetText("-")
 				}
@@ -573,8 +860,137 @@ func TotalProfitDateTable(db database.Service, startDate, endDate string) (*widg
 		})
 
 	table.SetColumnWidth(0, widget.NewLabel("Number").MinSize().Width)
-	table.SetColumnWidth(1, widget.NewLabel("2024-11-01 50").MinSize().Width)
-	table.SetColumnWidth(2, widget.NewLabel("10000000 50").MinSize().Width)
+	table.SetColumnWidth(1, widget.NewLabel("ID операции").MinSize().Width)
+	table.SetColumnWidth(2, widget.NewLabel("very wide article").MinSize().Width)
+	table.SetColumnWidth(3, widget.NewLabel("2024-11-01").MinSize().Width)
+	table.SetColumnWidth(4, widget.NewLabel("Ссылается на баланс").MinSize().Width)
+
+	return table
+}
+
+func ClosingTable(data []database.ClosingPeriod) *widget.Table {
+	header := []string{"Номер", "Начало", "Конец", "Тип", "Доход", "Расход", "Прибыль", "Состояние"}
+
+	table := widget.NewTable(
+		func() (int, int) {
+			return len(data) + 1, len(header)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("very very wide content")
+		},
+		func(i widget.TableCellID, o fyne.CanvasObject) {
+			lable := o.(*widget.Label)
+			col, row := i.Col, i.Row
+
+			if row == 0 {
+				lable.SetText(header[col])
+			} else {
+				switch col {
+				case 0:
+					lable.SetText(fmt.Sprint(row))
+				case 1:
+					lable.SetText(data[row-1].Start.Format("2006-01-02"))
+				case 2:
+					lable.SetText(data[row-1].End.Format("2006-01-02"))
+				case 3:
+					lable.SetText(TranslatePeriod(data[row-1].PeriodType))
+				case 4:
+					lable.SetText(fmt.Sprint(data[row-1].Debit))
+				case 5:
+					lable.SetText(fmt.Sprint(data[row-1].Credit))
+				case 6:
+					lable.SetText(fmt.Sprint(data[row-1].Profit))
+				case 7:
+					lable.SetText(ClosingStatus(data[row-1]))
+				default:
+					lable.SetText("-")
+				}
+
+			}
+		})
+
+	table.SetColumnWidth(0, widget.NewLabel("Number").MinSize().Width)
+	table.SetColumnWidth(1, widget.NewLabel("2024-11-01").MinSize().Width)
+	table.SetColumnWidth(2, widget.NewLabel("2024-11-01").MinSize().Width)
+	table.SetColumnWidth(3, widget.NewLabel("произвольный").MinSize().Width)
+	table.SetColumnWidth(4, widget.NewLabel("10000000").MinSize().Width)
+	table.SetColumnWidth(5, widget.NewLabel("10000000").MinSize().Width)
+	table.SetColumnWidth(6, widget.NewLabel("10000000").MinSize().Width)
+	table.SetColumnWidth(7, widget.NewLabel("пройдёт порог").MinSize().Width)
+
+	return table
+}
+
+func RecurringTable(db database.Service) (*widget.Table, error) {
+	table := widget.NewTable(nil,
+		func() fyne.CanvasObject {
+			return widget.NewLabel("very very wide content")
+		}, nil)
+
+	if err := UpdateRecurringTable(db, table); err != nil {
+		return nil, err
+	}
+
+	table.SetColumnWidth(0, widget.NewLabel("Number").MinSize().Width)
+	table.SetColumnWidth(1, widget.NewLabel("very very wide content").MinSize().Width)
+	table.SetColumnWidth(2, widget.NewLabel("10000000").MinSize().Width)
+	table.SetColumnWidth(3, widget.NewLabel("10000000").MinSize().Width)
+	table.SetColumnWidth(4, widget.NewLabel("2024-11-01").MinSize().Width)
+	table.SetColumnWidth(5, widget.NewLabel("2024-11-01").MinSize().Width)
+	table.SetColumnWidth(6, widget.NewLabel("Раз в месяцев").MinSize().Width)
 
 	return table, nil
 }
+
+func UpdateRecurringTable(db database.Service, table *widget.Table) error {
+	ctx := context.Background()
+
+	data, err := db.GetRecurringItems(ctx)
+	if err != nil {
+		log.Printf("Error while get recurring items: %v", err)
+		return err
+	}
+
+	header := []string{"ID", "Статья", "Доход", "Расход", "Начало", "Окончание", "Раз в месяцев"}
+
+	table.Length = func() (int, int) {
+		return len(data) + 1, len(header)
+	}
+	table.UpdateCell = func(i widget.TableCellID, o fyne.CanvasObject) {
+		label := o.(*widget.Label)
+		col, row := i.Col, i.Row
+
+		if row == 0 {
+			label.SetText(header[col])
+			return
+		}
+		label.SetText(recurringCell(data[row-1], col))
+	}
+
+	table.Refresh()
+	return nil
+}
+
+func recurringCell(item models.RecurringItem, col int) string {
+	switch col {
+	case 0:
+		return fmt.Sprint(item.ID)
+	case 1:
+		return item.ArticleName
+	case 2:
+		return fmt.Sprint(item.Debit)
+	case 3:
+		return fmt.Sprint(item.Credit)
+	case 4:
+		return item.StartDate.Format("2006-01-02")
+	case 5:
+		if item.EndDate == nil {
+			return "-"
+		}
+		return item.EndDate.Format("2006-01-02")
+	case 6:
+		return fmt.Sprint(item.EveryMonths)
+	default:
+		return "-"
+	}
+}
@@ -0,0 +1,153 @@
+package gui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image/color"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/EmptyInsid/db_gui/internal/database"
+)
+
+func MainTrash(w fyne.Window, db database.Service, role string) (*fyne.Container, error) {
+	var selected *database.TrashItem
+	selectedLabel := widget.NewLabel("Выберите запись в таблице")
+
+	table, err := TrashTable(db, func(item database.TrashItem) {
+		selected = &item
+		selectedLabel.SetText(trashItemTitle(item))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	editor := AccordionTrash(w, db, role, selectedLabel, func() *database.TrashItem { return selected })
+	return container.NewStack(GridViewer(db, table, editor, role)), nil
+}
+
+// СПИСОК ДЕЙСТВИЙ ДЛЯ КОРЗИНЫ
+func AccordionTrash(w fyne.Window, db database.Service, role string, selectedLabel *widget.Label, selected func() *database.TrashItem) *widget.Accordion {
+	accRestore := RestoreTrash(w, db, role, selectedLabel, selected)
+	accPurge := PurgeTrash(w, db, role)
+
+	editor := widget.NewAccordion(
+		widget.NewAccordionItem("Восстановить", accRestore),
+		widget.NewAccordionItem("Очистить", accPurge),
+	)
+	return editor
+}
+
+// РАЗДЕЛ ВОССТАНОВЛЕНИЯ
+func RestoreTrash(w fyne.Window, db database.Service, role string, selectedLabel *widget.Label, selected func() *database.TrashItem) *fyne.Container {
+	winRestore := WinRestoreTrash(w, db, role, selectedLabel, selected)
+	return container.NewVBox(canvas.NewLine(color.White), winRestore)
+}
+func WinRestoreTrash(w fyne.Window, db database.Service, role string, selectedLabel *widget.Label, selected func() *database.TrashItem) *fyne.Container {
+	ctx := context.Background()
+
+	btn := widget.NewButton("Восстановить", func() {
+		item := selected()
+		if item == nil {
+			dialog.ShowError(ErrEmptyTrashItem, w)
+			return
+		}
+
+		var err error
+		switch item.Entity {
+		case database.TrashArticle:
+			err = db.RestoreArticle(ctx, item.ID)
+		case database.TrashOperation:
+			err = db.RestoreOperation(ctx, item.ID)
+		case database.TrashBalance:
+			err = db.RestoreBalance(ctx, item.ID)
+		}
+
+		switch {
+		case errors.Is(err, database.ErrRestoreConflict):
+			dialog.ShowError(ErrRestoreConflict, w)
+			return
+		case errors.Is(err, database.ErrArticleDeleted):
+			dialog.ShowError(ErrRestoreArticleDeleted, w)
+			return
+		case errors.Is(err, database.ErrPeriodClosed):
+			dialog.ShowError(ErrClosedPeriod, w)
+			return
+		case err != nil:
+			dialog.ShowError(ErrRestore, w)
+			return
+		}
+		dialog.ShowInformation("Восстановить", "Запись успешно восстановлена!", w)
+
+		showTrash(w, db, role)
+	})
+
+	return container.NewVBox(selectedLabel, btn)
+}
+
+// РАЗДЕЛ ОЧИСТКИ
+func PurgeTrash(w fyne.Window, db database.Service, role string) *fyne.Container {
+	winPurge := WinPurgeTrash(w, db, role)
+	return container.NewVBox(canvas.NewLine(color.White), winPurge)
+}
+func WinPurgeTrash(w fyne.Window, db database.Service, role string) *fyne.Container {
+	ctx := context.Background()
+
+	days := widget.NewEntry()
+	days.SetText("30")
+
+	cont := container.NewAdaptiveGrid(2, widget.NewLabel("Старше, дней"), days)
+
+	btn := widget.NewButton("Очистить", func() {
+		olderThan, err := strconv.Atoi(days.Text)
+		if err != nil || olderThan < 0 {
+			dialog.ShowError(ErrTrashDays, w)
+			return
+		}
+
+		dialog.ShowConfirm("Очистить", "Записи будут удалены без возможности восстановления. Продолжить?",
+			func(ok bool) {
+				if !ok {
+					return
+				}
+				purged, err := db.PurgeTrash(ctx, olderThan)
+				if err != nil {
+					dialog.ShowError(ErrPurgeTrash, w)
+					return
+				}
+				dialog.ShowInformation("Очистить", fmt.Sprintf("Удалено записей: %d", purged), w)
+
+				showTrash(w, db, role)
+			},
+			w,
+		)
+	})
+
+	return container.NewVBox(cont, btn)
+}
+
+func showTrash(w fyne.Window, db database.Service, role string) {
+	trashContent, err := MainTrash(w, db, role)
+	if err != nil {
+		dialog.ShowError(ErrShowTrash, w)
+		return
+	}
+	w.SetContent(trashContent)
+}
+
+// краткое описание записи корзины
+func trashItemTitle(item database.TrashItem) string {
+	title := TranslateEntity(item.Entity)
+	if item.Name != "" {
+		title += " " + item.Name
+	}
+	if item.Date != nil {
+		title += " от " + item.Date.Format("2006-01-02")
+	}
+	return title
+}
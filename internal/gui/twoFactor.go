@@ -0,0 +1,132 @@
+package gui
+
+import (
+	"context"
+	"image/color"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/EmptyInsid/db_gui/internal/auth"
+	"github.com/EmptyInsid/db_gui/internal/database"
+)
+
+func MainTwoFactor(w fyne.Window, db database.Service, username string) (*fyne.Container, error) {
+	enabled, err := auth.TwoFactorEnabled(db, context.Background(), username)
+	if err != nil {
+		return nil, err
+	}
+
+	title := MadeTitle("Двухфакторная аутентификация")
+
+	var content *fyne.Container
+	if enabled {
+		content = WinDisableTwoFactor(w, db, username)
+	} else {
+		content, err = WinEnableTwoFactor(w, db, username)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return container.NewCenter(container.NewVBox(title, canvas.NewLine(color.White), content)), nil
+}
+
+// РАЗДЕЛ ПОДКЛЮЧЕНИЯ 2FA
+func WinEnableTwoFactor(w fyne.Window, db database.Service, username string) (*fyne.Container, error) {
+	ctx := context.Background()
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	png, err := auth.TOTPQRCode(auth.TOTPProvisioningURI(username, secret), 256)
+	if err != nil {
+		dialog.ShowError(ErrTwoFactorQR, w)
+		return nil, err
+	}
+	qr := canvas.NewImageFromResource(fyne.NewStaticResource("totp.png", png))
+	qr.FillMode = canvas.ImageFillOriginal
+
+	secretLabel := widget.NewLabel(secret)
+	secretLabel.TextStyle = fyne.TextStyle{Monospace: true}
+
+	code := widget.NewEntry()
+	code.SetPlaceHolder("123456")
+
+	cont := container.NewAdaptiveGrid(2, widget.NewLabel("Код из приложения"), code)
+
+	btn := widget.NewButton("Подключить", func() {
+		codes, err := auth.EnrollTwoFactor(db, ctx, username, secret, code.Text)
+		if err != nil {
+			dialog.ShowError(ErrEnableTwoFactor, w)
+			return
+		}
+
+		showRecoveryCodes(w, codes, func() {
+			twoFactorContent, err := MainTwoFactor(w, db, username)
+			if err != nil {
+				dialog.ShowError(ErrShowTwoFactor, w)
+				return
+			}
+			w.SetContent(twoFactorContent)
+		})
+	})
+
+	return container.NewVBox(
+		widget.NewLabel("Отсканируйте QR-код приложением-аутентификатором\nили введите секрет вручную:"),
+		container.NewCenter(qr),
+		container.NewCenter(secretLabel),
+		cont,
+		btn,
+	), nil
+}
+
+// РАЗДЕЛ ОТКЛЮЧЕНИЯ 2FA
+func WinDisableTwoFactor(w fyne.Window, db database.Service, username string) *fyne.Container {
+	ctx := context.Background()
+
+	code := widget.NewEntry()
+	code.SetPlaceHolder("123456")
+
+	cont := container.NewAdaptiveGrid(2, widget.NewLabel("Код подтверждения"), code)
+
+	btn := widget.NewButton("Отключить", func() {
+		if err := auth.DisableTwoFactor(db, ctx, username, code.Text); err != nil {
+			dialog.ShowError(ErrDisableTwoFactor, w)
+			return
+		}
+		dialog.ShowInformation("Двухфакторная аутентификация", "Двухфакторная аутентификация отключена.", w)
+
+		twoFactorContent, err := MainTwoFactor(w, db, username)
+		if err != nil {
+			dialog.ShowError(ErrShowTwoFactor, w)
+			return
+		}
+		w.SetContent(twoFactorContent)
+	})
+
+	return container.NewVBox(widget.NewLabel("Двухфакторная аутентификация включена."), cont, btn)
+}
+
+// показать коды восстановления один раз после подключения
+func showRecoveryCodes(w fyne.Window, codes []string, onClose func()) {
+	text := widget.NewLabel(strings.Join(codes, "\n"))
+	text.TextStyle = fyne.TextStyle{Monospace: true}
+
+	content := container.NewVBox(
+		widget.NewLabel("Сохраните коды восстановления. Каждый код можно\nиспользовать один раз вместо кода из приложения."),
+		text,
+		widget.NewButton("Скопировать", func() {
+			w.Clipboard().SetContent(strings.Join(codes, "\n"))
+		}),
+	)
+
+	d := dialog.NewCustom("Коды восстановления", "Готово", content, w)
+	d.SetOnClosed(onClose)
+	d.Show()
+}
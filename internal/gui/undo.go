@@ -0,0 +1,282 @@
+package gui
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
+	"github.com/EmptyInsid/db_gui/internal/database"
+)
+
+// действие справочника, которое можно отменить и повторить
+type undoAction struct {
+	name  string
+	dates []time.Time // даты затронутых операций, для проверки закрытых периодов
+	undo  func(ctx context.Context) error
+	redo  func(ctx context.Context) error
+}
+
+// UndoStack хранит историю правок справочника за сессию
+type UndoStack struct {
+	done   []*undoAction
+	undone []*undoAction
+}
+
+// история правок текущей сессии
+var undoHistory = &UndoStack{}
+
+func (s *UndoStack) Clear() {
+	s.done = nil
+	s.undone = nil
+}
+
+func (s *UndoStack) push(action *undoAction) {
+	s.done = append(s.done, action)
+	s.undone = nil
+}
+
+func (s *UndoStack) CanUndo() bool { return len(s.done) > 0 }
+func (s *UndoStack) CanRedo() bool { return len(s.undone) > 0 }
+
+// отменить последнее действие, возвращает его название
+func (s *UndoStack) Undo(ctx context.Context, db database.Service) (string, error) {
+	if !s.CanUndo() {
+		return "", ErrNothingToUndo
+	}
+	action := s.done[len(s.done)-1]
+
+	if err := checkOpenPeriod(ctx, db, action.dates); err != nil {
+		return "", err
+	}
+	if err := action.undo(ctx); err != nil {
+		log.Printf("Error while undo %q: %v", action.name, err)
+		return "", err
+	}
+
+	s.done = s.done[:len(s.done)-1]
+	s.undone = append(s.undone, action)
+	return action.name, nil
+}
+
+// повторить последнее отменённое действие, возвращает его название
+func (s *UndoStack) Redo(ctx context.Context, db database.Service) (string, error) {
+	if !s.CanRedo() {
+		return "", ErrNothingToRedo
+	}
+	action := s.undone[len(s.undone)-1]
+
+	if err := checkOpenPeriod(ctx, db, action.dates); err != nil {
+		return "", err
+	}
+	if err := action.redo(ctx); err != nil {
+		log.Printf("Error while redo %q: %v", action.name, err)
+		return "", err
+	}
+
+	s.undone = s.undone[:len(s.undone)-1]
+	s.done = append(s.done, action)
+	return action.name, nil
+}
+
+// отмена запрещена, если баланс закрыл период затронутых операций
+func checkOpenPeriod(ctx context.Context, db database.Service, dates []time.Time) error {
+	for _, date := range dates {
+		closed, err := db.IsPeriodClosed(ctx, date.Format("2006-01-02"))
+		if err != nil {
+			return err
+		}
+		if closed {
+			return ErrUndoClosedPeriod
+		}
+	}
+	return nil
+}
+
+// ОБРАТНЫЕ ДЕЙСТВИЯ ДЛЯ СТАТЕЙ
+func undoAddArticle(db database.Service, name string) *undoAction {
+	return &undoAction{
+		name: "Добавление статьи " + name,
+		undo: func(ctx context.Context) error { return db.DeleteArticle(ctx, name) },
+		redo: func(ctx context.Context) error { return db.AddArticle(ctx, name) },
+	}
+}
+
+func undoEditArticle(db database.Service, oldName, newName string) *undoAction {
+	return &undoAction{
+		name: "Переименование статьи " + oldName,
+		undo: func(ctx context.Context) error { return db.UpdateArticle(ctx, newName, oldName) },
+		redo: func(ctx context.Context) error { return db.UpdateArticle(ctx, oldName, newName) },
+	}
+}
+
+// статья удаляется в корзину вместе с операциями и восстанавливается из неё вместе с ними
+func undoDeleteArticle(db database.Service, id int, name string, operations []database.ArticleWithOperations) *undoAction {
+	var dates []time.Time
+	for _, op := range operations {
+		dates = append(dates, op.CreateDate)
+	}
+
+	return &undoAction{
+		name:  "Удаление статьи " + name,
+		dates: dates,
+		undo:  func(ctx context.Context) error { return db.RestoreArticle(ctx, id) },
+		redo:  func(ctx context.Context) error { return db.DeleteArticle(ctx, name) },
+	}
+}
+
+// ОБРАТНЫЕ ДЕЙСТВИЯ ДЛЯ ОПЕРАЦИЙ
+func undoAddOperation(db database.Service, id int, article string, debit, credit float64, date time.Time) *undoAction {
+	return &undoAction{
+		name:  "Добавление операции",
+		dates: []time.Time{date},
+		undo:  func(ctx context.Context) error { return db.DeleteOperation(ctx, id) },
+		redo: func(ctx context.Context) error {
+			// повторно добавленная операция получает новый идентификатор
+			newID, err := db.AddOperation(ctx, article, debit, credit, date.Format("2006-01-02"))
+			if err != nil {
+				return err
+			}
+			id = newID
+			return nil
+		},
+	}
+}
+
+func undoEditOperation(db database.Service, before database.ArticleWithOperations, article string, debit, credit float64) *undoAction {
+	return &undoAction{
+		name:  "Изменение операции",
+		dates: []time.Time{before.CreateDate},
+		undo: func(ctx context.Context) error {
+			return db.UpdateOpertions(ctx, before.OperationID, before.ArticleName, before.Debit, before.Credit)
+		},
+		redo: func(ctx context.Context) error {
+			return db.UpdateOpertions(ctx, before.OperationID, article, debit, credit)
+		},
+	}
+}
+
+// удалённая операция восстанавливается из корзины с тем же идентификатором
+func undoDeleteOperation(db database.Service, before database.ArticleWithOperations) *undoAction {
+	id := before.OperationID
+	return &undoAction{
+		name:  "Удаление операции",
+		dates: []time.Time{before.CreateDate},
+		undo:  func(ctx context.Context) error { return db.RestoreOperation(ctx, id) },
+		redo:  func(ctx context.Context) error { return db.DeleteOperation(ctx, id) },
+	}
+}
+
+// отмена возвращает прежний расход именно тем операциям, которые были изменены
+func undoIncreaseExpenses(db database.Service, article string, changes []database.CreditChange) *undoAction {
+	var dates []time.Time
+	before := make(map[int]float64, len(changes))
+	after := make(map[int]float64, len(changes))
+	for _, change := range changes {
+		dates = append(dates, change.CreateDate)
+		before[change.OperationID] = change.Before
+		after[change.OperationID] = change.After
+	}
+
+	return &undoAction{
+		name:  "Повышение расходов по статье " + article,
+		dates: dates,
+		undo:  func(ctx context.Context) error { return db.SetOperationCredits(ctx, before) },
+		redo:  func(ctx context.Context) error { return db.SetOperationCredits(ctx, after) },
+	}
+}
+
+// идентификатор активной статьи по названию
+func articleID(ctx context.Context, db database.Service, name string) (int, error) {
+	articles, err := db.GetAllArticles(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, article := range articles {
+		if article.Name == name {
+			return article.ID, nil
+		}
+	}
+	return 0, database.ErrEmptyRow
+}
+
+// операции статьи из справочника операций
+func articleOperations(ctx context.Context, db database.Service, article string) ([]database.ArticleWithOperations, error) {
+	all, err := db.GetArticlesWithOperations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var operations []database.ArticleWithOperations
+	for _, op := range all {
+		if op.ArticleName == article {
+			operations = append(operations, op)
+		}
+	}
+	return operations, nil
+}
+
+// отменить последнее действие и показать обновлённый справочник
+func UndoLast(w fyne.Window, db database.Service, role string) {
+	name, err := undoHistory.Undo(context.Background(), db)
+	if err != nil {
+		showUndoError(w, err, ErrUndo)
+		return
+	}
+	showDirAfterUndo(w, db, role)
+	dialog.ShowInformation("Отменить", "Отменено: "+name, w)
+}
+
+// повторить отменённое действие и показать обновлённый справочник
+func RedoLast(w fyne.Window, db database.Service, role string) {
+	name, err := undoHistory.Redo(context.Background(), db)
+	if err != nil {
+		showUndoError(w, err, ErrRedo)
+		return
+	}
+	showDirAfterUndo(w, db, role)
+	dialog.ShowInformation("Повторить", "Повторено: "+name, w)
+}
+
+func showUndoError(w fyne.Window, err, fallback error) {
+	switch {
+	case errors.Is(err, ErrNothingToUndo), errors.Is(err, ErrNothingToRedo), errors.Is(err, ErrUndoClosedPeriod):
+		dialog.ShowError(err, w)
+	case errors.Is(err, database.ErrPeriodClosed):
+		dialog.ShowError(ErrUndoClosedPeriod, w)
+	default:
+		dialog.ShowError(fallback, w)
+	}
+}
+
+func showDirAfterUndo(w fyne.Window, db database.Service, role string) {
+	dirContent, err := MainDir(w, db, role)
+	if err != nil {
+		dialog.ShowError(ErrShowDir, w)
+		return
+	}
+	w.SetContent(dirContent)
+}
+
+// горячие клавиши отмены и повтора
+var (
+	undoShortcut = &desktop.CustomShortcut{KeyName: fyne.KeyZ, Modifier: fyne.KeyModifierShortcutDefault}
+	redoShortcut = &desktop.CustomShortcut{KeyName: fyne.KeyY, Modifier: fyne.KeyModifierShortcutDefault}
+)
+
+// меню правки с отменой и повтором, горячие клавиши регистрируются в окне
+func EditMenu(w fyne.Window, db database.Service, role string) *fyne.Menu {
+	undo := fyne.NewMenuItem("Отменить", func() { UndoLast(w, db, role) })
+	undo.Shortcut = undoShortcut
+	redo := fyne.NewMenuItem("Повторить", func() { RedoLast(w, db, role) })
+	redo.Shortcut = redoShortcut
+
+	w.Canvas().RemoveShortcut(undoShortcut)
+	w.Canvas().RemoveShortcut(redoShortcut)
+	w.Canvas().AddShortcut(undoShortcut, func(fyne.Shortcut) { UndoLast(w, db, role) })
+	w.Canvas().AddShortcut(redoShortcut, func(fyne.Shortcut) { RedoLast(w, db, role) })
+
+	return fyne.NewMenu("Правка", undo, redo)
+}
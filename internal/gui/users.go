@@ -0,0 +1,97 @@
+package gui
+
+import (
+	"context"
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/EmptyInsid/db_gui/internal/auth"
+	"github.com/EmptyInsid/db_gui/internal/database"
+)
+
+func MainUsers(w fyne.Window, db database.Service, role string) (*fyne.Container, error) {
+	usersContent, err := TabsUsers(w, db, role)
+	if err != nil {
+		return nil, err
+	}
+	return container.NewStack(usersContent), nil
+}
+
+func TabsUsers(w fyne.Window, db database.Service, role string) (*container.AppTabs, error) {
+	historyTable, err := LoginHistoryTable(db)
+	if err != nil {
+		return nil, err
+	}
+
+	attemptsTable, err := LoginAttemptsTable(db)
+	if err != nil {
+		return nil, err
+	}
+	editor := AccordionUsers(w, db, role)
+
+	auditContent, err := AuditViewer(w, db)
+	if err != nil {
+		return nil, err
+	}
+
+	history := container.NewTabItem("История входов", container.NewStack(historyTable))
+	locks := container.NewTabItem("Блокировки", GridViewer(db, attemptsTable, editor, role))
+
+	audit := container.NewTabItem("Журнал изменений", auditContent)
+
+	tab := container.NewAppTabs(history, locks, audit)
+	tab.SetTabLocation(container.TabLocationTop)
+	return tab, nil
+}
+
+// СПИСОК ДЕЙСТВИЙ ДЛЯ ПОЛЬЗОВАТЕЛЕЙ
+func AccordionUsers(w fyne.Window, db database.Service, role string) *widget.Accordion {
+	accUnlock := UnlockUser(w, db, role)
+
+	editor := widget.NewAccordion(
+		widget.NewAccordionItem("Разблокировать", accUnlock),
+	)
+	return editor
+}
+
+// РАЗДЕЛ РАЗБЛОКИРОВКИ ПОЛЬЗОВАТЕЛЯ
+func UnlockUser(w fyne.Window, db database.Service, role string) *fyne.Container {
+	winUnlockUser := WinUnlockUser(w, db, role)
+	return container.NewVBox(canvas.NewLine(color.White), winUnlockUser)
+}
+func WinUnlockUser(w fyne.Window, db database.Service, role string) *fyne.Container {
+	ctx := context.Background()
+
+	username := widget.NewEntry()
+	username.SetPlaceHolder("логин")
+
+	cont := container.NewAdaptiveGrid(2, widget.NewLabel("Пользователь"), username)
+
+	btn := widget.NewButton("Разблокировать", func() {
+
+		if username.Text == "" {
+			dialog.ShowError(ErrEmptyUser, w)
+			return
+		}
+
+		if err := auth.UnlockUser(db, ctx, username.Text); err != nil {
+			dialog.ShowError(ErrUnlockUser, w)
+			return
+		} else {
+			dialog.ShowInformation("Разблокировать", "Пользователь успешно разблокирован!", w)
+		}
+
+		usersContent, err := MainUsers(w, db, role)
+		if err != nil {
+			dialog.ShowError(ErrShowUsers, w)
+			return
+		}
+		w.SetContent(usersContent)
+	})
+
+	return container.NewVBox(cont, btn)
+}
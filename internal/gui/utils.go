@@ -2,6 +2,7 @@ package gui
 
 import (
 	"context"
+	"errors"
 	"image/color"
 	"log"
 	"strings"
@@ -13,16 +14,44 @@ import (
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 	"github.com/EmptyInsid/db_gui/internal/database"
+	"github.com/EmptyInsid/db_gui/internal/report"
 )
 
-func isEndOfMonth(date time.Time) bool {
-	nextDay := date.AddDate(0, 0, 1)
-	return nextDay.Day() == 1
+// названия типов периодов баланса
+var periodNames = map[string]string{
+	database.PeriodWeek:    "неделя",
+	database.PeriodMonth:   "месяц",
+	database.PeriodQuarter: "квартал",
+	database.PeriodYear:    "год",
+	database.PeriodCustom:  "произвольный",
 }
 
-func getStartOfMonth(t time.Time) time.Time {
-	// Устанавливаем день месяца равным 1
-	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+func TranslatePeriod(periodType string) string {
+	if name, ok := periodNames[periodType]; ok {
+		return name
+	}
+	return periodType
+}
+
+// выбор типа периода баланса
+func MadeSelectPeriod() *widget.Select {
+	var names []string
+	for _, periodType := range database.PeriodTypes {
+		names = append(names, periodNames[periodType])
+	}
+	period := widget.NewSelect(names, nil)
+	period.SetSelected(periodNames[database.PeriodMonth])
+	return period
+}
+
+// тип периода по выбранному названию
+func SelectedPeriod(period *widget.Select) string {
+	for _, periodType := range database.PeriodTypes {
+		if periodNames[periodType] == period.Selected {
+			return periodType
+		}
+	}
+	return database.PeriodCustom
 }
 
 func MadeSelectArticle(w fyne.Window, db database.Service) *widget.Select {
@@ -43,7 +72,8 @@ func LoadArticles(articlesContainer *fyne.Container) []string {
 	// Сбор всех статей из контейнера
 	articles := []string{}
 	for _, obj := range articlesContainer.Objects {
-		if entry, ok := obj.(*widget.Select); ok {
+		// невыбранные поля пропускаются
+		if entry, ok := obj.(*widget.Select); ok && strings.TrimSpace(entry.Selected) != "" {
 			articles = append(articles, strings.TrimSpace(entry.Selected))
 			log.Print(entry.Selected)
 		}
@@ -60,6 +90,43 @@ func MadeDateFields() (*widget.Entry, *widget.Entry) {
 	return startDate, endDate
 }
 
+// подпись периода, который вводится вручную
+const customPeriodLabel = "Произвольный"
+
+// MadePresetSelect - быстрый выбор относительного периода. onPreset получает ключ периода,
+// для произвольного периода - пустую строку
+func MadePresetSelect(onPreset func(preset string)) *widget.Select {
+	labels := make([]string, 0, len(report.Presets)+1)
+	for _, preset := range report.Presets {
+		labels = append(labels, preset.Label)
+	}
+	labels = append(labels, customPeriodLabel)
+
+	presetSelect := widget.NewSelect(labels, func(label string) {
+		onPreset(presetKey(label))
+	})
+	presetSelect.PlaceHolder = "быстрый выбор периода"
+	return presetSelect
+}
+
+func presetKey(label string) string {
+	for _, preset := range report.Presets {
+		if preset.Label == label {
+			return preset.Value
+		}
+	}
+	return ""
+}
+
+func presetLabel(key string) string {
+	for _, preset := range report.Presets {
+		if preset.Value == key {
+			return preset.Label
+		}
+	}
+	return customPeriodLabel
+}
+
 func MadeTitle(titleText string) *canvas.Text {
 	title := canvas.NewText(titleText, color.RGBA{R: 135, G: 206, B: 250, A: 255})
 	title.TextStyle = fyne.TextStyle{Bold: true}
@@ -104,27 +171,6 @@ func MadeArticlesButton(db database.Service) (*fyne.Container, *widget.Button, *
 	return articlesContainer, addArticleButton, delArticleButton, nil
 }
 
-func TranslateFlow(flow string) string {
-	switch flow {
-	case "расход":
-		{
-			return "credit"
-		}
-	case "доход":
-		{
-			return "debit"
-		}
-	case "прибыль":
-		{
-			return "profit"
-		}
-	default:
-		{
-			return "idk"
-		}
-	}
-}
-
 func CompareDate(start, end string) error {
 	const layout = "2006-01-02"
 
@@ -146,3 +192,11 @@ func CompareDate(start, end string) error {
 	}
 	return nil
 }
+
+// понятное сообщение, если изменение затрагивает закрытый период
+func closedPeriodOr(err, fallback error) error {
+	if errors.Is(err, database.ErrPeriodClosed) {
+		return ErrClosedPeriod
+	}
+	return fallback
+}
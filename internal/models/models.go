@@ -6,6 +6,7 @@ import "time"
 type Article struct {
 	ID   int    `json:"id"`
 	Name string `json:"name"`
+	Tag  string `json:"tag"`
 }
 
 // Operation представляет операцию (доход/расход)
@@ -25,4 +26,77 @@ type Balance struct {
 	Debit  float64   `json:"debit"`
 	Credit float64   `json:"credit"`
 	Amount float64   `json:"amount"`
+
+	StartDate  time.Time `json:"start_date"` // начало периода, Date - его конец
+	PeriodType string    `json:"period_type"`
+	OnHand     float64   `json:"on_hand"` // деньги на руках на конец периода
+
+	ReopenedAt *time.Time `json:"reopened_at"` // период открыт повторно администратором
+	ReopenedBy string     `json:"reopened_by"`
+}
+
+// LoginAttempts представляет счётчик неудачных попыток входа пользователя
+type LoginAttempts struct {
+	Username     string     `json:"username"`
+	FailedCount  int        `json:"failed_count"`
+	LastFailedAt *time.Time `json:"last_failed_at"`
+	LockedUntil  *time.Time `json:"locked_until"`
+}
+
+// виды событий истории входов
+const (
+	LoginEventLogin  = "login"
+	LoginEventUnlock = "unlock"
+)
+
+// LoginRecord представляет запись в истории входов
+type LoginRecord struct {
+	ID       int       `json:"id"`
+	Username string    `json:"username"`
+	Success  bool      `json:"success"`
+	Reason   string    `json:"reason"`
+	Event    string    `json:"event"` // login или unlock
+	Date     time.Time `json:"created_at"`
+}
+
+// RecoveryCode представляет одноразовый код восстановления двухфакторной аутентификации
+type RecoveryCode struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+	CodeHash string `json:"code_hash"`
+}
+
+// Household представляет домохозяйство - отдельный бюджет со своими статьями, операциями и балансами
+type Household struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// RecurringItem - регулярный доход или расход статьи раз в EveryMonths месяцев
+type RecurringItem struct {
+	ID          int        `json:"id"`
+	ArticleName string     `json:"article_name"`
+	Debit       float64    `json:"debit"`
+	Credit      float64    `json:"credit"`
+	StartDate   time.Time  `json:"start_date"`
+	EndDate     *time.Time `json:"end_date"`
+	EveryMonths int        `json:"every_months"`
+}
+
+// OpeningBalance - деньги на руках на начало даты, без даты - до первой операции
+type OpeningBalance struct {
+	Amount float64    `json:"opening_balance"`
+	Date   *time.Time `json:"opening_date"`
+}
+
+// AuditEntry представляет запись журнала изменений с состоянием данных до и после
+type AuditEntry struct {
+	ID          int       `json:"id"`
+	HouseholdID int       `json:"household_id"`
+	Username    string    `json:"username"`
+	Action      string    `json:"action"`
+	Entity      string    `json:"entity"`
+	Before      string    `json:"before_data"`
+	After       string    `json:"after_data"`
+	Date        time.Time `json:"created_at"`
 }
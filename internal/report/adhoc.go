@@ -0,0 +1,101 @@
+package report
+
+import (
+	"context"
+	"strings"
+
+	"github.com/EmptyInsid/db_gui/internal/database"
+)
+
+// подписи и типы колонок измерений и показателей конструктора
+var (
+	DimensionColumns = map[string]Column{
+		database.DimArticle: {Header: "Статья", Kind: ColText, Width: 150},
+		database.DimMonth:   {Header: "Месяц", Kind: ColMonth},
+		database.DimWeek:    {Header: "Неделя", Kind: ColDate},
+		database.DimTag:     {Header: "Метка", Kind: ColText},
+		database.DimBalance: {Header: "Баланс", Kind: ColText, Width: 150},
+	}
+	MeasureColumns = map[string]Column{
+		database.MeasureDebit:     {Header: "Доход", Kind: ColMoney, Sum: true},
+		database.MeasureCredit:    {Header: "Расход", Kind: ColMoney, Sum: true},
+		database.MeasureProfit:    {Header: "Прибыль", Kind: ColMoney, Sum: true},
+		database.MeasureCount:     {Header: "Операций", Kind: ColCount, Sum: true},
+		database.MeasureAvgDebit:  {Header: "Средний доход", Kind: ColMoney},
+		database.MeasureAvgCredit: {Header: "Средний расход", Kind: ColMoney},
+	}
+)
+
+// порядок измерений и показателей в форме конструктора
+var (
+	Dimensions = []string{database.DimArticle, database.DimMonth, database.DimWeek, database.DimTag, database.DimBalance}
+	Measures   = []string{database.MeasureDebit, database.MeasureCredit, database.MeasureProfit,
+		database.MeasureCount, database.MeasureAvgDebit, database.MeasureAvgCredit}
+)
+
+// Adhoc - описание отчёта конструктора, параметры уже заданы в spec.
+// По времени строится линия, по остальным измерениям - столбцы первого показателя
+func Adhoc(name string, spec database.AdhocSpec) *Definition {
+	def := &Definition{
+		ID:    "adhoc",
+		Name:  name,
+		Title: name,
+		Load: func(ctx context.Context, db database.Service, values Values) (*Result, error) {
+			rows, err := db.RunAdhocReport(ctx, spec)
+			if err != nil {
+				return nil, err
+			}
+			return &Result{Rows: rows, Params: adhocParams(spec)}, nil
+		},
+	}
+
+	for _, dim := range spec.Dimensions {
+		def.Columns = append(def.Columns, DimensionColumns[dim])
+	}
+	for _, measure := range spec.Measures {
+		def.Columns = append(def.Columns, MeasureColumns[measure])
+	}
+
+	if len(spec.Dimensions) == 1 && len(spec.Measures) > 0 {
+		chart := &ChartSpec{Kind: ChartBar, Title: name, YLabel: "Amount"}
+		if dim := spec.Dimensions[0]; dim == database.DimMonth || dim == database.DimWeek {
+			chart.Kind = ChartLine
+		}
+		for i, measure := range spec.Measures {
+			chart.Series = append(chart.Series, Series{Name: MeasureColumns[measure].Header, Column: i + 1})
+		}
+		def.Chart = chart
+	}
+	return def
+}
+
+// параметры конструктора для выгрузок
+func adhocParams(spec database.AdhocSpec) []ParamValue {
+	headers := func(keys []string, columns map[string]Column) string {
+		names := make([]string, len(keys))
+		for i, key := range keys {
+			names[i] = columns[key].Header
+		}
+		return strings.Join(names, ", ")
+	}
+	optional := func(value any, ok bool) any {
+		if !ok {
+			return "-"
+		}
+		return value
+	}
+
+	articles := "все"
+	if len(spec.Articles) > 0 {
+		articles = strings.Join(spec.Articles, ", ")
+	}
+	return []ParamValue{
+		{Label: "Измерения", Value: headers(spec.Dimensions, DimensionColumns)},
+		{Label: "Показатели", Value: headers(spec.Measures, MeasureColumns)},
+		{Label: "Период (начало)", Value: optional(paramDate(spec.StartDate), spec.StartDate != "")},
+		{Label: "Период (конец)", Value: optional(paramDate(spec.EndDate), spec.EndDate != "")},
+		{Label: "Статьи", Value: articles},
+		{Label: "Сумма от", Value: optional(spec.MinAmount, spec.MinAmount != nil)},
+		{Label: "Сумма до", Value: optional(spec.MaxAmount, spec.MaxAmount != nil)},
+	}
+}
@@ -0,0 +1,213 @@
+package report
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgimg"
+)
+
+// цвета серий по порядку
+var palette = []color.Color{
+	color.RGBA{R: 0, G: 90, B: 200, A: 255},
+	color.RGBA{R: 220, G: 60, B: 60, A: 255},
+	color.RGBA{R: 230, G: 160, B: 0, A: 255},
+	color.RGBA{R: 40, G: 160, B: 80, A: 255},
+	color.RGBA{R: 130, G: 80, B: 190, A: 255},
+}
+
+var bandColor = color.RGBA{R: 180, G: 210, B: 255, A: 255}
+
+// PlotOptions - вид графика и скрытые серии (по индексу в ChartSpec.Series)
+type PlotOptions struct {
+	Kind   ChartKind
+	Hidden map[int]bool
+}
+
+// Plot строит график отчёта по его описанию, nil - у отчёта нет графика
+func Plot(def *Definition, result *Result) (*plot.Plot, error) {
+	if def.Chart == nil {
+		return nil, nil
+	}
+	return PlotWith(def, result, PlotOptions{Kind: def.Chart.Kind})
+}
+
+// PlotImage рисует график в изображение в памяти заданного размера и разрешения
+func PlotImage(p *plot.Plot, width, height vg.Length, dpi int) image.Image {
+	canvas := vgimg.NewWith(vgimg.UseWH(width, height), vgimg.UseDPI(dpi))
+	p.Draw(draw.New(canvas))
+	return canvas.Image()
+}
+
+// PlotWith строит график с выбранным видом и набором серий
+func PlotWith(def *Definition, result *Result, options PlotOptions) (*plot.Plot, error) {
+	spec := def.Chart
+	if spec == nil {
+		return nil, nil
+	}
+
+	p := plot.New()
+	p.Title.Text = spec.Title
+	p.X.Label.Text = spec.XLabel
+	p.Y.Label.Text = spec.YLabel
+
+	labels := Labels(def, result)
+
+	// цвет серии не зависит от того, какие серии скрыты
+	var series []Series
+	var colors []color.Color
+	for i, s := range spec.Series {
+		if !options.Hidden[i] {
+			series = append(series, s)
+			colors = append(colors, palette[i%len(palette)])
+		}
+	}
+
+	var err error
+	switch options.Kind {
+	case ChartPie, ChartDonut:
+		addPie(p, ChartSlices(def, result), options.Kind == ChartDonut)
+	case ChartBar:
+		// без строк столбцов нет, а NominalX не принимает пустой список подписей
+		if len(result.Rows) == 0 {
+			break
+		}
+		err = addBars(p, series, colors, result)
+		p.NominalX(labels...)
+	case ChartStackedArea:
+		err = addStackedAreas(p, series, colors, result)
+		p.X.Tick.Marker = plot.ConstantTicks(DateTicks(labels))
+	default:
+		err = addLines(p, spec.Band, series, colors, result)
+		p.X.Tick.Marker = plot.ConstantTicks(DateTicks(labels))
+		p.Y.Tick.Marker = plot.DefaultTicks{}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Labels - подписи точек оси X из колонки Category
+func Labels(def *Definition, result *Result) []string {
+	spec := def.Chart
+	labels := make([]string, len(result.Rows))
+	for i, row := range result.Rows {
+		labels[i] = Format(def.Columns[spec.Category], row[spec.Category])
+	}
+	return labels
+}
+
+func addLines(p *plot.Plot, band *Band, series []Series, colors []color.Color, result *Result) error {
+	if band != nil && len(result.Rows) > 0 {
+		// верхняя граница слева направо, нижняя - обратно
+		points := make(plotter.XYs, 0, 2*len(result.Rows))
+		for i, row := range result.Rows {
+			points = append(points, plotter.XY{X: float64(i), Y: Number(row[band.High])})
+		}
+		for i := len(result.Rows) - 1; i >= 0; i-- {
+			points = append(points, plotter.XY{X: float64(i), Y: Number(result.Rows[i][band.Low])})
+		}
+
+		polygon, err := plotter.NewPolygon(points)
+		if err != nil {
+			return fmt.Errorf("ошибка создания области %s: %v", band.Name, err)
+		}
+		polygon.Color = bandColor
+		polygon.LineStyle.Width = 0
+		p.Add(polygon)
+		p.Legend.Add(band.Name, polygon)
+	}
+
+	for i, series := range series {
+		points := make(plotter.XYs, len(result.Rows))
+		for j, row := range result.Rows {
+			points[j].X = float64(j)
+			points[j].Y = Number(row[series.Column])
+		}
+
+		line, err := plotter.NewLine(points)
+		if err != nil {
+			return fmt.Errorf("ошибка создания линии %s: %v", series.Name, err)
+		}
+		line.Color = colors[i]
+		p.Add(line)
+		p.Legend.Add(series.Name, line)
+	}
+	return nil
+}
+
+func addBars(p *plot.Plot, series []Series, colors []color.Color, result *Result) error {
+	width := vg.Points(15)
+	// столбцы серий стоят рядом, по центру подписи
+	offset := -width * vg.Length(len(series)-1) / 2
+
+	for i, series := range series {
+		values := make(plotter.Values, len(result.Rows))
+		for j, row := range result.Rows {
+			values[j] = Number(row[series.Column])
+		}
+
+		bars, err := plotter.NewBarChart(values, width)
+		if err != nil {
+			return fmt.Errorf("ошибка создания столбцов %s: %v", series.Name, err)
+		}
+		bars.Color = colors[i]
+		bars.LineStyle.Width = 0
+		bars.Offset = offset + width*vg.Length(i)
+		p.Add(bars)
+		p.Legend.Add(series.Name, bars)
+	}
+	p.Legend.Top = true
+	return nil
+}
+
+// серии закрашиваются одна над другой: область каждой - между суммой
+// предыдущих серий и суммой с её значениями
+func addStackedAreas(p *plot.Plot, series []Series, colors []color.Color, result *Result) error {
+	if len(result.Rows) == 0 {
+		return nil
+	}
+
+	lower := make([]float64, len(result.Rows))
+	for i, series := range series {
+		upper := make([]float64, len(result.Rows))
+		points := make(plotter.XYs, 0, 2*len(result.Rows))
+		for j, row := range result.Rows {
+			upper[j] = lower[j] + Number(row[series.Column])
+			points = append(points, plotter.XY{X: float64(j), Y: upper[j]})
+		}
+		for j := len(result.Rows) - 1; j >= 0; j-- {
+			points = append(points, plotter.XY{X: float64(j), Y: lower[j]})
+		}
+
+		area, err := plotter.NewPolygon(points)
+		if err != nil {
+			return fmt.Errorf("ошибка создания области %s: %v", series.Name, err)
+		}
+		area.Color = colors[i]
+		area.LineStyle.Width = 0
+		p.Add(area)
+		p.Legend.Add(series.Name, area)
+
+		lower = upper
+	}
+	return nil
+}
+
+// DateTicks - подписи оси X по индексам точек
+func DateTicks(dates []string) []plot.Tick {
+	var ticks []plot.Tick
+	for i, date := range dates {
+		ticks = append(ticks, plot.Tick{
+			Value: float64(i), // Координата точки на оси X
+			Label: date,       // Подпись (дата)
+		})
+	}
+	return ticks
+}
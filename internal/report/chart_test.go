@@ -0,0 +1,45 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/EmptyInsid/db_gui/internal/database"
+	"gonum.org/v1/plot/vg"
+)
+
+// отчёты с графиками: зарегистрированные и конструктор по каждому измерению
+func chartDefinitions() []*Definition {
+	defs := All()
+	for _, dim := range Dimensions {
+		defs = append(defs, Adhoc("adhoc "+dim, database.AdhocSpec{Dimensions: []string{dim}, Measures: Measures}))
+	}
+	return defs
+}
+
+// график пустого результата строится и рисуется любым видом без паники
+func TestPlotWithEmptyResult(t *testing.T) {
+	kinds := []ChartKind{ChartLine, ChartBar, ChartStackedArea, ChartPie, ChartDonut}
+	for _, def := range chartDefinitions() {
+		if def.Chart == nil {
+			continue
+		}
+		for _, kind := range kinds {
+			p, err := PlotWith(def, &Result{}, PlotOptions{Kind: kind})
+			if err != nil {
+				t.Errorf("%s, kind %d: %v", def.Name, kind, err)
+				continue
+			}
+			PlotImage(p, 4*vg.Inch, 3*vg.Inch, 72)
+		}
+	}
+}
+
+// легенда конструктора подписана так же, как колонки таблицы
+func TestAdhocSeriesNames(t *testing.T) {
+	def := Adhoc("adhoc", database.AdhocSpec{Dimensions: []string{database.DimArticle}, Measures: Measures})
+	for i, series := range def.Chart.Series {
+		if want := def.Columns[series.Column].Header; series.Name != want {
+			t.Errorf("series %d: name %q, want %q", i, series.Name, want)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package report
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// RenderCSV пишет таблицу отчёта с заголовками колонок и итоговой строкой
+func RenderCSV(def *Definition, result *Result, w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	headers := make([]string, len(def.Columns))
+	for i, column := range def.Columns {
+		headers[i] = column.Header
+	}
+	if err := writer.Write(headers); err != nil {
+		return err
+	}
+
+	for _, row := range result.Rows {
+		if err := writer.Write(formatRow(def, row)); err != nil {
+			return err
+		}
+	}
+	if result.Total != nil {
+		if err := writer.Write(formatRow(def, result.Total)); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,20 @@
+package report
+
+import "time"
+
+// ShiftDate сдвигает дату на годы и месяцы; день ограничивается длиной месяца,
+// а конец месяца остаётся концом месяца
+func ShiftDate(date string, years, months int) (string, error) {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return "", err
+	}
+	first := time.Date(t.Year()+years, t.Month()+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+	lastDay := first.AddDate(0, 1, -1).Day()
+
+	day := t.Day()
+	if day > lastDay || t.AddDate(0, 0, 1).Day() == 1 {
+		day = lastDay
+	}
+	return first.AddDate(0, 0, day-1).Format("2006-01-02"), nil
+}
@@ -0,0 +1,225 @@
+package report
+
+import (
+	"context"
+	"time"
+
+	"github.com/EmptyInsid/db_gui/internal/database"
+	"github.com/EmptyInsid/db_gui/internal/forecast"
+)
+
+var flowOptions = []Option{
+	{Label: "расход", Value: "credit"},
+	{Label: "доход", Value: "debit"},
+	{Label: "прибыль", Value: "profit"},
+}
+
+func init() {
+	Register(IncomeExpenseDynamics)
+	Register(FinancialPercentages)
+	Register(TotalProfitDate)
+	Register(Comparison)
+	Register(Forecast)
+}
+
+// Отчёт 1: доходы и расходы выбранных статей по датам
+var IncomeExpenseDynamics = &Definition{
+	ID:    "dynamics",
+	Name:  "Отчёт 1",
+	Title: "Отчёт 1 Динамика изменения расходов и доходов.",
+	Params: []Param{
+		{Key: "period", Label: "Период", Kind: ParamPeriod},
+		{Key: "articles", Label: "Статьи", Kind: ParamArticles},
+	},
+	Columns: []Column{
+		{Header: "Дата", Kind: ColDate},
+		{Header: "Общий доход", Kind: ColMoney, Sum: true},
+		{Header: "Общий расход", Kind: ColMoney, Sum: true},
+	},
+	Chart: &ChartSpec{
+		Kind:   ChartLine,
+		Title:  "Credit and Debit Over Time",
+		XLabel: "Date",
+		YLabel: "Amount",
+		Series: []Series{{Name: "Debit", Column: 1}, {Name: "Credit", Column: 2}},
+	},
+	Load: func(ctx context.Context, db database.Service, values Values) (*Result, error) {
+		start, end := values.Period("period")
+		data, err := db.GetIncomeExpenseDynamics(ctx, values.Articles("articles"), start, end)
+		if err != nil {
+			return nil, err
+		}
+
+		result := &Result{}
+		for _, row := range data {
+			result.Rows = append(result.Rows, []any{row.Date, row.TotalDebit, row.TotalCredit})
+		}
+		return result, nil
+	},
+}
+
+// Отчёт 2: доля каждой статьи в выбранном потоке
+var FinancialPercentages = &Definition{
+	ID:    "percentages",
+	Name:  "Отчёт 2",
+	Title: "Отчёт 2 Процентное соотношение финансовых потоков по статьям.",
+	Params: []Param{
+		{Key: "period", Label: "Период", Kind: ParamPeriod},
+		{Key: "flow", Label: "Тип потока", Kind: ParamChoice, Options: flowOptions},
+		{Key: "articles", Label: "Статьи", Kind: ParamArticles},
+	},
+	Columns: []Column{
+		{Header: "Статья", Kind: ColText, Width: 150},
+		{Header: "Общий доход", Kind: ColMoney, Sum: true},
+		{Header: "Общий расход", Kind: ColMoney, Sum: true},
+		{Header: "Прибыль", Kind: ColMoney, Sum: true},
+		{Header: "Процент", Kind: ColPercent},
+	},
+	Chart: &ChartSpec{
+		Kind:   ChartPie,
+		Title:  "Share of Flow",
+		Series: []Series{{Name: "Share", Column: 4}},
+	},
+	Load: func(ctx context.Context, db database.Service, values Values) (*Result, error) {
+		start, end := values.Period("period")
+		data, err := db.GetFinancialPercentages(ctx, values.Articles("articles"), values.Get("flow"), start, end)
+		if err != nil {
+			return nil, err
+		}
+
+		result := &Result{}
+		for _, row := range data {
+			result.Rows = append(result.Rows, []any{row.ArticleName, row.TotalDebit, row.TotalCredit, row.TotalProfit, row.TotalProc})
+		}
+		return result, nil
+	},
+}
+
+// Отчёт 3: чистая прибыль и деньги на руках по датам
+var TotalProfitDate = &Definition{
+	ID:    "profit",
+	Name:  "Отчёт 3",
+	Title: "Отчёт 3 Чистая прибыль бюджета от времени.",
+	Params: []Param{
+		{Key: "period", Label: "Период", Kind: ParamPeriod},
+	},
+	Columns: []Column{
+		{Header: "Дата", Kind: ColDate},
+		{Header: "Прибыль", Kind: ColMoney, Sum: true},
+		{Header: "На руках", Kind: ColMoney},
+	},
+	Chart: &ChartSpec{
+		Kind:   ChartLine,
+		Title:  "Profit Over Time",
+		XLabel: "Date",
+		YLabel: "Amount",
+		Series: []Series{{Name: "Profit", Column: 1}, {Name: "On hand", Column: 2}},
+	},
+	Load: func(ctx context.Context, db database.Service, values Values) (*Result, error) {
+		start, end := values.Period("period")
+		data, err := db.GetTotalProfitDate(ctx, start, end)
+		if err != nil {
+			return nil, err
+		}
+
+		result := &Result{}
+		for _, row := range data {
+			result.Rows = append(result.Rows, []any{row.Date, row.TotalProfit, row.OnHand})
+		}
+		return result, nil
+	},
+}
+
+// сравнение потока по статьям за текущий и базовый периоды
+var Comparison = &Definition{
+	ID:    "comparison",
+	Name:  "Сравнение периодов",
+	Title: "Сравнение периодов по статьям.",
+	Params: []Param{
+		{Key: "current", Label: "Текущий период", Kind: ParamPeriod},
+		{Key: "base", Label: "Базовый период", Kind: ParamPeriod, ShiftFrom: "current"},
+		{Key: "flow", Label: "Тип потока", Kind: ParamChoice, Options: flowOptions},
+		{Key: "articles", Label: "Статьи", Kind: ParamArticles},
+	},
+	Columns: []Column{
+		{Header: "Статья", Kind: ColText, Width: 150},
+		{Header: "Базовый", Kind: ColMoney},
+		{Header: "Текущий", Kind: ColMoney},
+		{Header: "Изменение", Kind: ColMoney, Signed: true},
+		{Header: "Изменение, %", Kind: ColPercent, Signed: true},
+	},
+	Chart: &ChartSpec{
+		Kind:   ChartBar,
+		Title:  "Period Comparison",
+		YLabel: "Amount",
+		Series: []Series{{Name: "Base", Column: 1}, {Name: "Current", Column: 2}},
+	},
+	Load: func(ctx context.Context, db database.Service, values Values) (*Result, error) {
+		baseStart, baseEnd := values.Period("base")
+		currentStart, currentEnd := values.Period("current")
+		data, err := db.GetArticleComparison(ctx, values.Articles("articles"), values.Get("flow"), baseStart, baseEnd, currentStart, currentEnd)
+		if err != nil {
+			return nil, err
+		}
+
+		result := &Result{}
+		for _, row := range data {
+			result.Rows = append(result.Rows, comparisonRow(row))
+		}
+		result.Total = comparisonRow(database.ComparisonTotal(data))
+		return result, nil
+	},
+}
+
+func comparisonRow(row database.ArticleComparison) []any {
+	return []any{row.ArticleName, row.Base, row.Current, row.Change, row.ChangeProc}
+}
+
+// прогноз денежного потока на следующие месяцы
+var Forecast = &Definition{
+	ID:    "forecast",
+	Name:  "Прогноз",
+	Title: "Прогноз денежного потока.",
+	Params: []Param{
+		{Key: "months", Label: "Месяцев прогноза", Kind: ParamNumber, Default: "6"},
+		{Key: "history", Label: "Месяцев истории", Kind: ParamNumber, Default: "24"},
+	},
+	Columns: []Column{
+		{Header: "Месяц", Kind: ColMonth, Width: 80},
+		{Header: "Доход", Kind: ColMoney, Width: 80, Sum: true},
+		{Header: "Расход", Kind: ColMoney, Width: 80, Sum: true},
+		{Header: "Прибыль", Kind: ColMoney, Width: 80, Sum: true},
+		{Header: "На руках", Kind: ColMoney, Width: 80},
+		{Header: "Не ниже", Kind: ColMoney, Width: 80},
+		{Header: "Не выше", Kind: ColMoney, Width: 80},
+	},
+	Chart: &ChartSpec{
+		Kind:   ChartLine,
+		Title:  "Cash Flow Forecast",
+		XLabel: "Month",
+		YLabel: "Amount",
+		Series: []Series{{Name: "On hand", Column: 4}, {Name: "Net", Column: 3}},
+		Band:   &Band{Name: "Confidence band", Low: 5, High: 6},
+	},
+	Load: func(ctx context.Context, db database.Service, values Values) (*Result, error) {
+		months, err := values.Int("months")
+		if err != nil {
+			return nil, err
+		}
+		history, err := values.Int("history")
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := forecast.Build(db, ctx, forecast.Params{Months: months, HistoryMonths: history}, time.Now())
+		if err != nil {
+			return nil, err
+		}
+
+		result := &Result{}
+		for _, row := range data {
+			result.Rows = append(result.Rows, []any{row.Month, row.Income, row.Expense, row.Net, row.Cumulative, row.Low, row.High})
+		}
+		return result, nil
+	},
+}
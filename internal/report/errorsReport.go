@@ -0,0 +1,11 @@
+package report
+
+import "errors"
+
+var (
+	ErrUnknownReport  = errors.New("Report is not registered")
+	ErrInvalidDate    = errors.New("Report date must be in YYYY-MM-DD format")
+	ErrEndBeforeStart = errors.New("Report period ends before it starts")
+	ErrInvalidNumber  = errors.New("Report number parameter must be a positive integer")
+	ErrUnknownPreset  = errors.New("Relative report period is unknown")
+)
@@ -0,0 +1,57 @@
+package report
+
+import (
+	"io"
+	"os"
+)
+
+// Exporter - формат сохранения отчёта. Render пишет документ в поток,
+// поэтому отчёты можно строить параллельно и без временных файлов
+type Exporter struct {
+	Name      string // подпись кнопки
+	Extension string
+	Render    func(def *Definition, result *Result, w io.Writer) error
+}
+
+// Write сохраняет отчёт в файл
+func (e Exporter) Write(def *Definition, result *Result, filename string) error {
+	return writeFile(filename, func(w io.Writer) error {
+		return e.Render(def, result, w)
+	})
+}
+
+// writeFile создаёт файл и пишет в него документ, при ошибке недописанный файл удаляется
+func writeFile(filename string, render func(w io.Writer) error) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+
+	err = render(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(filename)
+		return err
+	}
+	return nil
+}
+
+// Exporters - все форматы, доступные на экране отчёта
+var Exporters = []Exporter{
+	{Name: "PDF", Extension: ".pdf", Render: RenderPDF},
+	{Name: "CSV", Extension: ".csv", Render: RenderCSV},
+	{Name: "XLSX", Extension: ".xlsx", Render: RenderXLSX},
+	{Name: "HTML", Extension: ".html", Render: RenderHTML},
+}
+
+// ExporterByName - формат по подписи, например "PDF"
+func ExporterByName(name string) (Exporter, bool) {
+	for _, exporter := range Exporters {
+		if exporter.Name == name {
+			return exporter, true
+		}
+	}
+	return Exporter{}, false
+}
@@ -0,0 +1,42 @@
+package report
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "report.csv")
+	err := writeFile(filename, func(w io.Writer) error {
+		_, err := io.WriteString(w, "a;b\n")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("writeFile: %v", err)
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if string(data) != "a;b\n" {
+		t.Errorf("content = %q, want %q", data, "a;b\n")
+	}
+}
+
+func TestWriteFileRemovesPartial(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "report.pdf")
+	failed := errors.New("render failed")
+	err := writeFile(filename, func(w io.Writer) error {
+		io.WriteString(w, "%PDF-")
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("writeFile error = %v, want %v", err, failed)
+	}
+	if _, err := os.Stat(filename); !os.IsNotExist(err) {
+		t.Errorf("partial file left after render error: %v", err)
+	}
+}
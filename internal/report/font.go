@@ -0,0 +1,42 @@
+package report
+
+import (
+	"log"
+
+	"github.com/go-fonts/liberation/liberationsansbold"
+	"github.com/go-fonts/liberation/liberationsansregular"
+	"github.com/signintech/gopdf"
+)
+
+// семейства шрифтов PDF: обычный и жирный для заголовков
+const (
+	pdfFont     = "Sans"
+	pdfFontBold = "SansBold"
+)
+
+// FontPath - путь к TTF-шрифту для PDF (pdf_font в config.ini).
+// Пусто - встроенный Liberation Sans с кириллицей, он есть на любой ОС
+var FontPath string
+
+// FontBoldPath - жирный шрифт для заголовков таблиц, пусто - встроенный
+var FontBoldPath string
+
+// addFonts подключает шрифты к документу; если указанный в настройках файл
+// не читается, используется встроенный шрифт
+func addFonts(pdf *gopdf.GoPdf) error {
+	if err := addFont(pdf, pdfFont, FontPath, liberationsansregular.TTF); err != nil {
+		return err
+	}
+	return addFont(pdf, pdfFontBold, FontBoldPath, liberationsansbold.TTF)
+}
+
+func addFont(pdf *gopdf.GoPdf, family, path string, bundled []byte) error {
+	if path != "" {
+		err := pdf.AddTTFFont(family, path)
+		if err == nil {
+			return nil
+		}
+		log.Printf("Error while load pdf font %s, using bundled font: %v", path, err)
+	}
+	return pdf.AddTTFFontData(family, bundled)
+}
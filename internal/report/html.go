@@ -0,0 +1,161 @@
+package report
+
+import (
+	"bytes"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgsvg"
+)
+
+// размер графика в HTML
+const (
+	htmlChartWidth  = 7 * vg.Inch
+	htmlChartHeight = 4 * vg.Inch
+)
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: Arial, Helvetica, sans-serif; color: #222; margin: 24px; }
+h1 { font-size: 20px; color: #1f5fa8; margin-bottom: 4px; }
+.generated { color: #777; font-size: 12px; margin-bottom: 16px; }
+table { border-collapse: collapse; margin-bottom: 24px; font-size: 13px; }
+th, td { border: 1px solid #ccc; padding: 4px 10px; }
+th { background: #eaf2fc; text-align: left; }
+td.num { text-align: right; white-space: nowrap; }
+td.neg { color: #c0392b; }
+tr:nth-child(even) td { background: #fafafa; }
+tr.total td { font-weight: bold; background: #f0f0f0; }
+table.params th { background: none; border: none; padding: 2px 10px 2px 0; }
+table.params td { border: none; padding: 2px 0; }
+.chart svg { max-width: 100%; height: auto; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<div class="generated">Сформирован {{.Generated}}</div>
+{{- if .Params}}
+<table class="params">
+{{- range .Params}}
+<tr><th>{{.Label}}</th><td>{{.Value}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+<table>
+<tr>{{range .Headers}}<th>{{.}}</th>{{end}}</tr>
+{{- range .Rows}}
+<tr{{if .Total}} class="total"{{end}}>{{range .Cells}}<td{{if .Class}} class="{{.Class}}"{{end}}>{{.Text}}</td>{{end}}</tr>
+{{- end}}
+</table>
+{{- if .Chart}}
+<div class="chart">{{.Chart}}</div>
+{{- end}}
+</body>
+</html>
+`))
+
+type htmlCell struct {
+	Text  string
+	Class string
+}
+
+type htmlRow struct {
+	Cells []htmlCell
+	Total bool
+}
+
+type htmlParam struct {
+	Label string
+	Value string
+}
+
+type htmlPage struct {
+	Title     string
+	Generated string
+	Params    []htmlParam
+	Headers   []string
+	Rows      []htmlRow
+	Chart     template.HTML
+}
+
+// RenderHTML пишет отчёт одной страницей: параметры и время формирования в шапке,
+// таблица и график в виде встроенного SVG
+func RenderHTML(def *Definition, result *Result, w io.Writer) error {
+	generated := result.GeneratedAt
+	if generated.IsZero() {
+		generated = time.Now()
+	}
+	page := htmlPage{
+		Title:     def.Title,
+		Generated: generated.Format("2006-01-02 15:04"),
+	}
+	if result.GeneratedBy != "" {
+		page.Generated += ", " + result.GeneratedBy
+	}
+
+	for _, param := range result.Params {
+		page.Params = append(page.Params, htmlParam{Label: param.Label, Value: Format(Column{}, param.Value)})
+	}
+	for _, column := range def.Columns {
+		page.Headers = append(page.Headers, column.Header)
+	}
+	for _, row := range result.Rows {
+		page.Rows = append(page.Rows, htmlTableRow(def, row, false))
+	}
+	if result.Total != nil {
+		page.Rows = append(page.Rows, htmlTableRow(def, result.Total, true))
+	}
+
+	chart, err := chartSVG(def, result)
+	if err != nil {
+		return err
+	}
+	page.Chart = chart
+
+	return htmlTemplate.Execute(w, page)
+}
+
+func htmlTableRow(def *Definition, row []any, total bool) htmlRow {
+	cells := make([]htmlCell, len(def.Columns))
+	for i, column := range def.Columns {
+		cells[i].Text = Format(column, row[i])
+		switch column.Kind {
+		case ColMoney, ColPercent, ColCount:
+			cells[i].Class = "num"
+			if Number(row[i]) < 0 {
+				cells[i].Class += " neg"
+			}
+		}
+	}
+	return htmlRow{Cells: cells, Total: total}
+}
+
+// график отчёта в SVG без заголовка XML, чтобы вставить его прямо в страницу.
+// Шрифты не встраиваются: браузер подставляет похожие, а файл остаётся небольшим
+func chartSVG(def *Definition, result *Result) (template.HTML, error) {
+	p, err := Plot(def, result)
+	if err != nil || p == nil {
+		return "", err
+	}
+
+	canvas := vgsvg.New(htmlChartWidth, htmlChartHeight)
+	p.Draw(draw.New(canvas))
+
+	var buf bytes.Buffer
+	if _, err := canvas.WriteTo(&buf); err != nil {
+		return "", err
+	}
+	svg := buf.String()
+	if start := strings.Index(svg, "<svg"); start > 0 {
+		svg = svg[start:]
+	}
+	return template.HTML(svg), nil
+}
@@ -0,0 +1,358 @@
+package report
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/signintech/gopdf"
+	"gonum.org/v1/plot/vg"
+)
+
+// размеры страницы A4 и отступы в пунктах
+const (
+	pageWidth    = 595.28
+	pageHeight   = 841.89
+	pageMargin   = 40
+	footerHeight = 20
+	contentWidth = pageWidth - 2*pageMargin
+
+	rowHeight   = 18
+	cellPadding = 4
+	chartHeight = contentWidth * 0.6
+	chartDPI    = 150 // разрешение графика в PDF
+)
+
+// вид строки таблицы PDF
+type pdfRowKind int
+
+const (
+	pdfRowData pdfRowKind = iota
+	pdfRowSubtotal
+	pdfRowTotal
+)
+
+type pdfRow struct {
+	cells []any
+	kind  pdfRowKind
+}
+
+// pdfLayout раскладывает отчёт по страницам: шапка, таблица с повтором
+// заголовков, итоги, график и номера страниц
+type pdfLayout struct {
+	pdf    *gopdf.GoPdf
+	def    *Definition
+	widths []float64
+	y      float64
+}
+
+func newPDFLayout(pdf *gopdf.GoPdf, def *Definition) *pdfLayout {
+	return &pdfLayout{pdf: pdf, def: def, widths: columnWidths(def), y: pageMargin}
+}
+
+// ширины колонок; если таблица шире страницы - сжимаются пропорционально
+func columnWidths(def *Definition) []float64 {
+	widths := make([]float64, len(def.Columns))
+	var total float64
+	for i, column := range def.Columns {
+		widths[i] = column.Width
+		if widths[i] == 0 {
+			widths[i] = defaultColumnWidth
+		}
+		total += widths[i]
+	}
+	if total > contentWidth {
+		for i := range widths {
+			widths[i] *= contentWidth / total
+		}
+	}
+	return widths
+}
+
+// переход на новую страницу, если по высоте не помещается h
+func (l *pdfLayout) ensure(h float64) bool {
+	if l.y+h <= pageHeight-pageMargin-footerHeight {
+		return false
+	}
+	l.pdf.AddPage()
+	l.y = pageMargin
+	return true
+}
+
+func (l *pdfLayout) font(bold bool, size float64) error {
+	if bold {
+		return l.pdf.SetFont(pdfFontBold, "", size)
+	}
+	return l.pdf.SetFont(pdfFont, "", size)
+}
+
+// шапка: название, параметры, кто и когда сформировал
+func (l *pdfLayout) titleBlock(result *Result) error {
+	l.pdf.SetTextColor(0, 0, 0)
+	if err := l.font(true, 16); err != nil {
+		return err
+	}
+	if err := l.lines(l.def.Title, 22); err != nil {
+		return err
+	}
+
+	if err := l.font(false, 10); err != nil {
+		return err
+	}
+	for _, param := range result.Params {
+		if err := l.lines(param.Label+": "+Format(Column{}, param.Value), 14); err != nil {
+			return err
+		}
+	}
+
+	generatedAt := result.GeneratedAt
+	if generatedAt.IsZero() {
+		generatedAt = time.Now()
+	}
+	generated := "Сформирован: " + generatedAt.Format("2006-01-02 15:04")
+	if result.GeneratedBy != "" {
+		generated += ", " + result.GeneratedBy
+	}
+	l.pdf.SetTextColor(110, 110, 110)
+	if err := l.lines(generated, 14); err != nil {
+		return err
+	}
+	l.pdf.SetTextColor(0, 0, 0)
+
+	l.y += 10
+	return nil
+}
+
+// текст с переносом по ширине страницы
+func (l *pdfLayout) lines(text string, height float64) error {
+	lines, err := l.pdf.SplitTextWithWordWrap(text, contentWidth)
+	if err != nil {
+		lines = []string{text}
+	}
+	for _, line := range lines {
+		l.ensure(height)
+		l.pdf.SetXY(pageMargin, l.y)
+		if err := l.pdf.CellWithOption(&gopdf.Rect{W: contentWidth, H: height}, line, gopdf.CellOption{Align: gopdf.Left | gopdf.Middle}); err != nil {
+			return err
+		}
+		l.y += height
+	}
+	return nil
+}
+
+func (l *pdfLayout) header() error {
+	cells := make([]string, len(l.def.Columns))
+	for i, column := range l.def.Columns {
+		cells[i] = column.Header
+	}
+	return l.drawRow(cells, true, [3]uint8{234, 242, 252}, false)
+}
+
+// таблица: заголовки повторяются на каждой странице
+func (l *pdfLayout) table(rows []pdfRow) error {
+	if err := l.header(); err != nil {
+		return err
+	}
+	for i, row := range rows {
+		if l.ensure(rowHeight) {
+			if err := l.header(); err != nil {
+				return err
+			}
+		}
+
+		cells := make([]string, len(l.def.Columns))
+		for c, column := range l.def.Columns {
+			if c < len(row.cells) && (row.kind == pdfRowData || row.cells[c] != nil) {
+				cells[c] = Format(column, row.cells[c])
+			}
+		}
+
+		var err error
+		switch row.kind {
+		case pdfRowSubtotal:
+			err = l.drawRow(cells, true, [3]uint8{242, 242, 242}, true)
+		case pdfRowTotal:
+			err = l.drawRow(cells, true, [3]uint8{225, 225, 225}, true)
+		default:
+			fill := [3]uint8{255, 255, 255}
+			if i%2 == 1 {
+				fill = [3]uint8{250, 250, 250}
+			}
+			err = l.drawRow(cells, false, fill, true)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// строка таблицы; числа выравниваются вправо, длинный текст обрезается
+func (l *pdfLayout) drawRow(cells []string, bold bool, fill [3]uint8, alignNumbers bool) error {
+	if err := l.font(bold, 9); err != nil {
+		return err
+	}
+	l.pdf.SetStrokeColor(190, 190, 190)
+	l.pdf.SetLineWidth(0.5)
+	l.pdf.SetFillColor(fill[0], fill[1], fill[2])
+
+	x := float64(pageMargin)
+	for i, text := range cells {
+		width := l.widths[i]
+		l.pdf.RectFromUpperLeftWithStyle(x, l.y, width, rowHeight, "FD")
+
+		align := gopdf.Left | gopdf.Middle
+		if alignNumbers && isNumeric(l.def.Columns[i].Kind) {
+			align = gopdf.Right | gopdf.Middle
+		}
+		l.pdf.SetXY(x+cellPadding, l.y)
+		err := l.pdf.CellWithOption(&gopdf.Rect{W: width - 2*cellPadding, H: rowHeight}, l.fit(text, width-2*cellPadding), gopdf.CellOption{Align: align})
+		if err != nil {
+			return err
+		}
+		x += width
+	}
+	l.y += rowHeight
+	return nil
+}
+
+// текст, обрезанный по ширине ячейки
+func (l *pdfLayout) fit(text string, width float64) string {
+	if w, err := l.pdf.MeasureTextWidth(text); err != nil || w <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		if w, err := l.pdf.MeasureTextWidth(string(runes) + "…"); err == nil && w <= width {
+			break
+		}
+	}
+	return string(runes) + "…"
+}
+
+// график по ширине страницы сразу после таблицы, если не помещается - на следующей
+func (l *pdfLayout) chart(result *Result) error {
+	p, err := Plot(l.def, result)
+	if err != nil {
+		return fmt.Errorf("ошибка создания графика: %v", err)
+	}
+	if p == nil {
+		return nil
+	}
+
+	img := PlotImage(p, vg.Length(contentWidth), vg.Length(chartHeight), chartDPI)
+
+	l.y += 10
+	l.ensure(chartHeight)
+	if err := l.pdf.ImageFrom(img, pageMargin, l.y, &gopdf.Rect{W: contentWidth, H: chartHeight}); err != nil {
+		return err
+	}
+	l.y += chartHeight
+	return nil
+}
+
+// подвал каждой страницы: название отчёта и "Страница x из y"
+func (l *pdfLayout) footers() error {
+	pages := l.pdf.GetNumberOfPages()
+	for page := 1; page <= pages; page++ {
+		if err := l.pdf.SetPage(page); err != nil {
+			return err
+		}
+		if err := l.font(false, 8); err != nil {
+			return err
+		}
+		l.pdf.SetTextColor(110, 110, 110)
+
+		y := pageHeight - pageMargin - footerHeight/2
+		l.pdf.SetStrokeColor(190, 190, 190)
+		l.pdf.SetLineWidth(0.5)
+		l.pdf.Line(pageMargin, y, pageWidth-pageMargin, y)
+
+		rect := &gopdf.Rect{W: contentWidth, H: footerHeight}
+		l.pdf.SetXY(pageMargin, y)
+		if err := l.pdf.CellWithOption(rect, l.fit(l.def.Title, contentWidth/2), gopdf.CellOption{Align: gopdf.Left | gopdf.Middle}); err != nil {
+			return err
+		}
+		l.pdf.SetXY(pageMargin, y)
+		if err := l.pdf.CellWithOption(rect, fmt.Sprintf("Страница %d из %d", page, pages), gopdf.CellOption{Align: gopdf.Right | gopdf.Middle}); err != nil {
+			return err
+		}
+	}
+	l.pdf.SetTextColor(0, 0, 0)
+	return nil
+}
+
+// строки таблицы PDF с подытогами по первой колонке и итоговой строкой
+func pdfRows(def *Definition, result *Result) []pdfRow {
+	var rows []pdfRow
+	grouped := hasSubtotals(def)
+
+	var group [][]any
+	flush := func() {
+		if grouped && len(group) > 1 {
+			subtotal := sumRows(def, group)
+			subtotal[0] = "Итого: " + Format(def.Columns[0], group[0][0])
+			rows = append(rows, pdfRow{cells: subtotal, kind: pdfRowSubtotal})
+		}
+		group = nil
+	}
+	for _, row := range result.Rows {
+		if grouped && len(group) > 0 && Format(def.Columns[0], group[0][0]) != Format(def.Columns[0], row[0]) {
+			flush()
+		}
+		group = append(group, row)
+		rows = append(rows, pdfRow{cells: row, kind: pdfRowData})
+	}
+	flush()
+
+	switch {
+	case result.Total != nil:
+		rows = append(rows, pdfRow{cells: result.Total, kind: pdfRowTotal})
+	case hasSums(def) && len(result.Rows) > 0:
+		total := sumRows(def, result.Rows)
+		if !def.Columns[0].Sum {
+			total[0] = "Итого"
+		}
+		rows = append(rows, pdfRow{cells: total, kind: pdfRowTotal})
+	}
+	return rows
+}
+
+// подытоги нужны, когда строки сгруппированы по двум и более текстовым колонкам или датам
+func hasSubtotals(def *Definition) bool {
+	return len(def.Columns) > 2 && def.Columns[0].Kind == ColText &&
+		!isNumeric(def.Columns[1].Kind) && hasSums(def)
+}
+
+func hasSums(def *Definition) bool {
+	for _, column := range def.Columns {
+		if column.Sum {
+			return true
+		}
+	}
+	return false
+}
+
+// суммы колонок с Sum, остальные ячейки пустые
+func sumRows(def *Definition, rows [][]any) []any {
+	sums := make([]any, len(def.Columns))
+	for i, column := range def.Columns {
+		if !column.Sum {
+			continue
+		}
+		var sum float64
+		for _, row := range rows {
+			sum += Number(row[i])
+		}
+		if column.Kind == ColCount {
+			sums[i] = int64(sum)
+		} else {
+			sums[i] = sum
+		}
+	}
+	return sums
+}
+
+func isNumeric(kind ColumnKind) bool {
+	return kind == ColMoney || kind == ColPercent || kind == ColCount
+}
@@ -0,0 +1,65 @@
+package report
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/signintech/gopdf"
+)
+
+// ширина колонки в PDF, если не задана в описании
+const defaultColumnWidth = 100
+
+func NewPDF() (*gopdf.GoPdf, error) {
+	pdf := &gopdf.GoPdf{}
+	pdf.Start(gopdf.Config{PageSize: *gopdf.PageSizeA4}) // Размер страницы A4
+	pdf.AddPage()
+
+	err := addFonts(pdf)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка добавления шрифта: %v", err)
+	}
+
+	err = pdf.SetFont(pdfFont, "", 12)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка установки шрифта: %v", err)
+	}
+
+	return pdf, nil
+}
+
+// RenderPDF пишет отчёт: шапка с параметрами, таблица по страницам
+// с итогами, график после таблицы и номера страниц
+func RenderPDF(def *Definition, result *Result, w io.Writer) error {
+	pdf, err := NewPDF()
+	if err != nil {
+		return err
+	}
+
+	layout := newPDFLayout(pdf, def)
+	if err := layout.titleBlock(result); err != nil {
+		return err
+	}
+	if err := layout.table(pdfRows(def, result)); err != nil {
+		return err
+	}
+	if err := layout.chart(result); err != nil {
+		return err
+	}
+	if err := layout.footers(); err != nil {
+		return err
+	}
+
+	if err := pdf.Write(w); err != nil {
+		return fmt.Errorf("ошибка сохранения PDF: %v", err)
+	}
+	return nil
+}
+
+func formatRow(def *Definition, row []any) []string {
+	cells := make([]string, len(def.Columns))
+	for i, column := range def.Columns {
+		cells[i] = Format(column, row[i])
+	}
+	return cells
+}
@@ -0,0 +1,110 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// значение ячейки по типу колонки; текст первой колонки повторяется, чтобы были подытоги
+func sampleValue(column Column, col, row int) any {
+	day := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, row)
+	switch column.Kind {
+	case ColDate:
+		return day
+	case ColMonth:
+		return time.Date(2024, time.Month(row%12+1), 1, 0, 0, 0, 0, time.UTC)
+	case ColMoney:
+		return float64(row*100+col) - 250.5
+	case ColPercent:
+		if row%7 == 3 {
+			return (*float64)(nil)
+		}
+		percent := float64(row%10) * 10
+		return &percent
+	case ColCount:
+		return int64(row + col)
+	default:
+		if col == 0 {
+			return fmt.Sprintf("Статья с длинным названием для обрезки в ячейке %d", row/4)
+		}
+		return fmt.Sprintf("Значение %d", row)
+	}
+}
+
+// результат на несколько страниц с параметрами в шапке
+func sampleResult(def *Definition, rows int) *Result {
+	result := &Result{
+		Params: []ParamValue{
+			{Label: "Период (начало)", Value: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)},
+			{Label: "Статьи", Value: "все"},
+		},
+		GeneratedBy: "тест",
+		GeneratedAt: time.Date(2024, time.November, 1, 12, 0, 0, 0, time.UTC),
+	}
+	for r := 0; r < rows; r++ {
+		row := make([]any, len(def.Columns))
+		for c, column := range def.Columns {
+			row[c] = sampleValue(column, c, r)
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	return result
+}
+
+// каждый отчёт выгружается каждым форматом во временный файл
+func renderAll(t *testing.T, result func(def *Definition) *Result) {
+	t.Helper()
+	dir := t.TempDir()
+	for i, def := range chartDefinitions() {
+		for _, exporter := range Exporters {
+			filename := filepath.Join(dir, fmt.Sprintf("%d_%s%s", i, def.ID, exporter.Extension))
+			if err := exporter.Write(def, result(def), filename); err != nil {
+				t.Errorf("%s to %s: %v", def.Name, exporter.Name, err)
+				continue
+			}
+			data, err := os.ReadFile(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(data) == 0 {
+				t.Errorf("%s to %s: empty file", def.Name, exporter.Name)
+			}
+			if exporter.Extension == ".pdf" && !bytes.HasPrefix(data, []byte("%PDF-")) {
+				t.Errorf("%s to %s: not a PDF", def.Name, exporter.Name)
+			}
+		}
+	}
+}
+
+func TestRenderPopulated(t *testing.T) {
+	renderAll(t, func(def *Definition) *Result { return sampleResult(def, 90) })
+}
+
+func TestRenderEmpty(t *testing.T) {
+	renderAll(t, func(def *Definition) *Result { return &Result{} })
+}
+
+// шрифт из настроек не читается - PDF строится встроенным шрифтом
+func TestRenderPDFFontFallback(t *testing.T) {
+	defer func(path, bold string) { FontPath, FontBoldPath = path, bold }(FontPath, FontBoldPath)
+	FontPath = filepath.Join(t.TempDir(), "missing.ttf")
+	FontBoldPath = filepath.Join(t.TempDir(), "broken.ttf")
+	if err := os.WriteFile(FontBoldPath, []byte("not a font"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, def := range All() {
+		var buf bytes.Buffer
+		if err := RenderPDF(def, sampleResult(def, 10), &buf); err != nil {
+			t.Errorf("%s: %v", def.Name, err)
+			continue
+		}
+		if !bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")) {
+			t.Errorf("%s: not a PDF", def.Name)
+		}
+	}
+}
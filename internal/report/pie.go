@@ -0,0 +1,167 @@
+package report
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/text"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// доля по умолчанию (в процентах), ниже которой статьи объединяются в "прочее"
+const defaultOtherShare = 3
+
+const otherLabel = "Прочее"
+
+// DonutHole - радиус пустого центра кольцевой диаграммы относительно внешнего
+const DonutHole = 0.55
+
+// PieSlice - сектор круговой диаграммы. Start и End - углы в радианах,
+// отсчитанные от верхней точки по часовой стрелке
+type PieSlice struct {
+	Label string
+	Value float64
+	Share float64 // доля в процентах
+	Color color.Color
+	Start float64
+	End   float64
+}
+
+// PieSlices делит круг по положительным значениям; значения с долей меньше
+// otherShare процентов объединяются в один сектор "Прочее"
+func PieSlices(labels []string, values []float64, otherShare float64) []PieSlice {
+	var total float64
+	for _, value := range values {
+		if value > 0 {
+			total += value
+		}
+	}
+	if total == 0 {
+		return nil
+	}
+
+	var slices []PieSlice
+	var other float64
+	for i, value := range values {
+		if value <= 0 {
+			continue
+		}
+		share := value * 100 / total
+		if share < otherShare {
+			other += value
+			continue
+		}
+		slices = append(slices, PieSlice{Label: labels[i], Value: value, Share: share})
+	}
+	if other > 0 {
+		slices = append(slices, PieSlice{Label: otherLabel, Value: other, Share: other * 100 / total})
+	}
+
+	var angle float64
+	for i := range slices {
+		slices[i].Color = palette[i%len(palette)]
+		slices[i].Start = angle
+		angle += 2 * math.Pi * slices[i].Value / total
+		slices[i].End = angle
+	}
+	return slices
+}
+
+// PieGeometry - центр и радиус диаграммы в области данных; справа остаётся место для легенды
+func PieGeometry(area draw.Canvas) (vg.Point, vg.Length) {
+	size := area.Size()
+	radius := vg.Length(math.Min(float64(size.X)*0.3, float64(size.Y)/2)) * 0.95
+	center := vg.Point{X: area.Min.X + size.X*0.35, Y: area.Min.Y + size.Y/2}
+	return center, radius
+}
+
+// pieChart рисует сектора диаграммы, для кольцевой - с пустым центром
+type pieChart struct {
+	slices []PieSlice
+	donut  bool
+	labels draw.TextStyle
+}
+
+func (pc *pieChart) Plot(c draw.Canvas, p *plot.Plot) {
+	center, radius := PieGeometry(c)
+
+	for _, slice := range pc.slices {
+		var path vg.Path
+		path.Move(center)
+		// в vg углы отсчитываются от оси X против часовой стрелки
+		path.Arc(center, radius, math.Pi/2-slice.Start, -(slice.End - slice.Start))
+		path.Close()
+		c.SetColor(slice.Color)
+		c.Fill(path)
+	}
+
+	inner := vg.Length(0)
+	if pc.donut {
+		inner = radius * DonutHole
+		var hole vg.Path
+		hole.Move(vg.Point{X: center.X + inner, Y: center.Y})
+		hole.Arc(center, inner, 0, 2*math.Pi)
+		hole.Close()
+		c.SetColor(color.White)
+		c.Fill(hole)
+	}
+
+	// подписи долей посередине сектора
+	for _, slice := range pc.slices {
+		mid := math.Pi/2 - (slice.Start+slice.End)/2
+		r := (radius + inner) / 2
+		if !pc.donut {
+			r = radius * 0.65
+		}
+		pt := vg.Point{X: center.X + r*vg.Length(math.Cos(mid)), Y: center.Y + r*vg.Length(math.Sin(mid))}
+		c.FillText(pc.labels, pt, fmt.Sprintf("%.1f%%", slice.Share))
+	}
+}
+
+// значок сектора в легенде
+type pieThumbnail struct {
+	color color.Color
+}
+
+func (t pieThumbnail) Thumbnail(c *draw.Canvas) {
+	c.FillPolygon(t.color, []vg.Point{
+		{X: c.Min.X, Y: c.Min.Y},
+		{X: c.Min.X, Y: c.Max.Y},
+		{X: c.Max.X, Y: c.Max.Y},
+		{X: c.Max.X, Y: c.Min.Y},
+	})
+}
+
+// ChartSlices - сектора диаграммы отчёта по первой серии
+func ChartSlices(def *Definition, result *Result) []PieSlice {
+	spec := def.Chart
+	if spec == nil || len(spec.Series) == 0 {
+		return nil
+	}
+	values := make([]float64, len(result.Rows))
+	for i, row := range result.Rows {
+		values[i] = Number(row[spec.Series[0].Column])
+	}
+
+	otherShare := spec.OtherShare
+	if otherShare == 0 {
+		otherShare = defaultOtherShare
+	}
+	return PieSlices(Labels(def, result), values, otherShare)
+}
+
+func addPie(p *plot.Plot, slices []PieSlice, donut bool) {
+	labels := p.Legend.TextStyle
+	labels.XAlign = text.XCenter
+	labels.YAlign = text.YCenter
+
+	p.HideAxes()
+	p.Add(&pieChart{slices: slices, donut: donut, labels: labels})
+	for _, slice := range slices {
+		p.Legend.Add(fmt.Sprintf("%s (%.1f%%)", slice.Label, slice.Share), pieThumbnail{color: slice.Color})
+	}
+	p.Legend.Top = true
+}
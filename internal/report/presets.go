@@ -0,0 +1,95 @@
+package report
+
+import (
+	"time"
+
+	"github.com/EmptyInsid/db_gui/internal/database"
+)
+
+// относительные периоды: считаются от текущей даты при построении отчёта
+const (
+	PresetCurrentMonth    = "current_month"
+	PresetPreviousMonth   = "previous_month"
+	PresetQuarterToDate   = "quarter_to_date"
+	PresetPreviousQuarter = "previous_quarter"
+	PresetYearToDate      = "year_to_date"
+	PresetPreviousYear    = "previous_year"
+	PresetLast12Months    = "last_12_months"
+)
+
+// Presets - относительные периоды в порядке показа, Value - ключ периода
+var Presets = []Option{
+	{Label: "Текущий месяц", Value: PresetCurrentMonth},
+	{Label: "Прошлый месяц", Value: PresetPreviousMonth},
+	{Label: "С начала квартала", Value: PresetQuarterToDate},
+	{Label: "Прошлый квартал", Value: PresetPreviousQuarter},
+	{Label: "С начала года", Value: PresetYearToDate},
+	{Label: "Прошлый год", Value: PresetPreviousYear},
+	{Label: "Последние 12 месяцев", Value: PresetLast12Months},
+}
+
+// PresetPeriod возвращает начало и конец относительного периода на дату today
+func PresetPeriod(preset string, today time.Time) (time.Time, time.Time, error) {
+	switch preset {
+	case PresetCurrentMonth:
+		start, end := database.PeriodBounds(database.PeriodMonth, today)
+		return start, end, nil
+	case PresetPreviousMonth:
+		start, _ := database.PeriodBounds(database.PeriodMonth, today)
+		start, end := database.PeriodBounds(database.PeriodMonth, start.AddDate(0, 0, -1))
+		return start, end, nil
+	case PresetQuarterToDate:
+		start, _ := database.PeriodBounds(database.PeriodQuarter, today)
+		_, end := database.PeriodBounds(database.PeriodCustom, today)
+		return start, end, nil
+	case PresetPreviousQuarter:
+		start, _ := database.PeriodBounds(database.PeriodQuarter, today)
+		start, end := database.PeriodBounds(database.PeriodQuarter, start.AddDate(0, 0, -1))
+		return start, end, nil
+	case PresetYearToDate:
+		start, _ := database.PeriodBounds(database.PeriodYear, today)
+		_, end := database.PeriodBounds(database.PeriodCustom, today)
+		return start, end, nil
+	case PresetPreviousYear:
+		start, _ := database.PeriodBounds(database.PeriodYear, today)
+		start, end := database.PeriodBounds(database.PeriodYear, start.AddDate(0, 0, -1))
+		return start, end, nil
+	case PresetLast12Months:
+		start, end := database.PeriodBounds(database.PeriodMonth, today)
+		return start.AddDate(0, -11, 0), end, nil
+	}
+	return time.Time{}, time.Time{}, ErrUnknownPreset
+}
+
+// PresetValues подставляет относительный период во все периоды отчёта.
+// Период с ShiftFrom берётся тем же отрезком годом раньше, остальные параметры копируются
+func (d *Definition) PresetValues(preset string, values Values, today time.Time) (Values, error) {
+	start, end, err := PresetPeriod(preset, today)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(Values, len(values))
+	for key, value := range values {
+		result[key] = value
+	}
+	for _, param := range d.Params {
+		if param.Kind != ParamPeriod {
+			continue
+		}
+		period := []string{start.Format("2006-01-02"), end.Format("2006-01-02")}
+		if from, ok := result[param.ShiftFrom]; ok && param.ShiftFrom != "" && len(from) == 2 {
+			shiftedStart, err := ShiftDate(from[0], -1, 0)
+			if err != nil {
+				return nil, err
+			}
+			shiftedEnd, err := ShiftDate(from[1], -1, 0)
+			if err != nil {
+				return nil, err
+			}
+			period = []string{shiftedStart, shiftedEnd}
+		}
+		result[param.Key] = period
+	}
+	return result, nil
+}
@@ -0,0 +1,44 @@
+package report
+
+import "sync"
+
+var (
+	registryMu sync.RWMutex
+	registry   []*Definition
+)
+
+// Register добавляет отчёт в реестр, повторный ID заменяет прежнее описание
+func Register(def *Definition) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	for i, existing := range registry {
+		if existing.ID == def.ID {
+			registry[i] = def
+			return
+		}
+	}
+	registry = append(registry, def)
+}
+
+// All - отчёты в порядке регистрации
+func All() []*Definition {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	defs := make([]*Definition, len(registry))
+	copy(defs, registry)
+	return defs
+}
+
+func Get(id string) (*Definition, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	for _, def := range registry {
+		if def.ID == id {
+			return def, nil
+		}
+	}
+	return nil, ErrUnknownReport
+}
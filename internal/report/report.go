@@ -0,0 +1,293 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EmptyInsid/db_gui/internal/database"
+)
+
+// ParamKind - вид параметра отчёта, по нему строится поле формы
+type ParamKind int
+
+const (
+	ParamPeriod   ParamKind = iota // две даты: начало и конец
+	ParamArticles                  // список статей
+	ParamChoice                    // выбор одного значения из Options
+	ParamNumber                    // целое положительное число
+)
+
+// Option - значение параметра выбора и его подпись в форме
+type Option struct {
+	Label string
+	Value string
+}
+
+// Param описывает один параметр отчёта
+type Param struct {
+	Key     string
+	Label   string
+	Kind    ParamKind
+	Options []Option // для ParamChoice, первое значение - по умолчанию
+	Default string   // для ParamNumber
+	// для ParamPeriod: ключ периода, от которого отсчитываются кнопки "год назад" и "месяц назад"
+	ShiftFrom string
+}
+
+// ColumnKind задаёт тип значений колонки и их формат
+type ColumnKind int
+
+const (
+	ColText    ColumnKind = iota // string
+	ColDate                      // time.Time, день
+	ColMonth                     // time.Time, месяц
+	ColMoney                     // float64
+	ColPercent                   // float64 или *float64, nil - не определено
+	ColCount                     // int64
+)
+
+// Column - колонка таблицы отчёта
+type Column struct {
+	Header string
+	Kind   ColumnKind
+	Signed bool    // показывать знак у положительных чисел
+	Width  float64 // ширина в PDF, 0 - по умолчанию
+	Sum    bool    // суммируется в итогах и подытогах PDF
+}
+
+// ChartKind - вид графика отчёта
+type ChartKind int
+
+const (
+	ChartLine ChartKind = iota
+	ChartBar
+	ChartStackedArea
+	ChartPie   // круговая по первой серии
+	ChartDonut // кольцевая по первой серии
+)
+
+// Series - линия или столбцы графика по колонке таблицы
+type Series struct {
+	Name   string
+	Column int
+}
+
+// Band - закрашенная область между двумя колонками, например доверительный интервал
+type Band struct {
+	Name string
+	Low  int
+	High int
+}
+
+// ChartSpec описывает график по строкам таблицы отчёта.
+// Подписи оси X берутся из колонки Category
+type ChartSpec struct {
+	Kind     ChartKind
+	Title    string
+	XLabel   string
+	YLabel   string
+	Category int
+	Series   []Series
+	Band     *Band
+	// для круговой: доля в процентах, ниже которой статьи идут в "прочее", 0 - по умолчанию
+	OtherShare float64
+}
+
+// Result - строки отчёта; значения ячеек имеют типы, заданные колонками
+type Result struct {
+	Rows   [][]any
+	Total  []any        // итоговая строка, nil - без итога
+	Params []ParamValue // параметры, с которыми построен отчёт
+
+	GeneratedBy string // кто и когда сформировал отчёт
+	GeneratedAt time.Time
+}
+
+// ParamValue - подпись и значение параметра для выгрузок; даты хранятся как time.Time
+type ParamValue struct {
+	Label string
+	Value any
+}
+
+// Definition - описание отчёта: параметры, источник данных, колонки и график.
+// Форма, предпросмотр и выгрузки строятся по описанию
+type Definition struct {
+	ID      string
+	Name    string // название в меню
+	Title   string // заголовок формы и документа
+	Params  []Param
+	Columns []Column
+	Chart   *ChartSpec // nil - отчёт без графика
+	Load    func(ctx context.Context, db database.Service, values Values) (*Result, error)
+}
+
+// Values - введённые значения параметров по ключу.
+// Период хранится как [начало, конец], статьи - списком
+type Values map[string][]string
+
+func (v Values) Get(key string) string {
+	if len(v[key]) == 0 {
+		return ""
+	}
+	return v[key][0]
+}
+
+func (v Values) Period(key string) (string, string) {
+	if len(v[key]) < 2 {
+		return "", ""
+	}
+	return v[key][0], v[key][1]
+}
+
+// Articles - выбранные статьи без пустых полей, пустой список означает все статьи
+func (v Values) Articles(key string) []string {
+	var articles []string
+	for _, article := range v[key] {
+		if article = strings.TrimSpace(article); article != "" {
+			articles = append(articles, article)
+		}
+	}
+	return articles
+}
+
+func (v Values) Int(key string) (int, error) {
+	n, err := strconv.Atoi(v.Get(key))
+	if err != nil || n <= 0 {
+		return 0, ErrInvalidNumber
+	}
+	return n, nil
+}
+
+// Validate проверяет даты периодов и числа
+func (d *Definition) Validate(values Values) error {
+	for _, param := range d.Params {
+		switch param.Kind {
+		case ParamPeriod:
+			start, end := values.Period(param.Key)
+			startDate, err := time.Parse("2006-01-02", start)
+			if err != nil {
+				return ErrInvalidDate
+			}
+			endDate, err := time.Parse("2006-01-02", end)
+			if err != nil {
+				return ErrInvalidDate
+			}
+			if startDate.After(endDate) {
+				return ErrEndBeforeStart
+			}
+		case ParamNumber:
+			if _, err := values.Int(param.Key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Run проверяет параметры и загружает данные отчёта
+func (d *Definition) Run(ctx context.Context, db database.Service, values Values) (*Result, error) {
+	if err := d.Validate(values); err != nil {
+		return nil, err
+	}
+	result, err := d.Load(ctx, db, values)
+	if err != nil {
+		return nil, err
+	}
+	if result.Params == nil {
+		result.Params = d.ParamValues(values)
+	}
+	result.GeneratedBy = db.CurrentUser()
+	result.GeneratedAt = time.Now()
+	return result, nil
+}
+
+// ParamValues - введённые параметры в виде подписей и значений, период - двумя строками
+func (d *Definition) ParamValues(values Values) []ParamValue {
+	var params []ParamValue
+	for _, param := range d.Params {
+		switch param.Kind {
+		case ParamPeriod:
+			start, end := values.Period(param.Key)
+			params = append(params,
+				ParamValue{Label: param.Label + " (начало)", Value: paramDate(start)},
+				ParamValue{Label: param.Label + " (конец)", Value: paramDate(end)},
+			)
+		case ParamArticles:
+			articles := values.Articles(param.Key)
+			text := "все"
+			if len(articles) > 0 {
+				text = strings.Join(articles, ", ")
+			}
+			params = append(params, ParamValue{Label: param.Label, Value: text})
+		case ParamChoice:
+			text := values.Get(param.Key)
+			for _, option := range param.Options {
+				if option.Value == text {
+					text = option.Label
+				}
+			}
+			params = append(params, ParamValue{Label: param.Label, Value: text})
+		case ParamNumber:
+			var value any = values.Get(param.Key)
+			if n, err := values.Int(param.Key); err == nil {
+				value = int64(n)
+			}
+			params = append(params, ParamValue{Label: param.Label, Value: value})
+		}
+	}
+	return params
+}
+
+// дата параметра, нераспознанная остаётся строкой
+func paramDate(text string) any {
+	date, err := time.Parse("2006-01-02", text)
+	if err != nil {
+		return text
+	}
+	return date
+}
+
+// Format - текст ячейки по типу колонки
+func Format(column Column, value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "-"
+	case string:
+		return v
+	case time.Time:
+		if column.Kind == ColMonth {
+			return v.Format("2006-01")
+		}
+		return v.Format("2006-01-02")
+	case *float64:
+		if v == nil {
+			return "-"
+		}
+		return Format(column, *v)
+	case float64:
+		if column.Signed {
+			return fmt.Sprintf("%+.2f", v)
+		}
+		return fmt.Sprintf("%.2f", v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Number - числовое значение ячейки для графика
+func Number(value any) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case *float64:
+		if v != nil {
+			return *v
+		}
+	case int64:
+		return float64(v)
+	}
+	return 0
+}
@@ -0,0 +1,54 @@
+package report
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/EmptyInsid/db_gui/internal/database"
+)
+
+// сервис, запоминающий статьи, переданные в запрос
+type articlesService struct {
+	database.Service
+	articles []string
+}
+
+func (s *articlesService) GetIncomeExpenseDynamics(ctx context.Context, articles []string, startDate, endDate string) ([]database.DateTotalMoney, error) {
+	s.articles = articles
+	return nil, nil
+}
+
+func (s *articlesService) CurrentUser() string { return "user" }
+
+// невыбранные поля статей не попадают в запрос, а пустой выбор подписан как все статьи
+func TestArticlesWithoutSelection(t *testing.T) {
+	tests := []struct {
+		name     string
+		selected []string
+		want     []string
+		label    string
+	}{
+		{"ничего не выбрано", []string{""}, nil, "все"},
+		{"пустые поля между статьями", []string{"Еда", " ", "Транспорт", ""}, []string{"Еда", "Транспорт"}, "Еда, Транспорт"},
+	}
+	for _, tt := range tests {
+		db := &articlesService{}
+		values := Values{"period": {"2024-01-01", "2024-01-31"}, "articles": tt.selected}
+
+		result, err := IncomeExpenseDynamics.Run(context.Background(), db, values)
+		if err != nil {
+			t.Fatalf("%s: Run: %v", tt.name, err)
+		}
+		if !slices.Equal(db.articles, tt.want) {
+			t.Errorf("%s: articles = %q, want %q", tt.name, db.articles, tt.want)
+		}
+		i := slices.IndexFunc(result.Params, func(param ParamValue) bool { return param.Label == "Статьи" })
+		if i < 0 {
+			t.Fatalf("%s: no articles parameter in %v", tt.name, result.Params)
+		}
+		if result.Params[i].Value != tt.label {
+			t.Errorf("%s: label = %v, want %q", tt.name, result.Params[i].Value, tt.label)
+		}
+	}
+}
@@ -0,0 +1,340 @@
+package report
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Sheet - лист книги XLSX: колонки задают заголовки и формат ячеек
+type Sheet struct {
+	Name    string
+	Columns []Column
+	Rows    [][]any
+	Total   []any // итоговая строка, nil - без итога
+}
+
+// номера стилей ячеек в styles.xml
+const (
+	xlsxStyleDefault = iota
+	xlsxStyleHeader
+	xlsxStyleDate
+	xlsxStyleMonth
+	xlsxStyleMoney
+	xlsxStyleSignedMoney
+	xlsxStylePercent
+	xlsxStyleSignedPercent
+	xlsxStyleCount
+	xlsxStyleTotal
+)
+
+// ширина колонки в символах
+const (
+	xlsxMinWidth = 8
+	xlsxMaxWidth = 60
+)
+
+// начало отсчёта дат Excel
+var xlsxEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// RenderXLSX пишет таблицу отчёта на первый лист, параметры - на лист "Параметры"
+func RenderXLSX(def *Definition, result *Result, w io.Writer) error {
+	sheets := []Sheet{{Name: def.Name, Columns: def.Columns, Rows: result.Rows, Total: result.Total}}
+
+	params := Sheet{
+		Name:    "Параметры",
+		Columns: []Column{{Header: "Параметр", Kind: ColText}, {Header: "Значение", Kind: ColText}},
+		Rows:    [][]any{{"Отчёт", def.Title}},
+	}
+	for _, param := range result.Params {
+		params.Rows = append(params.Rows, []any{param.Label, param.Value})
+	}
+	sheets = append(sheets, params)
+
+	return RenderWorkbook(w, sheets...)
+}
+
+// WriteWorkbook сохраняет листы в файл XLSX
+func WriteWorkbook(filename string, sheets ...Sheet) error {
+	return writeFile(filename, func(w io.Writer) error {
+		return RenderWorkbook(w, sheets...)
+	})
+}
+
+// RenderWorkbook пишет листы книгой XLSX: числа и даты - типизированные ячейки
+// с форматом по колонке, строка заголовков закреплена
+func RenderWorkbook(w io.Writer, sheets ...Sheet) error {
+	archive := zip.NewWriter(w)
+	parts := []struct {
+		name, content string
+	}{
+		{"[Content_Types].xml", xlsxContentTypes(len(sheets))},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbook(sheets)},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels(len(sheets))},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for i, sheet := range sheets {
+		parts = append(parts, struct{ name, content string }{
+			fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), xlsxSheet(sheet),
+		})
+	}
+
+	for _, part := range parts {
+		writer, err := archive.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := writer.Write([]byte(part.content)); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+const xlsxRootRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+// форматы: 164 - день, 165 - месяц, 166 - сумма со знаком, 167 - проценты, 168 - проценты со знаком
+const xlsxStyles = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="5">` +
+	`<numFmt numFmtId="164" formatCode="yyyy\-mm\-dd"/>` +
+	`<numFmt numFmtId="165" formatCode="yyyy\-mm"/>` +
+	`<numFmt numFmtId="166" formatCode="+#,##0.00;\-#,##0.00;0.00"/>` +
+	`<numFmt numFmtId="167" formatCode="0.00&quot;%&quot;"/>` +
+	`<numFmt numFmtId="168" formatCode="+0.00&quot;%&quot;;\-0.00&quot;%&quot;;0.00&quot;%&quot;"/>` +
+	`</numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="10">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="4" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="166" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="167" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="168" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="1" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`</cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+	`</styleSheet>`
+
+func xlsxContentTypes(sheets int) string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	b.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	b.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	b.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	b.WriteString(`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	for i := 1; i <= sheets; i++ {
+		fmt.Fprintf(&b, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i)
+	}
+	b.WriteString(`</Types>`)
+	return b.String()
+}
+
+func xlsxWorkbook(sheets []Sheet) string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	used := make(map[string]bool)
+	for i, sheet := range sheets {
+		name := xlsxSheetName(sheet.Name, i+1, used)
+		fmt.Fprintf(&b, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xlsxEscape(name), i+1, i+1)
+	}
+	b.WriteString(`</sheets></workbook>`)
+	return b.String()
+}
+
+func xlsxWorkbookRels(sheets int) string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := 1; i <= sheets; i++ {
+		fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i, i)
+	}
+	fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, sheets+1)
+	b.WriteString(`</Relationships>`)
+	return b.String()
+}
+
+// название листа: не длиннее 31 символа, без запрещённых символов и без повторов
+func xlsxSheetName(name string, index int, used map[string]bool) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return ' '
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if name == "" {
+		name = fmt.Sprintf("Лист %d", index)
+	}
+	if utf8.RuneCountInString(name) > 31 {
+		name = string([]rune(name)[:31])
+	}
+	for used[strings.ToLower(name)] {
+		suffix := fmt.Sprintf(" %d", index)
+		runes := []rune(name)
+		if len(runes)+len(suffix) > 31 {
+			runes = runes[:31-len(suffix)]
+		}
+		name = string(runes) + suffix
+		index++
+	}
+	used[strings.ToLower(name)] = true
+	return name
+}
+
+func xlsxSheet(sheet Sheet) string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	b.WriteString(`<sheetViews><sheetView workbookViewId="0">`)
+	b.WriteString(`<pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/><selection pane="bottomLeft"/>`)
+	b.WriteString(`</sheetView></sheetViews>`)
+
+	if len(sheet.Columns) > 0 {
+		b.WriteString(`<cols>`)
+		for i := range sheet.Columns {
+			fmt.Fprintf(&b, `<col min="%d" max="%d" width="%d" customWidth="1"/>`, i+1, i+1, xlsxColumnWidth(sheet, i))
+		}
+		b.WriteString(`</cols>`)
+	}
+
+	b.WriteString(`<sheetData>`)
+	b.WriteString(`<row r="1">`)
+	for i, column := range sheet.Columns {
+		xlsxCell(&b, xlsxRef(i, 1), column.Header, xlsxStyleHeader)
+	}
+	b.WriteString(`</row>`)
+
+	rows := sheet.Rows
+	if sheet.Total != nil {
+		rows = append(rows[:len(rows):len(rows)], sheet.Total)
+	}
+	for r, row := range rows {
+		total := sheet.Total != nil && r == len(rows)-1
+		fmt.Fprintf(&b, `<row r="%d">`, r+2)
+		for i, value := range row {
+			var column Column
+			if i < len(sheet.Columns) {
+				column = sheet.Columns[i]
+			}
+			style := xlsxStyle(column, value)
+			if total && style == xlsxStyleDefault {
+				style = xlsxStyleTotal
+			}
+			xlsxCell(&b, xlsxRef(i, r+2), value, style)
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String()
+}
+
+// одна ячейка: строки - встроенным текстом, числа и даты - числом со стилем
+func xlsxCell(b *strings.Builder, ref string, value any, style int) {
+	switch v := value.(type) {
+	case nil:
+		return
+	case *float64:
+		if v == nil {
+			return
+		}
+		xlsxCell(b, ref, *v, style)
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return
+		}
+		fmt.Fprintf(b, `<c r="%s" s="%d"><v>%s</v></c>`, ref, style, strconv.FormatFloat(v, 'f', -1, 64))
+	case int64:
+		fmt.Fprintf(b, `<c r="%s" s="%d"><v>%d</v></c>`, ref, style, v)
+	case int:
+		fmt.Fprintf(b, `<c r="%s" s="%d"><v>%d</v></c>`, ref, style, v)
+	case time.Time:
+		day := time.Date(v.Year(), v.Month(), v.Day(), 0, 0, 0, 0, time.UTC)
+		fmt.Fprintf(b, `<c r="%s" s="%d"><v>%d</v></c>`, ref, style, int64(day.Sub(xlsxEpoch).Hours()/24))
+	case string:
+		fmt.Fprintf(b, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, style, xlsxEscape(v))
+	default:
+		xlsxCell(b, ref, fmt.Sprint(v), style)
+	}
+}
+
+// стиль ячейки по колонке; у колонок без типа стиль берётся по значению
+func xlsxStyle(column Column, value any) int {
+	if _, ok := value.(string); ok {
+		return xlsxStyleDefault
+	}
+	switch column.Kind {
+	case ColDate:
+		return xlsxStyleDate
+	case ColMonth:
+		return xlsxStyleMonth
+	case ColMoney:
+		if column.Signed {
+			return xlsxStyleSignedMoney
+		}
+		return xlsxStyleMoney
+	case ColPercent:
+		if column.Signed {
+			return xlsxStyleSignedPercent
+		}
+		return xlsxStylePercent
+	case ColCount:
+		return xlsxStyleCount
+	}
+
+	switch value.(type) {
+	case time.Time:
+		return xlsxStyleDate
+	case float64, *float64:
+		return xlsxStyleMoney
+	case int64, int:
+		return xlsxStyleCount
+	}
+	return xlsxStyleDefault
+}
+
+// ширина по самому длинному тексту колонки
+func xlsxColumnWidth(sheet Sheet, i int) int {
+	column := sheet.Columns[i]
+	width := utf8.RuneCountInString(column.Header)
+	rows := sheet.Rows
+	if sheet.Total != nil {
+		rows = append(rows[:len(rows):len(rows)], sheet.Total)
+	}
+	for _, row := range rows {
+		if i < len(row) {
+			width = max(width, utf8.RuneCountInString(Format(column, row[i])))
+		}
+	}
+	return min(max(width+2, xlsxMinWidth), xlsxMaxWidth)
+}
+
+// адрес ячейки: колонка с нуля, строка с единицы
+func xlsxRef(col, row int) string {
+	name := ""
+	for col++; col > 0; col = (col - 1) / 26 {
+		name = string(rune('A'+(col-1)%26)) + name
+	}
+	return name + strconv.Itoa(row)
+}
+
+func xlsxEscape(text string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(text))
+	return b.String()
+}
@@ -0,0 +1,7 @@
+package schedule
+
+import "errors"
+
+var (
+	ErrUnknownFormat = errors.New("Schedule output format is not supported")
+)
@@ -0,0 +1,112 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/EmptyInsid/db_gui/internal/database"
+	"github.com/EmptyInsid/db_gui/internal/report"
+)
+
+// RunDue строит отчёты всех наступивших расписаний и возвращает число успешных.
+// Каждое расписание сначала сдвигается на следующий запуск, поэтому его не выполнят
+// дважды приложение и команда run-schedules, запущенные одновременно.
+// Ошибка одного расписания не мешает остальным
+func RunDue(ctx context.Context, db database.Service, now time.Time) (int, error) {
+	schedules, err := db.GetDueReportSchedules(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	var done int
+	for _, s := range schedules {
+		next := database.NextScheduleRun(s.FirstRun, s.NextRun, s.Frequency, now)
+		claimed, err := db.ClaimReportSchedule(ctx, s.ID, s.NextRun, next)
+		if err != nil {
+			log.Printf("Error while claim report schedule %d: %v", s.ID, err)
+			continue
+		}
+		if !claimed {
+			continue // расписание уже выполняет другой процесс
+		}
+
+		filename, err := runSafe(ctx, db.ForHousehold(s.Username, s.HouseholdID), s, now)
+		status, message := database.ScheduleOK, filename
+		if err != nil {
+			log.Printf("Error while run report schedule %d (%s): %v", s.ID, s.ReportID, err)
+			status, message = database.ScheduleError, err.Error()
+		} else {
+			log.Printf("Report schedule %d (%s) saved %s", s.ID, s.ReportID, filename)
+			done++
+		}
+		if err := db.FinishReportSchedule(ctx, s.ID, status, message); err != nil {
+			log.Printf("Error while finish report schedule %d: %v", s.ID, err)
+		}
+	}
+	return done, nil
+}
+
+// Run с перехватом паники: расписания выполняются в фоне, и сбой одного отчёта
+// не должен завершать приложение
+func runSafe(ctx context.Context, db database.Service, s database.ReportSchedule, now time.Time) (filename string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("сбой построения отчёта: %v", r)
+		}
+	}()
+	return Run(ctx, db, s, now)
+}
+
+// Run строит отчёт расписания за его относительный период и сохраняет в папку расписания.
+// db должен быть сессией владельца расписания
+func Run(ctx context.Context, db database.Service, s database.ReportSchedule, now time.Time) (string, error) {
+	def, err := report.Get(s.ReportID)
+	if err != nil {
+		return "", err
+	}
+	exporter, ok := report.ExporterByName(s.Format)
+	if !ok {
+		return "", ErrUnknownFormat
+	}
+
+	values, err := def.PresetValues(s.Period, s.Values, now)
+	if err != nil {
+		return "", err
+	}
+	result, err := def.Run(ctx, db, values)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(s.Directory, 0o755); err != nil {
+		return "", err
+	}
+	// номер расписания в имени: одинаковые расписания не перезаписывают файлы друг друга
+	filename := filepath.Join(s.Directory, fmt.Sprintf("%s_%s_%d_%s%s", def.ID, s.Period, s.ID, now.Format("2006-01-02_1504"), exporter.Extension))
+	if err := exporter.Write(def, result, filename); err != nil {
+		return "", err
+	}
+	return filename, nil
+}
+
+// Start проверяет расписания с заданным интервалом, пока не отменён ctx
+func Start(ctx context.Context, db database.Service, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if _, err := RunDue(ctx, db, time.Now()); err != nil {
+				log.Printf("Error while run report schedules: %v", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
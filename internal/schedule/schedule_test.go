@@ -0,0 +1,104 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/EmptyInsid/db_gui/internal/database"
+	"github.com/EmptyInsid/db_gui/internal/report"
+)
+
+var errDB = errors.New("db unavailable")
+
+// сервис расписаний в памяти; claimFail и finishFail - id расписаний с ошибкой базы
+type scheduleDB struct {
+	database.Service
+	schedules  []database.ReportSchedule
+	claimFail  map[int]bool
+	finishFail map[int]bool
+	finished   map[int]string
+}
+
+func (db *scheduleDB) GetDueReportSchedules(ctx context.Context, now time.Time) ([]database.ReportSchedule, error) {
+	return db.schedules, nil
+}
+
+func (db *scheduleDB) ClaimReportSchedule(ctx context.Context, id int, planned, next time.Time) (bool, error) {
+	if db.claimFail[id] {
+		return false, errDB
+	}
+	return true, nil
+}
+
+func (db *scheduleDB) FinishReportSchedule(ctx context.Context, id int, status, message string) error {
+	db.finished[id] = status
+	if db.finishFail[id] {
+		return errDB
+	}
+	return nil
+}
+
+func (db *scheduleDB) ForHousehold(username string, householdID int) database.Service {
+	return db
+}
+
+func (db *scheduleDB) CurrentUser() string {
+	return "user"
+}
+
+func (db *scheduleDB) GetTotalProfitDate(ctx context.Context, startDate, endDate string) ([]database.DateProfit, error) {
+	return []database.DateProfit{{Date: time.Date(2024, time.October, 1, 0, 0, 0, 0, time.UTC), TotalProfit: 100, OnHand: 500}}, nil
+}
+
+// ошибка одного расписания не останавливает остальные
+func TestRunDueContinuesAfterErrors(t *testing.T) {
+	now := time.Date(2024, time.November, 1, 8, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+	schedule := func(id int, reportID string) database.ReportSchedule {
+		return database.ReportSchedule{
+			ID: id, ReportID: reportID, Period: report.PresetPreviousMonth, Format: "CSV", Directory: dir,
+			Frequency: database.ScheduleMonthly, FirstRun: now, NextRun: now,
+		}
+	}
+
+	db := &scheduleDB{
+		schedules: []database.ReportSchedule{
+			schedule(1, report.TotalProfitDate.ID), // не удалось сдвинуть запуск
+			schedule(2, report.TotalProfitDate.ID), // отчёт построен, итог не записан
+			schedule(3, "unknown"),                 // отчёта нет в реестре
+			schedule(4, report.TotalProfitDate.ID),
+		},
+		claimFail:  map[int]bool{1: true},
+		finishFail: map[int]bool{2: true},
+		finished:   map[int]string{},
+	}
+
+	done, err := RunDue(context.Background(), db, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if done != 2 {
+		t.Errorf("done = %d, want 2", done)
+	}
+
+	want := map[int]string{2: database.ScheduleOK, 3: database.ScheduleError, 4: database.ScheduleOK}
+	for id, status := range want {
+		if db.finished[id] != status {
+			t.Errorf("schedule %d: status %q, want %q", id, db.finished[id], status)
+		}
+	}
+	if _, ok := db.finished[1]; ok {
+		t.Error("schedule 1 was run without being claimed")
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Errorf("got %d files, want 2", len(files))
+	}
+}
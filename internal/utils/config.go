@@ -7,15 +7,19 @@ import (
 )
 
 type Config struct {
-	DBHost         string
-	DBPort         int
-	DBUser         string
-	DBPassword     string
-	DBName         string
-	DBSSLMode      string
-	LogLevel       string
-	MaxConnections int
-	Timeout        int
+	DBHost          string
+	DBPort          int
+	DBUser          string
+	DBPassword      string
+	DBName          string
+	DBSSLMode       string
+	LogLevel        string
+	MaxConnections  int
+	Timeout         int
+	TrashDays       int    // срок хранения записей в корзине, 0 (по умолчанию) - без автоочистки
+	PDFFont         string // TTF-шрифт для PDF, пусто - встроенный
+	PDFFontBold     string // жирный TTF-шрифт для заголовков PDF, пусто - встроенный
+	ScheduleMinutes int    // как часто проверять расписания отчётов в минутах, 0 - не проверять
 }
 
 func LoadConfig(path string) (*Config, error) {
@@ -26,15 +30,19 @@ func LoadConfig(path string) (*Config, error) {
 	}
 
 	config := &Config{
-		DBHost:         cfg.Section("database").Key("host").String(),
-		DBPort:         cfg.Section("database").Key("port").MustInt(5432),
-		DBUser:         cfg.Section("database").Key("user").String(),
-		DBPassword:     cfg.Section("database").Key("password").String(),
-		DBName:         cfg.Section("database").Key("dbname").String(),
-		DBSSLMode:      cfg.Section("database").Key("sslmode").String(),
-		LogLevel:       cfg.Section("app").Key("log_level").String(),
-		MaxConnections: cfg.Section("app").Key("max_connections").MustInt(10),
-		Timeout:        cfg.Section("app").Key("timeout").MustInt(30),
+		DBHost:          cfg.Section("database").Key("host").String(),
+		DBPort:          cfg.Section("database").Key("port").MustInt(5432),
+		DBUser:          cfg.Section("database").Key("user").String(),
+		DBPassword:      cfg.Section("database").Key("password").String(),
+		DBName:          cfg.Section("database").Key("dbname").String(),
+		DBSSLMode:       cfg.Section("database").Key("sslmode").String(),
+		LogLevel:        cfg.Section("app").Key("log_level").String(),
+		MaxConnections:  cfg.Section("app").Key("max_connections").MustInt(10),
+		Timeout:         cfg.Section("app").Key("timeout").MustInt(30),
+		TrashDays:       cfg.Section("app").Key("trash_days").MustInt(0),
+		PDFFont:         cfg.Section("app").Key("pdf_font").String(),
+		PDFFontBold:     cfg.Section("app").Key("pdf_font_bold").String(),
+		ScheduleMinutes: cfg.Section("app").Key("schedule_minutes").MustInt(5),
 	}
 
 	return config, nil
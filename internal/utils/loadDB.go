@@ -26,6 +26,12 @@ func LoadDb(config *Config) (database.Service, error) {
 		return nil, err
 	}
 
+	// Создание недостающих таблиц
+	if err := db.Migrate(ctx); err != nil {
+		log.Printf("Error while migrate bd: %v", err)
+		return nil, err
+	}
+
 	return db, nil
 }
 